@@ -0,0 +1,54 @@
+package zzterm
+
+import "time"
+
+// AuditEvent describes a key read by an Input, as reported to the audit
+// hook set with WithAuditHook. Unless full capture is enabled, the content
+// typed or pasted by the user is redacted: Rune is -1 for keys of type
+// KeyRune, and Mouse and Bytes are not set, so that the input activity can
+// be logged without recording what was typed.
+type AuditEvent struct {
+	Type KeyType
+	Mod  Mod
+	Time time.Time // time the key was decoded, as given by the Clock of the Input
+	Len  int       // number of bytes of the key
+
+	// set only with full capture
+	Rune  rune       // rune of a KeyRune, -1 otherwise
+	Mouse MouseEvent // mouse event of a KeyMouse
+	Bytes []byte     // raw bytes of the key, valid only during the call
+}
+
+// WithAuditHook sets a function that is called with the metadata of each
+// key decoded by the Input, e.g. for audit logging in security-conscious
+// deployments. By default the runes, mouse events and raw bytes are
+// redacted, including those of pasted content and escape sequences such as
+// OSC payloads; if full is true, they are captured too. The hook is called
+// once per key decoded, before it is returned by ReadKey or queued by
+// Expect, and it must not call the methods of the Input.
+func WithAuditHook(fn func(AuditEvent), full bool) Option {
+	return func(i *Input) {
+		i.audit = fn
+		i.auditFull = full
+	}
+}
+
+// reports the key k, the last key read by i, to the audit hook.
+func (i *Input) auditKey(k Key) {
+	b := i.Bytes()
+	ev := AuditEvent{
+		Type: k.Type(),
+		Mod:  k.Mod(),
+		Time: i.clock.Now(),
+		Len:  len(b),
+		Rune: -1,
+	}
+	if i.auditFull {
+		ev.Rune = k.Rune()
+		ev.Bytes = b
+		if ev.Type == KeyMouse {
+			ev.Mouse = i.lastm
+		}
+	}
+	i.audit(ev)
+}
@@ -0,0 +1,48 @@
+package zzterm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithAuditHook(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	chunks := []string{"a", "\x1b[1;2C", "\x1b[<0;3;4M", "平"}
+
+	for _, full := range []bool{false, true} {
+		clock := &fakeClock{now: start}
+		var got []AuditEvent
+		input := NewInput(WithMouse(), WithClock(clock), WithAuditHook(func(ev AuditEvent) {
+			ev.Bytes = append([]byte(nil), ev.Bytes...)
+			got = append(got, ev)
+		}, full))
+		r := &clockedReader{clock: clock, chunks: chunks, delay: time.Second}
+		for range chunks {
+			if _, err := input.ReadKey(r); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		_, m := NewMouseEvent(1, true, 3, 4, ModNone)
+		want := []AuditEvent{
+			{Type: KeyRune, Len: 1, Rune: 'a', Bytes: []byte("a")},
+			{Type: KeyRight, Mod: ModShift, Len: 6, Rune: -1, Bytes: []byte("\x1b[1;2C")},
+			{Type: KeyMouse, Len: 9, Rune: -1, Mouse: m, Bytes: []byte("\x1b[<0;3;4M")},
+			{Type: KeyRune, Len: 3, Rune: '平', Bytes: []byte("平")},
+		}
+		if len(got) != len(want) {
+			t.Fatalf("full=%t: want %d events, got %d", full, len(want), len(got))
+		}
+		for j, w := range want {
+			w.Time = start.Add(time.Duration(j+1) * time.Second)
+			if !full {
+				w.Rune, w.Mouse, w.Bytes = -1, MouseEvent{}, []byte{}
+			}
+			g := got[j]
+			if g.Type != w.Type || g.Mod != w.Mod || !g.Time.Equal(w.Time) || g.Len != w.Len ||
+				g.Rune != w.Rune || g.Mouse != w.Mouse || string(g.Bytes) != string(w.Bytes) {
+				t.Errorf("full=%t: [%d]: want %+v, got %+v", full, j, w, g)
+			}
+		}
+	}
+}
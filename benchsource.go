@@ -0,0 +1,86 @@
+package zzterm
+
+// BenchmarkSource is an io.Reader that cycles over a corpus of terminal
+// input sequences, to benchmark Input.ReadKey with a realistic mix of keys,
+// mouse events, pastes and OSC sequences instead of a single sequence. Each
+// Read returns the next sequence of the corpus (or as much of it as fits in
+// the buffer, the rest being returned by the next Read), and it starts over
+// after the last sequence, so it never returns io.EOF. Reading does not
+// allocate.
+//
+// A BenchmarkSource is also an io.Writer that records each Write as a new
+// sequence of the corpus, so that a session can be recorded by passing it
+// to WithRawMirror, which writes each sequence consumed by ReadKey.
+//
+// A BenchmarkSource is not safe for concurrent use.
+type BenchmarkSource struct {
+	corpus []byte
+	ends   []int // end offset of each sequence in corpus
+	ix     int   // index of the next sequence to read
+	off    int   // offset in corpus of the next byte to read
+	cycles int
+}
+
+// NewBenchmarkSource returns a BenchmarkSource with the sequences seqs as
+// corpus. The empty sequences are ignored.
+func NewBenchmarkSource(seqs ...string) *BenchmarkSource {
+	var s BenchmarkSource
+	for _, seq := range seqs {
+		s.add([]byte(seq))
+	}
+	return &s
+}
+
+// Write adds a copy of p as a new sequence at the end of the corpus. It
+// never fails.
+func (s *BenchmarkSource) Write(p []byte) (int, error) {
+	s.add(p)
+	return len(p), nil
+}
+
+func (s *BenchmarkSource) add(p []byte) {
+	if len(p) == 0 {
+		return
+	}
+	s.corpus = append(s.corpus, p...)
+	s.ends = append(s.ends, len(s.corpus))
+}
+
+// Read reads the next sequence of the corpus into b. If the corpus is
+// empty, it returns 0 and no error, which ReadKey reports as ErrTimeout.
+func (s *BenchmarkSource) Read(b []byte) (int, error) {
+	if len(s.ends) == 0 {
+		return 0, nil
+	}
+	n := copy(b, s.corpus[s.off:s.ends[s.ix]])
+	s.off += n
+	if s.off == s.ends[s.ix] {
+		s.ix++
+		if s.ix == len(s.ends) {
+			s.ix, s.off = 0, 0
+			s.cycles++
+		}
+	}
+	return n, nil
+}
+
+// Len returns the number of sequences in the corpus.
+func (s *BenchmarkSource) Len() int {
+	return len(s.ends)
+}
+
+// Bytes returns the total number of bytes of the sequences in the corpus,
+// e.g. to report the throughput of a benchmark with testing.B.SetBytes.
+func (s *BenchmarkSource) Bytes() int {
+	return len(s.corpus)
+}
+
+// Cycles returns the number of times the whole corpus has been read.
+func (s *BenchmarkSource) Cycles() int {
+	return s.cycles
+}
+
+// Reset restarts reading at the first sequence of the corpus.
+func (s *BenchmarkSource) Reset() {
+	s.ix, s.off, s.cycles = 0, 0, 0
+}
@@ -0,0 +1,74 @@
+package zzterm
+
+import (
+	"testing"
+)
+
+func TestBenchmarkSource(t *testing.T) {
+	s := NewBenchmarkSource("a", "", "\x1b[A", "\x1b[<0;1;1M")
+	if s.Len() != 3 || s.Bytes() != 13 {
+		t.Fatalf("want 3 sequences of 13 bytes, got %d of %d", s.Len(), s.Bytes())
+	}
+
+	b := make([]byte, 4)
+	want := []string{"a", "\x1b[A", "\x1b[<0", ";1;1", "M", "a", "\x1b[A"}
+	for j, w := range want {
+		n, err := s.Read(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(b[:n]); got != w {
+			t.Errorf("[%d]: want %q, got %q", j, w, got)
+		}
+	}
+	if s.Cycles() != 1 {
+		t.Errorf("want 1 cycle, got %d", s.Cycles())
+	}
+	s.Reset()
+	if n, _ := s.Read(b); string(b[:n]) != "a" || s.Cycles() != 0 {
+		t.Errorf("want 'a' after reset, got %q (%d cycles)", b[:n], s.Cycles())
+	}
+
+	if n, err := NewBenchmarkSource().Read(b); n != 0 || err != nil {
+		t.Errorf("want 0, nil for empty corpus, got %d, %v", n, err)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		s.Read(b)
+	})
+	if allocs != 0 {
+		t.Errorf("want no allocation, got %f", allocs)
+	}
+}
+
+func TestBenchmarkSource_Record(t *testing.T) {
+	var rec BenchmarkSource
+	input := NewInput(WithMouse(), WithRawMirror(&rec))
+	src := NewBenchmarkSource("ab\x1b[<0;1;1M\x1b[B")
+	var keys []Key
+	for j := 0; j < 4; j++ {
+		k, err := input.ReadKey(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, k)
+	}
+	if rec.Len() != 4 {
+		t.Fatalf("want 4 recorded sequences, got %d", rec.Len())
+	}
+
+	// replaying the recording gives the same keys
+	input = NewInput(WithMouse())
+	for j, want := range keys {
+		k, err := input.ReadKey(&rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k != want {
+			t.Errorf("[%d]: want %s, got %s", j, want, k)
+		}
+	}
+	if rec.Cycles() != 1 {
+		t.Errorf("want 1 cycle, got %d", rec.Cycles())
+	}
+}
@@ -0,0 +1,191 @@
+package zzterm
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+// Chord is an ordered sequence of keys that must be pressed one after the
+// other to trigger an action, such as the emacs Ctrl-X Ctrl-S or the tmux
+// prefix key followed by a command key.
+type Chord struct {
+	Keys []Key
+
+	// Timeout is the maximum delay between two keys of the chord. If it
+	// expires before the chord is complete, the keys are returned
+	// individually. A zero value means no timeout.
+	Timeout time.Duration
+
+	// Category and Data are not used by the ChordReader, they associate
+	// user data with the chord (e.g. a command ID or a closure) so that the
+	// chords can be the single source of truth for the help screens and
+	// command palettes of the application, see ChordReader.Groups.
+	Category string
+	Data     interface{}
+}
+
+// ChordGroup is a group of chords with the same category.
+type ChordGroup struct {
+	Category string
+	Chords   []*Chord
+}
+
+// ChordReader reads keys from an Input and returns either the complete
+// chords or the individual keys that are not part of a chord. For the
+// timeout of chords to be detected while no key is pressed, the reader must
+// have a read timeout so that Input.ReadKey returns ErrTimeout regularly.
+type ChordReader struct {
+	input  *Input
+	r      io.Reader
+	chords []Chord
+
+	pending   []Key     // keys read that are a prefix of a chord
+	replay    []Key     // keys to return individually before reading more
+	next      Key       // key to return after replay (valid if hasNext)
+	nextChord *Chord    // chord to return after replay (valid if hasNext)
+	hasNext   bool      // next or nextChord is set
+	last      time.Time // time the last pending key was read
+}
+
+// NewChordReader returns a ChordReader that reads keys from r using input
+// and detects the provided chords.
+func NewChordReader(input *Input, r io.Reader, chords ...Chord) *ChordReader {
+	return &ChordReader{
+		input:  input,
+		r:      r,
+		chords: chords,
+	}
+}
+
+// ReadChord returns the next chord or individual key. If a chord is
+// complete, it returns a pointer to that chord and a 0 key, otherwise it
+// returns the key and a nil chord. If the error is ErrTimeout, no key nor
+// chord is available yet.
+//
+// Keys that were part of an incomplete chord are returned individually
+// without reading from the Input, so Input.Bytes and Input.Mouse are only
+// valid for the last key returned before the next call to ReadChord if
+// they correspond to the last key read by the Input.
+func (c *ChordReader) ReadChord() (Key, *Chord, error) {
+	for {
+		if len(c.replay) > 0 {
+			k := c.replay[0]
+			c.replay = c.replay[1:]
+			return k, nil, nil
+		}
+		if c.hasNext {
+			c.hasNext = false
+			return c.next, c.nextChord, nil
+		}
+
+		k, err := c.input.ReadKey(c.r)
+		if err != nil {
+			if errors.Is(err, ErrTimeout) && c.expired() {
+				c.flush()
+				continue
+			}
+			return 0, nil, err
+		}
+
+		if len(c.pending) > 0 && c.expired() {
+			c.flush()
+		}
+
+		c.pending = append(c.pending, k)
+		ch, prefix := c.match(c.pending)
+		if ch == nil && !prefix && len(c.pending) > 1 {
+			// not part of a chord, return the pending keys individually,
+			// except for the last one that may start a new chord.
+			c.pending = c.pending[:len(c.pending)-1]
+			c.flush()
+			c.pending = append(c.pending, k)
+			ch, prefix = c.match(c.pending)
+		}
+
+		// the result is returned after the flushed keys, if any
+		switch {
+		case ch != nil:
+			c.pending = c.pending[:0]
+			c.next, c.nextChord, c.hasNext = 0, ch, true
+		case prefix:
+			c.last = c.input.clock.Now()
+		default:
+			c.pending = c.pending[:0]
+			c.next, c.nextChord, c.hasNext = k, nil, true
+		}
+	}
+}
+
+// moves the pending keys to the replay queue.
+func (c *ChordReader) flush() {
+	c.replay = append(c.replay, c.pending...)
+	c.pending = c.pending[:0]
+}
+
+// returns true if the pending keys exceed the timeout of all chords that
+// they are a prefix of.
+func (c *ChordReader) expired() bool {
+	if len(c.pending) == 0 {
+		return false
+	}
+	var timeout time.Duration
+	for _, ch := range c.chords {
+		if isKeysPrefix(ch.Keys, c.pending) {
+			if ch.Timeout == 0 {
+				return false
+			}
+			if ch.Timeout > timeout {
+				timeout = ch.Timeout
+			}
+		}
+	}
+	return c.input.clock.Now().Sub(c.last) > timeout
+}
+
+// Groups returns the chords of the reader grouped by category, in the order
+// of the first chord of each category. The chords of a group are in the
+// order in which they were provided to NewChordReader.
+func (c *ChordReader) Groups() []ChordGroup {
+	var groups []ChordGroup
+	ixs := make(map[string]int)
+	for i := range c.chords {
+		ch := &c.chords[i]
+		ix, ok := ixs[ch.Category]
+		if !ok {
+			ix = len(groups)
+			ixs[ch.Category] = ix
+			groups = append(groups, ChordGroup{Category: ch.Category})
+		}
+		groups[ix].Chords = append(groups[ix].Chords, ch)
+	}
+	return groups
+}
+
+// returns the chord that exactly matches keys, or if none matches, whether
+// keys is the prefix of a chord.
+func (c *ChordReader) match(keys []Key) (*Chord, bool) {
+	var prefix bool
+	for i, ch := range c.chords {
+		if !isKeysPrefix(ch.Keys, keys) {
+			continue
+		}
+		if len(ch.Keys) == len(keys) {
+			return &c.chords[i], false
+		}
+		prefix = true
+	}
+	return nil, prefix
+}
+
+func isKeysPrefix(keys, prefix []Key) bool {
+	if len(prefix) > len(keys) {
+		return false
+	}
+	for i, k := range prefix {
+		if keys[i] != k {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,168 @@
+package zzterm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// scriptReader returns a chunk of bytes per call to Read, an empty chunk
+// sleeps for delay and returns (0, nil), as a terminal read timeout would.
+type scriptReader struct {
+	chunks []string
+	delay  time.Duration
+}
+
+func (r *scriptReader) Read(b []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, nil
+	}
+	c := r.chunks[0]
+	r.chunks = r.chunks[1:]
+	if c == "" && r.delay > 0 {
+		time.Sleep(r.delay)
+	}
+	return copy(b, c), nil
+}
+
+func TestChordReader(t *testing.T) {
+	ctrlX, ctrlS, ctrlC := NewKey(KeyCAN, ModNone), NewKey(KeyDC3, ModNone), NewKey(KeyETX, ModNone)
+	chords := []Chord{
+		{Keys: []Key{ctrlX, ctrlS}},
+		{Keys: []Key{ctrlX, ctrlC}},
+		{Keys: []Key{Key('g'), Key('g')}, Timeout: 10 * time.Millisecond},
+		{Keys: []Key{Key('q')}},
+	}
+
+	type result struct {
+		k     Key
+		chord int // index in chords or -1
+	}
+	cases := []struct {
+		desc   string
+		chunks []string
+		want   []result
+	}{
+		{"no chord", []string{"a", "b"}, []result{{Key('a'), -1}, {Key('b'), -1}}},
+		{"chord", []string{"\x18", "\x13", "a"}, []result{{0, 0}, {Key('a'), -1}}},
+		{"second chord", []string{"\x18", "\x03"}, []result{{0, 1}}},
+		{"single key chord", []string{"a", "q"}, []result{{Key('a'), -1}, {0, 3}}},
+		{"incomplete", []string{"\x18", "a"}, []result{{ctrlX, -1}, {Key('a'), -1}}},
+		{"incomplete then chord", []string{"\x18", "g", "g"}, []result{{ctrlX, -1}, {0, 2}}},
+		{"incomplete then single key chord", []string{"\x18", "q"}, []result{{ctrlX, -1}, {0, 3}}},
+		{"timeout", []string{"g", "", "g", "", ""}, []result{{Key('g'), -1}, {Key('g'), -1}}},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			r := &scriptReader{chunks: c.chunks, delay: 20 * time.Millisecond}
+			cr := NewChordReader(NewInput(), r, chords...)
+
+			for i, want := range c.want {
+				var (
+					k     Key
+					chord *Chord
+					err   error
+				)
+				for {
+					k, chord, err = cr.ReadChord()
+					if !errors.Is(err, ErrTimeout) || len(r.chunks) == 0 {
+						break
+					}
+				}
+				if err != nil {
+					t.Fatalf("[%d]: %v", i, err)
+				}
+
+				gotIx := -1
+				for j := range chords {
+					if chord == &chords[j] {
+						gotIx = j
+					}
+				}
+				if k != want.k || gotIx != want.chord {
+					t.Fatalf("[%d]: want %s, chord %d, got %s, chord %d", i, want.k, want.chord, k, gotIx)
+				}
+			}
+			if k, chord, err := cr.ReadChord(); !errors.Is(err, ErrTimeout) {
+				t.Fatalf("want ErrTimeout, got %s, %v, %v", k, chord, err)
+			}
+		})
+	}
+}
+
+func TestChordReader_ExpiredThenChord(t *testing.T) {
+	chords := []Chord{
+		{Keys: []Key{'g', 'g'}, Timeout: 10 * time.Millisecond},
+		{Keys: []Key{'q'}},
+		{Keys: []Key{'z', 'z'}},
+	}
+	cases := []struct {
+		desc   string
+		chunks []string
+		want   []Key // 0 for a chord
+	}{
+		{"single key chord", []string{"g", "q"}, []Key{'g', 0}},
+		{"other chord", []string{"g", "z", "z"}, []Key{'g', 0}},
+		{"no chord", []string{"g", "a"}, []Key{'g', 'a'}},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			// the clock advances past the timeout before each read, so the
+			// pending keys expire when the next key is read
+			clock := &fakeClock{}
+			r := &clockedReader{clock: clock, chunks: c.chunks, delay: 20 * time.Millisecond}
+			cr := NewChordReader(NewInput(WithClock(clock)), r, chords...)
+			for i, want := range c.want {
+				k, chord, err := cr.ReadChord()
+				if err != nil {
+					t.Fatalf("[%d]: %v", i, err)
+				}
+				if k != want || (want == 0) != (chord != nil) {
+					t.Fatalf("[%d]: want %s (chord=%t), got %s, %v", i, want, want == 0, k, chord)
+				}
+			}
+		})
+	}
+}
+
+func TestChordReader_Groups(t *testing.T) {
+	ctrlX, ctrlS, ctrlC := NewKey(KeyCAN, ModNone), NewKey(KeyDC3, ModNone), NewKey(KeyETX, ModNone)
+	chords := []Chord{
+		{Keys: []Key{ctrlX, ctrlS}, Category: "file", Data: "save"},
+		{Keys: []Key{ctrlX, ctrlC}, Category: "app", Data: "quit"},
+		{Keys: []Key{ctrlX, 'f'}, Category: "file", Data: "open"},
+		{Keys: []Key{ctrlX, 'h'}},
+	}
+	cr := NewChordReader(NewInput(), &scriptReader{}, chords...)
+
+	groups := cr.Groups()
+	want := []struct {
+		cat string
+		ixs []int
+	}{
+		{"file", []int{0, 2}},
+		{"app", []int{1}},
+		{"", []int{3}},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("want %d groups, got %d", len(want), len(groups))
+	}
+	for i, w := range want {
+		g := groups[i]
+		if g.Category != w.cat || len(g.Chords) != len(w.ixs) {
+			t.Errorf("[%d]: want %q with %d chords, got %q with %d", i, w.cat, len(w.ixs), g.Category, len(g.Chords))
+			continue
+		}
+		for j, ix := range w.ixs {
+			if g.Chords[j] != &chords[ix] {
+				t.Errorf("[%d]: want chord %d (%v), got %v", i, ix, chords[ix].Data, g.Chords[j].Data)
+			}
+		}
+	}
+
+	// the chord returned by ReadChord carries its data
+	cr = NewChordReader(NewInput(), &scriptReader{chunks: []string{"\x18", "\x03"}}, chords...)
+	if _, chord, err := cr.ReadChord(); err != nil || chord == nil || chord.Data != "quit" {
+		t.Errorf("want quit chord, got %v, %v", chord, err)
+	}
+}
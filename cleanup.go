@@ -0,0 +1,65 @@
+package zzterm
+
+import (
+	"io"
+	"sync"
+)
+
+// cleanupSeq disables all the terminal modes that an application using
+// zzterm may have enabled: the mouse tracking modes (1000 to 1003) and the
+// UTF-8, SGR and urxvt mouse modes (1005, 1006 and 1015), focus events
+// (1004), bracketed paste (2004), the kitty keyboard protocol flags,
+// xterm's modifyOtherKeys mode and the application keypad mode, and it
+// shows the cursor (DECTCEM, 25).
+const cleanupSeq = "\x1b[?1000;1001;1002;1003;1005;1006;1015l" +
+	"\x1b[?1004l" +
+	"\x1b[?2004l" +
+	"\x1b[=0;1u" +
+	SeqDisableModifyOtherKeys +
+	SeqDisableKeypadApp +
+	"\x1b[?25h"
+
+var cleanup struct {
+	sync.Mutex
+	fns []func()
+}
+
+// RegisterCleanup registers fn to be called by Cleanup, e.g. to restore the
+// terminal from raw mode. The registered functions are called in the
+// reverse order of registration, after the terminal modes have been
+// disabled. It is safe to call concurrently.
+func RegisterCleanup(fn func()) {
+	cleanup.Lock()
+	defer cleanup.Unlock()
+	cleanup.fns = append(cleanup.fns, fn)
+}
+
+// Cleanup restores the terminal represented by w to a sane state: it
+// disables the mouse tracking, focus events, bracketed paste, kitty
+// keyboard, modifyOtherKeys and application keypad modes and shows the
+// cursor, in a single write, and then calls the functions registered with
+// RegisterCleanup. It is safe to call even if the modes were not enabled,
+// and it can be called more than once.
+//
+// It is typically deferred at the start of the main function so that the
+// terminal is restored even if the application panics:
+//
+//	defer zzterm.Cleanup(os.Stdout)
+//
+// Note that a panic in another goroutine terminates the program without
+// running the deferred calls of the main goroutine, so goroutines that may
+// panic should defer it too. The registered functions are called even if
+// the write fails, and the error of the write is returned.
+func Cleanup(w io.Writer) error {
+	_, err := io.WriteString(w, cleanupSeq)
+
+	cleanup.Lock()
+	fns := make([]func(), len(cleanup.fns))
+	copy(fns, cleanup.fns)
+	cleanup.Unlock()
+
+	for i := len(fns) - 1; i >= 0; i-- {
+		fns[i]()
+	}
+	return err
+}
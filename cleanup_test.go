@@ -0,0 +1,41 @@
+package zzterm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCleanup(t *testing.T) {
+	var calls []string
+	RegisterCleanup(func() { calls = append(calls, "first") })
+	RegisterCleanup(func() { calls = append(calls, "second") })
+	defer func() {
+		cleanup.Lock()
+		cleanup.fns = nil
+		cleanup.Unlock()
+	}()
+
+	var buf bytes.Buffer
+	func() {
+		defer func() {
+			if e := recover(); e == nil {
+				t.Fatal("want panic")
+			}
+		}()
+		defer Cleanup(&buf)
+		panic("boom")
+	}()
+
+	for _, want := range []string{
+		"1003;1005;1006;1015l", "\x1b[?1004l", "\x1b[?2004l", "\x1b[=0;1u",
+		SeqDisableModifyOtherKeys, SeqDisableKeypadApp, "\x1b[?25h",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("want %q in output, got %q", want, buf.String())
+		}
+	}
+	if got := strings.Join(calls, ","); got != "second,first" {
+		t.Errorf("want calls second,first, got %s", got)
+	}
+}
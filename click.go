@@ -0,0 +1,50 @@
+package zzterm
+
+// ClickConvention defines the modifiers that change the meaning of a click
+// of the primary (left) button, as they differ across platforms. A zero
+// Mod field disables the corresponding convention. Note that many
+// terminals reserve some modified clicks for their own use (e.g. Shift
+// clicks for the native text selection) and do not report them.
+type ClickConvention struct {
+	// ContextMod is the modifier that turns a primary click into a context
+	// click, as for a click of the secondary (right) button.
+	ContextMod Mod
+
+	// ExtendMod is the modifier that turns a primary click into a click
+	// that extends the current selection up to the clicked position.
+	ExtendMod Mod
+}
+
+// List of common click conventions.
+var (
+	// ClicksDefault is the convention of Linux and Windows applications.
+	ClicksDefault = ClickConvention{ExtendMod: ModShift}
+
+	// ClicksMacOS is the convention of macOS applications, where a click
+	// with the Control key is a context click.
+	ClicksMacOS = ClickConvention{ContextMod: ModCtrl, ExtendMod: ModShift}
+)
+
+// IsContextClick returns true if m is a press of the secondary (right)
+// button without modifier, or a press of the primary (left) button with
+// exactly the ContextMod modifier of the convention c. The mod argument is
+// the modifier of the KeyMouse key that reported m.
+func (m MouseEvent) IsContextClick(mod Mod, c ClickConvention) bool {
+	if !m.pressed {
+		return false
+	}
+	switch m.buttonID {
+	case 3:
+		return mod == ModNone
+	case 1:
+		return c.ContextMod != ModNone && mod == c.ContextMod
+	}
+	return false
+}
+
+// IsExtendSelectionClick returns true if m is a press of the primary (left)
+// button with exactly the ExtendMod modifier of the convention c. The mod
+// argument is the modifier of the KeyMouse key that reported m.
+func (m MouseEvent) IsExtendSelectionClick(mod Mod, c ClickConvention) bool {
+	return m.pressed && m.buttonID == 1 && c.ExtendMod != ModNone && mod == c.ExtendMod
+}
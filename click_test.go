@@ -0,0 +1,38 @@
+package zzterm
+
+import "testing"
+
+func TestMouseEvent_Clicks(t *testing.T) {
+	cases := []struct {
+		btn            int
+		pressed        bool
+		mod            Mod
+		c              ClickConvention
+		context, exten bool
+	}{
+		{1, true, ModNone, ClicksDefault, false, false},
+		{3, true, ModNone, ClicksDefault, true, false},
+		{3, false, ModNone, ClicksDefault, false, false},
+		{3, true, ModShift, ClicksDefault, false, false},
+		{1, true, ModCtrl, ClicksDefault, false, false},
+		{1, true, ModShift, ClicksDefault, false, true},
+		{1, false, ModShift, ClicksDefault, false, false},
+		{1, true, ModShift | ModCtrl, ClicksDefault, false, false},
+		{2, true, ModShift, ClicksDefault, false, false},
+		{1, true, ModCtrl, ClicksMacOS, true, false},
+		{3, true, ModNone, ClicksMacOS, true, false},
+		{1, true, ModShift, ClicksMacOS, false, true},
+		{1, true, ModShift, ClickConvention{}, false, false},
+		{0, true, ModNone, ClicksDefault, false, false},
+	}
+
+	for _, c := range cases {
+		k, m := NewMouseEvent(c.btn, c.pressed, 1, 1, c.mod)
+		if got := m.IsContextClick(k.Mod(), c.c); got != c.context {
+			t.Errorf("%s %s %+v: want context %t, got %t", k, m, c.c, c.context, got)
+		}
+		if got := m.IsExtendSelectionClick(k.Mod(), c.c); got != c.exten {
+			t.Errorf("%s %s %+v: want extend %t, got %t", k, m, c.c, c.exten, got)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package zzterm
+
+import "time"
+
+// Clock is the source of time used by the timing-based features of the
+// Input, such as the timeout of two-key escapes and chords and the delay
+// between retries of empty reads. It can be replaced with WithClock, e.g.
+// to run tests with a fake clock (see termtest.FakeClock).
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// Sleep pauses the current goroutine for at least the duration d.
+	Sleep(d time.Duration)
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time        { return time.Now() }
+func (systemClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// WithClock sets the clock used by the timing-based features of the Input,
+// including the ChordReaders that read from it. By default, the system
+// clock is used. If c is nil, the option is ignored.
+func WithClock(c Clock) Option {
+	return func(i *Input) {
+		if c != nil {
+			i.clock = c
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package zzterm
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+// ErrTerminalClosed is the error returned by ReadKey when the terminal is
+// closed, e.g. when the ssh connection drops or the tty is revoked (the
+// read fails with EIO or EPIPE), or when the file of the terminal is
+// closed. The actual error returned wraps the error of the read, use
+// errors.Is(err, ErrTerminalClosed) to detect it.
+//
+// By default, an io.EOF returned by the read is reported as ErrTimeout, as
+// terminals in raw mode with a read timeout may report an expired timeout
+// this way. Use the WithEOFAsClosed option to report it as
+// ErrTerminalClosed instead.
+var ErrTerminalClosed = errors.New("zzterm: terminal closed")
+
+type closedError struct {
+	err error
+}
+
+// Error returns the error message for the closedError.
+func (e closedError) Error() string {
+	return ErrTerminalClosed.Error() + ": " + e.err.Error()
+}
+
+// Unwrap returns the error returned by the read.
+func (e closedError) Unwrap() error {
+	return e.err
+}
+
+// Is returns true if target is ErrTerminalClosed.
+func (e closedError) Is(target error) bool {
+	return target == ErrTerminalClosed
+}
+
+// WithEOFAsClosed reports an io.EOF returned by the read as an error that
+// matches ErrTerminalClosed instead of ErrTimeout. This should be used
+// when the reader does not use io.EOF to report an expired read timeout,
+// e.g. when reading from a pipe or a network connection, so that the
+// application can detect that the input is closed instead of looping on
+// ErrTimeout.
+func WithEOFAsClosed() Option {
+	return func(i *Input) {
+		i.eofClosed = true
+	}
+}
+
+// returns err wrapped in a closedError if it indicates that the terminal
+// is closed, otherwise it returns err unchanged.
+func (i *Input) closedErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, errno := range closedErrnos {
+		if errors.Is(err, errno) {
+			return closedError{err: err}
+		}
+	}
+	if errors.Is(err, os.ErrClosed) || (i.eofClosed && errors.Is(err, io.EOF)) {
+		return closedError{err: err}
+	}
+	return err
+}
@@ -0,0 +1,9 @@
+//go:build !plan9
+// +build !plan9
+
+package zzterm
+
+import "syscall"
+
+// errors returned by the read of a terminal that is closed.
+var closedErrnos = []error{syscall.EIO, syscall.EPIPE}
@@ -0,0 +1,7 @@
+package zzterm
+
+import "syscall"
+
+// errors returned by the read of a terminal that is closed, Plan 9 has no
+// EPIPE.
+var closedErrnos = []error{syscall.EIO}
@@ -0,0 +1,63 @@
+package zzterm
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read(b []byte) (int, error) {
+	return 0, r.err
+}
+
+func TestInput_ReadKey_TerminalClosed(t *testing.T) {
+	errOther := errors.New("other")
+	type closedCase struct {
+		err    error
+		eof    bool
+		closed bool
+	}
+	cases := []closedCase{
+		{&os.PathError{Op: "read", Path: "/dev/tty", Err: syscall.EIO}, false, true},
+		{os.ErrClosed, false, true},
+		{io.EOF, false, false},
+		{io.EOF, true, true},
+		{errOther, true, false},
+	}
+	for _, errno := range closedErrnos {
+		cases = append(cases, closedCase{errno, false, true})
+	}
+	for _, c := range cases {
+		t.Run(c.err.Error(), func(t *testing.T) {
+			var opts []Option
+			if c.eof {
+				opts = append(opts, WithEOFAsClosed())
+			}
+			input := NewInput(opts...)
+			_, err := input.ReadKey(errReader{c.err})
+			if got := errors.Is(err, ErrTerminalClosed); got != c.closed {
+				t.Fatalf("want closed %t, got %t (%v)", c.closed, got, err)
+			}
+			if !errors.Is(err, c.err) && !errors.Is(err, ErrTimeout) {
+				t.Errorf("want error to wrap %v, got %v", c.err, err)
+			}
+		})
+	}
+
+	// a key is still returned before the EOF
+	input := NewInput(WithEOFAsClosed())
+	r := strings.NewReader("a")
+	if k, err := input.ReadKey(r); err != nil || k != 'a' {
+		t.Fatalf("want 'a', got %s, %v", k, err)
+	}
+	if _, err := input.ReadKey(r); !errors.Is(err, ErrTerminalClosed) {
+		t.Fatalf("want ErrTerminalClosed, got %v", err)
+	}
+}
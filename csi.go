@@ -0,0 +1,289 @@
+package zzterm
+
+import "strconv"
+
+// MaxCSIParams is the maximum number of parameters (including the
+// sub-parameters) supported in a CSI sequence decoded by ParseCSI.
+const MaxCSIParams = 16
+
+// maximum value of a CSI parameter, greater values are clamped to it.
+const maxCSIParamValue = 1<<31 - 1
+
+// CSISeq is a decoded Control Sequence Introducer (CSI) sequence, of the
+// form:
+//
+//	ESC [ <prefix> <parameters> <intermediate> <final>
+//
+// where the optional prefix is one of the private parameter bytes '<', '=',
+// '>' or '?', the parameters are semicolon-separated decimal numbers, the
+// optional intermediate is a byte in the range 0x20-0x2F (e.g. '$' or ' ')
+// and the final is a byte in the range 0x40-0x7E. A parameter may have
+// colon-separated sub-parameters, as used by the kitty keyboard protocol
+// (e.g. "ESC [ 97:65 ; 2 u") and the SGR extended colors (e.g.
+// "ESC [ 38:2::255:0:0 m").
+type CSISeq struct {
+	Prefix       byte // 0 if none
+	Intermediate byte // 0 if none
+	Final        byte
+
+	n      int                 // number of parameters
+	nv     int                 // number of values (parameters and sub-parameters)
+	vals   [MaxCSIParams]int   // values of parameters and sub-parameters
+	starts [MaxCSIParams]uint8 // index in vals of each parameter
+}
+
+// NumParams returns the number of parameters of the sequence. Note that
+// "ESC [ A" has no parameter, while "ESC [ ; A" has 2 (omitted) parameters.
+// The sub-parameters are not counted, e.g. "ESC [ 97:65 ; 2 u" has 2
+// parameters.
+func (c *CSISeq) NumParams() int {
+	return c.n
+}
+
+// Param returns the value of the parameter at index ix (starting at 0). It
+// returns -1 if the parameter was omitted (e.g. the first parameter in
+// "ESC [ ; 2 A") or if there is no such parameter. Values greater than
+// what fits in an int32 are clamped to the maximum int32 value. If the
+// parameter has sub-parameters, it returns the value before the first
+// colon.
+func (c *CSISeq) Param(ix int) int {
+	if ix < 0 || ix >= c.n {
+		return -1
+	}
+	return c.vals[c.starts[ix]]
+}
+
+// NumSubParams returns the number of sub-parameters of the parameter at
+// index ix, e.g. 2 for the first parameter of "ESC [ 97:65:: ; 2 u". It
+// returns 0 if there is no such parameter.
+func (c *CSISeq) NumSubParams(ix int) int {
+	if ix < 0 || ix >= c.n {
+		return 0
+	}
+	end := c.nv
+	if ix < c.n-1 {
+		end = int(c.starts[ix+1])
+	}
+	return end - int(c.starts[ix]) - 1
+}
+
+// SubParam returns the value of the sub-parameter at index sub (starting at
+// 0) of the parameter at index ix. As for Param, it returns -1 if the
+// sub-parameter was omitted or if there is no such sub-parameter.
+func (c *CSISeq) SubParam(ix, sub int) int {
+	if sub < 0 || sub >= c.NumSubParams(ix) {
+		return -1
+	}
+	return c.vals[int(c.starts[ix])+1+sub]
+}
+
+// HasSubParams returns true if any parameter has sub-parameters.
+func (c *CSISeq) HasSubParams() bool {
+	return c.nv > c.n
+}
+
+// ParamOr returns the value of the parameter at index ix, or def if it was
+// omitted or if there is no such parameter.
+func (c *CSISeq) ParamOr(ix, def int) int {
+	if v := c.Param(ix); v >= 0 {
+		return v
+	}
+	return def
+}
+
+// CSIErrorKind identifies the kind of a CSIError.
+type CSIErrorKind int
+
+// List of CSI error kinds.
+const (
+	CSIInvalid       CSIErrorKind = iota + 1 // not a valid CSI sequence
+	CSIIncomplete                            // incomplete CSI sequence
+	CSITooManyParams                         // more than MaxCSIParams parameters
+	CSIParamOverflow                         // parameter greater than the maximum
+	CSITooLong                               // sequence longer than the input buffer
+	CSIInvalidMouse                          // invalid SGR mouse report
+)
+
+var csiErrorNames = [...]string{
+	CSIInvalid:       "invalid sequence",
+	CSIIncomplete:    "incomplete sequence",
+	CSITooManyParams: "too many parameters",
+	CSIParamOverflow: "parameter overflow",
+	CSITooLong:       "sequence too long",
+	CSIInvalidMouse:  "invalid mouse report",
+}
+
+// String returns the description of the error kind.
+func (k CSIErrorKind) String() string {
+	if k > 0 && int(k) < len(csiErrorNames) {
+		return csiErrorNames[k]
+	}
+	return "CSIErrorKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// CSIError is the error returned by ParseCSIStrict, and by ReadKey for the
+// CSI sequences that it skips if the WithStrictCSI or WithStrictMode option
+// is set.
+type CSIError struct {
+	Kind CSIErrorKind
+
+	// Seq holds the bytes of the sequence, or the start of the sequence for
+	// CSIIncomplete and CSITooLong. It is truncated to 128 bytes.
+	Seq string
+}
+
+// Error returns the description of the error.
+func (e CSIError) Error() string {
+	return "zzterm: " + e.Kind.String() + ": " + DumpBytes([]byte(e.Seq))
+}
+
+// returns a CSIError of that kind for the sequence at the start of b.
+func newCSIError(kind CSIErrorKind, b []byte) CSIError {
+	if n := skipCSI(b); n > 0 {
+		b = b[:n]
+	}
+	if len(b) > maxTokenLen {
+		b = b[:maxTokenLen]
+	}
+	return CSIError{Kind: kind, Seq: string(b)}
+}
+
+// ParseCSI parses the CSI sequence at the start of b and returns the decoded
+// sequence and its length in bytes. It returns a length of 0 if b does not
+// start with a valid and complete CSI sequence, e.g. if it has more than
+// MaxCSIParams parameters and sub-parameters or more than one intermediate
+// byte. It does not allocate.
+func ParseCSI(b []byte) (CSISeq, int) {
+	seq, n, _ := parseCSI(b, false)
+	return seq, n
+}
+
+// ParseCSIStrict is like ParseCSI, but it returns an error of type
+// CSIError that describes why b does not start with a valid and complete
+// CSI sequence, and it rejects the parameters greater than the maximum
+// value of a signed 32-bit integer instead of clamping them.
+func ParseCSIStrict(b []byte) (CSISeq, int, error) {
+	seq, n, kind := parseCSI(b, true)
+	if kind != 0 {
+		return seq, 0, newCSIError(kind, b)
+	}
+	return seq, n, nil
+}
+
+func parseCSI(b []byte, strict bool) (CSISeq, int, CSIErrorKind) {
+	var seq CSISeq
+	if len(b) < 2 || b[0] != '\x1b' || b[1] != '[' {
+		return seq, 0, CSIInvalid
+	}
+	if len(b) < 3 {
+		return seq, 0, CSIIncomplete
+	}
+
+	i := 2
+	if c := b[i]; c >= '<' && c <= '?' {
+		seq.Prefix = c
+		i++
+	}
+
+	// parameters
+	var (
+		cur      int64 = -1
+		inParam        = false
+		newParam       = true // next value starts a new parameter
+	)
+	for ; i < len(b); i++ {
+		c := b[i]
+		if c >= '0' && c <= '9' {
+			if cur < 0 {
+				cur = 0
+			}
+			if cur <= maxCSIParamValue {
+				cur = cur*10 + int64(c-'0')
+			}
+			if strict && cur > maxCSIParamValue {
+				return CSISeq{}, 0, CSIParamOverflow
+			}
+			inParam = true
+			continue
+		}
+		if c == ';' || c == ':' {
+			if !seq.add(cur, newParam) {
+				return CSISeq{}, 0, CSITooManyParams
+			}
+			cur, inParam, newParam = -1, true, c == ';'
+			continue
+		}
+		break
+	}
+	if inParam {
+		if !seq.add(cur, newParam) {
+			return CSISeq{}, 0, CSITooManyParams
+		}
+	}
+
+	// intermediate
+	if i < len(b) && b[i] >= 0x20 && b[i] <= 0x2f {
+		seq.Intermediate = b[i]
+		i++
+	}
+
+	// final
+	if i >= len(b) {
+		return CSISeq{}, 0, CSIIncomplete
+	}
+	if b[i] < 0x40 || b[i] > 0x7e {
+		return CSISeq{}, 0, CSIInvalid
+	}
+	seq.Final = b[i]
+	return seq, i + 1, 0
+}
+
+// adds the value v, either as a new parameter or as a sub-parameter of the
+// last parameter. It returns false if there are too many values.
+func (c *CSISeq) add(v int64, newParam bool) bool {
+	if c.nv == MaxCSIParams {
+		return false
+	}
+	if newParam {
+		c.starts[c.n] = uint8(c.nv)
+		c.n++
+	}
+	c.vals[c.nv] = clampCSIParam(v)
+	c.nv++
+	return true
+}
+
+func clampCSIParam(v int64) int {
+	if v > maxCSIParamValue {
+		return maxCSIParamValue
+	}
+	return int(v)
+}
+
+// WithCSIHandler sets a function that is called for each CSI sequence that
+// is not otherwise recognized by the Input (i.e. that would be reported as
+// a KeyESCSeq), so that user code can decode sequences that zzterm does not
+// support (e.g. extended pointer events). If fn returns true, ReadKey
+// returns the Key returned by fn, otherwise the sequence is reported as a
+// KeyESCSeq (or KeyCSIUnknown, see WithUnknownSeqTypes). The raw bytes of
+// the sequence are provided in b and can also be retrieved by calling
+// Input.Bytes after ReadKey returns. The seq and b arguments are only valid
+// for the duration of the call.
+func WithCSIHandler(fn func(seq *CSISeq, b []byte) (Key, bool)) Option {
+	return func(i *Input) {
+		i.csiHandler = fn
+	}
+}
+
+// Seq returns the parsed CSI sequence of the last key read and true if the
+// bytes of that key (as returned by Input.Bytes) are exactly a valid and
+// complete CSI sequence, e.g. for a key of type KeyCSIUnknown (see
+// WithUnknownSeqTypes). Otherwise it returns the zero value and false. The
+// final byte of the sequence is in the Final field of the CSISeq.
+func (i *Input) Seq() (CSISeq, bool) {
+	b := i.Bytes()
+	if seq, n := ParseCSI(b); n > 0 && n == len(b) {
+		return seq, true
+	}
+	return CSISeq{}, false
+}
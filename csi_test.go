@@ -0,0 +1,393 @@
+package zzterm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseCSI(t *testing.T) {
+	cases := []struct {
+		in     string
+		n      int
+		prefix byte
+		inter  byte
+		final  byte
+		params []int
+	}{
+		{"", 0, 0, 0, 0, nil},
+		{"\x1b[", 0, 0, 0, 0, nil},
+		{"\x1bOA", 0, 0, 0, 0, nil},
+		{"\x1b[A", 3, 0, 0, 'A', nil},
+		{"\x1b[Axyz", 3, 0, 0, 'A', nil},
+		{"\x1b[1;5D", 6, 0, 0, 'D', []int{1, 5}},
+		{"\x1b[;2A", 5, 0, 0, 'A', []int{-1, 2}},
+		{"\x1b[2;A", 5, 0, 0, 'A', []int{2, -1}},
+		{"\x1b[<0;10;20M", 11, '<', 0, 'M', []int{0, 10, 20}},
+		{"\x1b[?1049;1$y", 11, '?', '$', 'y', []int{1049, 1}},
+		{"\x1b[>1;2;3c", 9, '>', 0, 'c', []int{1, 2, 3}},
+		{"\x1b[99999999999999999999A", 23, 0, 0, 'A', []int{maxCSIParamValue}},
+		{"\x1b[1;2", 0, 0, 0, 0, nil},
+		{"\x1b[1:2A", 6, 0, 0, 'A', []int{1}},
+		{"\x1b[1$$A", 0, 0, 0, 0, nil},
+		{"\x1b[" + strings.Repeat("1;", MaxCSIParams-1) + "1A", 2 + 2*MaxCSIParams, 0, 0, 'A', nil},
+		{"\x1b[" + strings.Repeat("1;", MaxCSIParams) + "1A", 0, 0, 0, 0, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			seq, n := ParseCSI([]byte(c.in))
+			if n != c.n {
+				t.Fatalf("want length %d, got %d", c.n, n)
+			}
+			if n == 0 {
+				return
+			}
+			if seq.Prefix != c.prefix || seq.Intermediate != c.inter || seq.Final != c.final {
+				t.Errorf("want prefix %q, intermediate %q and final %q, got %q, %q and %q",
+					c.prefix, c.inter, c.final, seq.Prefix, seq.Intermediate, seq.Final)
+			}
+			if c.params == nil {
+				return
+			}
+			if seq.NumParams() != len(c.params) {
+				t.Fatalf("want %d params, got %d", len(c.params), seq.NumParams())
+			}
+			for i, want := range c.params {
+				if got := seq.Param(i); got != want {
+					t.Errorf("param %d: want %d, got %d", i, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCSISeq_SubParams(t *testing.T) {
+	seq, n := ParseCSI([]byte("\x1b[97:65:;2;:3u"))
+	if n != 14 {
+		t.Fatalf("want length 14, got %d", n)
+	}
+	if !seq.HasSubParams() {
+		t.Error("want sub-params")
+	}
+	cases := []struct {
+		param int
+		want  []int // param followed by its sub-params
+	}{
+		{0, []int{97, 65, -1}},
+		{1, []int{2}},
+		{2, []int{-1, 3}},
+		{3, []int{-1}},
+	}
+	for _, c := range cases {
+		got := []int{seq.Param(c.param)}
+		for i := 0; i < seq.NumSubParams(c.param); i++ {
+			got = append(got, seq.SubParam(c.param, i))
+		}
+		if fmt.Sprint(got) != fmt.Sprint(c.want) {
+			t.Errorf("param %d: want %v, got %v", c.param, c.want, got)
+		}
+	}
+	if v := seq.SubParam(1, 0); v != -1 {
+		t.Errorf("want -1, got %d", v)
+	}
+
+	seq, _ = ParseCSI([]byte("\x1b[1;2A"))
+	if seq.HasSubParams() {
+		t.Error("want no sub-params")
+	}
+	if _, n := ParseCSI([]byte("\x1b[" + strings.Repeat("1:", MaxCSIParams) + "1A")); n != 0 {
+		t.Errorf("want too many values, got length %d", n)
+	}
+}
+
+func TestCSISeq_ParamOr(t *testing.T) {
+	seq, _ := ParseCSI([]byte("\x1b[;3A"))
+	if v := seq.ParamOr(0, 1); v != 1 {
+		t.Errorf("want 1, got %d", v)
+	}
+	if v := seq.ParamOr(1, 1); v != 3 {
+		t.Errorf("want 3, got %d", v)
+	}
+	if v := seq.ParamOr(2, 7); v != 7 {
+		t.Errorf("want 7, got %d", v)
+	}
+}
+
+func TestInput_ReadKey_CSIHandler(t *testing.T) {
+	var calls int
+	input := NewInput(WithCSIHandler(func(seq *CSISeq, b []byte) (Key, bool) {
+		calls++
+		if seq.Prefix == '<' && seq.Final == 'P' && seq.NumParams() == 3 {
+			return NewKey(KeyMouse, ModNone), true
+		}
+		return 0, false
+	}))
+
+	// known sequences do not call the handler
+	k, err := input.ReadKey(strings.NewReader("\x1b[A"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.Type() != KeyUp || calls != 0 {
+		t.Errorf("want KeyUp without handler call, got %s with %d calls", k, calls)
+	}
+
+	k, err = input.ReadKey(strings.NewReader("\x1b[<1;2;3P"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.Type() != KeyMouse || calls != 1 {
+		t.Errorf("want KeyMouse with 1 handler call, got %s with %d calls", k, calls)
+	}
+	if got := string(input.Bytes()); got != "\x1b[<1;2;3P" {
+		t.Errorf("want bytes %q, got %q", "\x1b[<1;2;3P", got)
+	}
+
+	// rejected by the handler
+	k, err = input.ReadKey(strings.NewReader("\x1b[99z"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.Type() != KeyESCSeq || calls != 2 {
+		t.Errorf("want KeyESCSeq with 2 handler calls, got %s with %d calls", k, calls)
+	}
+}
+
+func TestInput_ReadKey_UnknownSeqTypes(t *testing.T) {
+	cases := []struct {
+		in    string
+		typ   KeyType
+		final byte // 0 if Seq must return false
+	}{
+		{"\x1b[99z", KeyCSIUnknown, 'z'},
+		{"\x1b[?1;2$y", KeyCSIUnknown, 'y'},
+		{"\x1b[1;2;3;4;5;6;7;8;9;10;11;12;13;14;15;16;17m", KeyCSIUnknown, 0},
+		{"\x1b]11;rgb:0000/0000/0000\a", KeyOSCUnknown, 0},
+		{"\x1bP1$r0m\x1b\\", KeyDCSUnknown, 0},
+		{"\x1bx", KeyESCSeq, 0},
+		{"\x1b[A", KeyUp, 'A'},
+		{"a", Key('a').Type(), 0},
+	}
+
+	input := NewInput(WithUnknownSeqTypes())
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			k, err := input.ReadKey(strings.NewReader(c.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if k.Type() != c.typ {
+				t.Errorf("want %s, got %s", c.typ, k.Type())
+			}
+			if got := string(input.Bytes()); got != c.in {
+				t.Errorf("want bytes %q, got %q", c.in, got)
+			}
+
+			seq, ok := input.Seq()
+			if ok != (c.final != 0) {
+				t.Fatalf("want Seq ok %t, got %t", c.final != 0, ok)
+			}
+			if seq.Final != c.final {
+				t.Errorf("want final %q, got %q", c.final, seq.Final)
+			}
+		})
+	}
+
+	// without the option, unknown sequences are KeyESCSeq
+	k, err := NewInput().ReadKey(strings.NewReader("\x1b[99z"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.Type() != KeyESCSeq {
+		t.Errorf("want %s, got %s", KeyESCSeq, k.Type())
+	}
+}
+
+func TestParseCSIStrict(t *testing.T) {
+	cases := []struct {
+		in   string
+		n    int
+		kind CSIErrorKind
+		seq  string
+	}{
+		{"\x1b[1;5D", 6, 0, ""},
+		{"\x1b[2147483647A", 13, 0, ""},
+		{"", 0, CSIInvalid, ""},
+		{"\x1bOA", 0, CSIInvalid, "\x1bOA"},
+		{"\x1b[", 0, CSIIncomplete, "\x1b["},
+		{"\x1b[1;2", 0, CSIIncomplete, "\x1b[1;2"},
+		{"\x1b[1$$Axyz", 0, CSIInvalid, "\x1b[1$$A"},
+		{"\x1b[1\x1b[A", 0, CSIInvalid, "\x1b[1"},
+		{"\x1b[2147483648A", 0, CSIParamOverflow, "\x1b[2147483648A"},
+		{"\x1b[" + strings.Repeat("9;", MaxCSIParams) + "9m", 0, CSITooManyParams, "\x1b[" + strings.Repeat("9;", MaxCSIParams) + "9m"},
+		{"\x1b[" + strings.Repeat("9:", MaxCSIParams) + "9m", 0, CSITooManyParams, "\x1b[" + strings.Repeat("9:", MaxCSIParams) + "9m"},
+		{"\x1b[" + strings.Repeat("9;", 100) + "m", 0, CSITooManyParams, ("\x1b[" + strings.Repeat("9;", 100))[:maxTokenLen]},
+	}
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			_, n, err := ParseCSIStrict([]byte(c.in))
+			if n != c.n {
+				t.Errorf("want length %d, got %d", c.n, n)
+			}
+			if c.kind == 0 {
+				if err != nil {
+					t.Fatalf("want no error, got %v", err)
+				}
+				return
+			}
+
+			var ce CSIError
+			if !errors.As(err, &ce) {
+				t.Fatalf("want CSIError, got %v", err)
+			}
+			if ce.Kind != c.kind || ce.Seq != c.seq {
+				t.Errorf("want %s %q, got %s %q", c.kind, c.seq, ce.Kind, ce.Seq)
+			}
+		})
+	}
+}
+
+func TestInput_ReadKey_StrictCSI(t *testing.T) {
+	var dropped []string
+	input := NewInput(WithStrictCSI(), WithLoggerForDroppedBytes(func(b, _ []byte, _ error) {
+		dropped = append(dropped, string(b))
+	}))
+
+	flood := "\x1b[" + strings.Repeat("9;", 1000) + "m"
+	r := &scriptReader{chunks: []string{
+		"\x1b[" + strings.Repeat("9;", 20) + "9m\x1b[A",
+		"\x1b[99999999999A",
+		"\x1b[1\x1b[B",
+		"\x1b[1;5P",
+		"\x1b[1;2",
+	}}
+	want := []struct {
+		k    Key
+		kind CSIErrorKind
+	}{
+		{0, CSITooManyParams},
+		{NewKey(KeyUp, ModNone), 0},
+		{0, CSIParamOverflow},
+		{0, CSIInvalid},
+		{NewKey(KeyDown, ModNone), 0},
+		{NewKey(KeyF1, ModCtrl), 0},
+		{NewKey(KeyESCSeq, ModNone), 0},
+	}
+	for j, w := range want {
+		k, err := input.ReadKey(r)
+		var ce CSIError
+		if w.kind != 0 {
+			if !errors.As(err, &ce) || ce.Kind != w.kind {
+				t.Fatalf("[%d]: want %s error, got %s, %v", j, w.kind, k, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("[%d]: %v", j, err)
+		}
+		if k != w.k {
+			t.Errorf("[%d]: want %s, got %s", j, w.k, k)
+		}
+	}
+	if k, err := input.ReadKey(r); err != ErrTimeout {
+		t.Errorf("want ErrTimeout, got %s, %v", k, err)
+	}
+
+	// flood longer than the buffer
+	fr := strings.NewReader(flood + "a")
+	var ce CSIError
+	if _, err := input.ReadKey(fr); !errors.As(err, &ce) || ce.Kind != CSITooLong {
+		t.Fatalf("want %s error, got %v", CSITooLong, err)
+	}
+	if k, err := input.ReadKey(fr); err != nil || k != 'a' {
+		t.Fatalf("want 'a', got %s, %v", k, err)
+	}
+
+	// the whole flood is dropped, the rest of it in chunks after the start
+	want2 := []string{
+		"\x1b[" + strings.Repeat("9;", 20) + "9m",
+		"\x1b[99999999999A",
+		"\x1b[1",
+		flood,
+	}
+	if len(dropped) > len(want2) {
+		dropped = append(dropped[:len(want2)-1], strings.Join(dropped[len(want2)-1:], ""))
+	}
+	if len(dropped) != len(want2) {
+		t.Fatalf("want %d dropped sequences, got %q", len(want2), dropped)
+	}
+	for j := range want2 {
+		if dropped[j] != want2[j] {
+			t.Errorf("[%d]: want dropped %q, got %q", j, want2[j], dropped[j])
+		}
+	}
+}
+
+func TestInput_ReadKey_StrictMode(t *testing.T) {
+	var dropped []string
+	input := NewInput(WithStrictMode(), WithMouse(), WithLoggerForDroppedBytes(func(b, _ []byte, _ error) {
+		dropped = append(dropped, string(b))
+	}))
+
+	r := &scriptReader{chunks: []string{
+		"\x1b[<0;1;1M",
+		"\x1b[<0;1Mx",
+		"\x1b[<0;1;1;1m",
+		"\x1b[<0:1;1;1M",
+		"\x1b[1;2",
+		"\x1b[1;5P",
+		"\x1b[" + strings.Repeat("9;", 20) + "9m",
+	}}
+	want := []struct {
+		k    Key
+		kind CSIErrorKind
+	}{
+		{NewKey(KeyMouse, ModNone), 0},
+		{0, CSIInvalidMouse},
+		{'x', 0},
+		{0, CSIInvalidMouse},
+		{0, CSIInvalidMouse},
+		{0, CSIIncomplete},
+		{NewKey(KeyF1, ModCtrl), 0},
+		{0, CSITooManyParams},
+	}
+	for j, w := range want {
+		k, err := input.ReadKey(r)
+		var ce CSIError
+		if w.kind != 0 {
+			if !errors.As(err, &ce) || ce.Kind != w.kind {
+				t.Fatalf("[%d]: want %s error, got %s, %v", j, w.kind, k, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("[%d]: %v", j, err)
+		}
+		if k != w.k {
+			t.Errorf("[%d]: want %s, got %s", j, w.k, k)
+		}
+	}
+
+	want2 := []string{
+		"\x1b[<0;1M",
+		"\x1b[<0;1;1;1m",
+		"\x1b[<0:1;1;1M",
+		"\x1b[1;2",
+		"\x1b[" + strings.Repeat("9;", 20) + "9m",
+	}
+	if len(dropped) != len(want2) {
+		t.Fatalf("want %d dropped sequences, got %q", len(want2), dropped)
+	}
+	for j := range want2 {
+		if dropped[j] != want2[j] {
+			t.Errorf("[%d]: want dropped %q, got %q", j, want2[j], dropped[j])
+		}
+	}
+
+	// invalid mouse reports are unknown sequences without mouse events
+	input = NewInput(WithStrictMode())
+	if k, err := input.ReadKey(strings.NewReader("\x1b[<0;1M")); err != nil || k.Type() != KeyESCSeq {
+		t.Errorf("want KeyESCSeq, got %s, %v", k, err)
+	}
+}
@@ -0,0 +1,142 @@
+package zzterm
+
+// SeqRequestDeviceAttributes is the Primary Device Attributes request (DA1)
+// to which the terminal replies with its conformance level and supported
+// features, see ParseDeviceAttributes.
+const SeqRequestDeviceAttributes = "\x1b[c"
+
+// DeviceAttributes is a decoded Primary Device Attributes reply (DA1,
+// "ESC [ ? Ps ; ... c"). The feature codes reported by the terminal are
+// decoded into named booleans, see
+// https://invisible-island.net/xterm/ctlseqs/ctlseqs.html#h3-Functions-using-CSI-_-ordered-by-the-final-character_s_
+type DeviceAttributes struct {
+	// Unknown is true if the reply does not identify the terminal, as sent
+	// by some minimal terminals: an empty or null reply ("ESC [ ? c" or
+	// "ESC [ ? 0 c") or a malformed one (e.g. with sub-parameters). The
+	// other fields are then all false or 0.
+	Unknown bool
+
+	// Level is the conformance level of the terminal: 1 for a VT100 or
+	// VT102, 2 for a VT220, 3 for a VT320, 4 for a VT420 and 5 for a VT5xx.
+	// It is 0 if the first parameter of the reply is not recognized.
+	Level int
+
+	// AdvancedVideo is true if a VT100 reports the Advanced Video Option
+	// ("ESC [ ? 1 ; 2 c"). It is always false for higher levels.
+	AdvancedVideo bool
+
+	Columns132          bool // 1: 132 columns
+	Printer             bool // 2: printer port
+	ReGIS               bool // 3: ReGIS graphics
+	Sixel               bool // 4: sixel graphics
+	SelectiveErase      bool // 6: selective erase
+	UserDefinedKeys     bool // 8: user-defined keys (DECUDK)
+	NationalCharsets    bool // 9: national replacement character sets
+	TechnicalCharacters bool // 15: technical character set
+	Locator             bool // 16: locator port
+	StateInterrogation  bool // 17: terminal state interrogation
+	Windowing           bool // 18: user windows
+	HorizontalScrolling bool // 21: horizontal scrolling
+	ANSIColor           bool // 22: ANSI color
+	RectangularEditing  bool // 28: rectangular editing
+	TextLocator         bool // 29: ANSI text locator
+}
+
+// ParseDeviceAttributes parses the Primary Device Attributes reply at the
+// start of b and returns the decoded attributes and the length of the reply
+// in bytes. It returns a length of 0 if b does not start with a complete
+// DA1 reply. The empty, null or malformed replies are returned with Unknown
+// set, so that a caller waiting for the reply always gets a result. The
+// feature codes that are not recognized are ignored.
+func ParseDeviceAttributes(b []byte) (DeviceAttributes, int) {
+	seq, n := ParseCSI(b)
+	if n == 0 || seq.Prefix != '?' || seq.Intermediate != 0 || seq.Final != 'c' {
+		return DeviceAttributes{}, 0
+	}
+	if seq.NumParams() == 0 || seq.ParamOr(0, 0) == 0 || seq.HasSubParams() {
+		return DeviceAttributes{Unknown: true}, n
+	}
+
+	var da DeviceAttributes
+	switch p := seq.Param(0); {
+	case p == 1 || p == 6:
+		// VT100 ("?1;Ps c" where Ps is the options bitmask) or VT102 ("?6c")
+		da.Level = 1
+		if p == 1 {
+			da.AdvancedVideo = seq.ParamOr(1, 0)&2 != 0
+		}
+		return da, n
+	case p >= 62 && p <= 65:
+		da.Level = p - 60
+	}
+
+	for j := 1; j < seq.NumParams(); j++ {
+		switch seq.Param(j) {
+		case 1:
+			da.Columns132 = true
+		case 2:
+			da.Printer = true
+		case 3:
+			da.ReGIS = true
+		case 4:
+			da.Sixel = true
+		case 6:
+			da.SelectiveErase = true
+		case 8:
+			da.UserDefinedKeys = true
+		case 9:
+			da.NationalCharsets = true
+		case 15:
+			da.TechnicalCharacters = true
+		case 16:
+			da.Locator = true
+		case 17:
+			da.StateInterrogation = true
+		case 18:
+			da.Windowing = true
+		case 21:
+			da.HorizontalScrolling = true
+		case 22:
+			da.ANSIColor = true
+		case 28:
+			da.RectangularEditing = true
+		case 29:
+			da.TextLocator = true
+		}
+	}
+	return da, n
+}
+
+// WithDeviceAttributes enables decoding of the Primary Device Attributes
+// replies (DA1, see SeqRequestDeviceAttributes). Such replies are returned
+// as a key of type KeyDeviceAttrs, including the empty or null replies
+// of minimal terminals, and the attributes can be retrieved by calling
+// Input.DeviceAttributes before the next call to Input.ReadKey. This option
+// takes precedence over WithDeviceReplyFilter for those replies. Without
+// this option, they are reported as KeyESCSeq.
+func WithDeviceAttributes() Option {
+	return func(i *Input) {
+		i.daOn = true
+	}
+}
+
+// DeviceAttributes returns the attributes of the last key of type
+// KeyDeviceAttrs. It should be called only after a key of type
+// KeyDeviceAttrs has been received from ReadKey, and before any other
+// call to ReadKey.
+func (i *Input) DeviceAttributes() DeviceAttributes {
+	return i.da
+}
+
+// returns either a KeyDeviceAttrs key, or a KeyESCSeq if the buffer
+// does not start with a DA1 reply. If it returns a KeyDeviceAttrs key,
+// i.sz is set to the length of the reply.
+func (i *Input) decodeDeviceAttributes() Key {
+	da, n := ParseDeviceAttributes(i.buf[:i.len])
+	if n == 0 {
+		return keyFromTypeMod(KeyESCSeq, ModNone)
+	}
+	i.da = da
+	i.sz = n
+	return keyFromTypeMod(KeyDeviceAttrs, ModNone)
+}
@@ -0,0 +1,81 @@
+package zzterm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseDeviceAttributes(t *testing.T) {
+	cases := []struct {
+		in   string
+		want DeviceAttributes
+		n    int
+	}{
+		{"", DeviceAttributes{}, 0},
+		{"\x1b[?1;2", DeviceAttributes{}, 0},
+		{"\x1b[>1;2c", DeviceAttributes{}, 0},
+		{"\x1b[?c", DeviceAttributes{Unknown: true}, 4},
+		{"\x1b[?0c", DeviceAttributes{Unknown: true}, 5},
+		{"\x1b[?;1c", DeviceAttributes{Unknown: true}, 6},
+		{"\x1b[?62:1;4c", DeviceAttributes{Unknown: true}, 10},
+		{"\x1b[?1;2c", DeviceAttributes{Level: 1, AdvancedVideo: true}, 7},
+		{"\x1b[?1;0cx", DeviceAttributes{Level: 1}, 7},
+		{"\x1b[?6c", DeviceAttributes{Level: 1}, 5},
+		{"\x1b[?62;1;2;6;8;9;15c", DeviceAttributes{
+			Level: 2, Columns132: true, Printer: true, SelectiveErase: true,
+			UserDefinedKeys: true, NationalCharsets: true, TechnicalCharacters: true,
+		}, 19},
+		{"\x1b[?65;1;4;22;28;99c", DeviceAttributes{
+			Level: 5, Columns132: true, Sixel: true, ANSIColor: true, RectangularEditing: true,
+		}, 19},
+		{"\x1b[?70;3;16;17;18;21;29c", DeviceAttributes{
+			ReGIS: true, Locator: true, StateInterrogation: true, Windowing: true,
+			HorizontalScrolling: true, TextLocator: true,
+		}, 23},
+	}
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			got, n := ParseDeviceAttributes([]byte(c.in))
+			if n != c.n {
+				t.Errorf("want length %d, got %d", c.n, n)
+			}
+			if got != c.want {
+				t.Errorf("want %+v, got %+v", c.want, got)
+			}
+		})
+	}
+}
+
+func TestInput_ReadKey_DeviceAttributes(t *testing.T) {
+	cases := []struct {
+		in   string
+		typ  KeyType
+		want DeviceAttributes
+	}{
+		{"\x1b[?62;4c", KeyDeviceAttrs, DeviceAttributes{Level: 2, Sixel: true}},
+		{"\x1b[?c", KeyDeviceAttrs, DeviceAttributes{Unknown: true}},
+		{"\x1b[?0c", KeyDeviceAttrs, DeviceAttributes{Unknown: true}},
+		{"\x1b[>1;2c", KeyESCSeq, DeviceAttributes{}},
+		{"\x1b[c", KeyESCSeq, DeviceAttributes{}},
+	}
+	input := NewInput(WithDeviceAttributes())
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			k, err := input.ReadKey(strings.NewReader(c.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if k.Type() != c.typ {
+				t.Fatalf("want %s, got %s", c.typ, k.Type())
+			}
+			if c.typ == KeyDeviceAttrs && input.DeviceAttributes() != c.want {
+				t.Errorf("want %+v, got %+v", c.want, input.DeviceAttributes())
+			}
+		})
+	}
+
+	input = NewInput()
+	if k, _ := input.ReadKey(strings.NewReader("\x1b[?0c")); k.Type() != KeyESCSeq {
+		t.Errorf("want KeyESCSeq without option, got %s", k)
+	}
+}
@@ -0,0 +1,55 @@
+package zzterm
+
+import "unicode/utf8"
+
+// Decoder decodes the bytes of the terminal input into runes. The default
+// decoder is UTF-8, it can be replaced with WithDecoder, e.g. to read from
+// a terminal that sends a single-byte encoding such as CP437. The escape
+// sequences are matched on the raw bytes, so the decoder only needs to
+// decode the bytes of regular keys, and it must decode ESC (0x1b) and the
+// other control characters as themselves.
+type Decoder interface {
+	// DecodeRune returns the first rune in b and its width in bytes. If b
+	// does not start with a valid encoding, it returns utf8.RuneError and a
+	// width of 0 or 1.
+	DecodeRune(b []byte) (r rune, size int)
+	// FullRune returns true if b starts with a full (but not necessarily
+	// valid) encoding of a rune, false if more bytes are required.
+	FullRune(b []byte) bool
+}
+
+// WithDecoder sets the decoder of the runes of the terminal input. By
+// default the input is decoded as UTF-8, with a fast path for ASCII. If d
+// is nil, the option is ignored.
+func WithDecoder(d Decoder) Option {
+	return func(i *Input) {
+		if d != nil {
+			i.dec = d
+		}
+	}
+}
+
+// decodes the first rune of b, using the decoder of i if set, otherwise
+// UTF-8. Single-byte (ASCII) input, the most common when typing, bypasses
+// utf8.DecodeRune.
+func (i *Input) decodeRune(b []byte) (rune, int) {
+	if i.dec != nil {
+		return i.dec.DecodeRune(b)
+	}
+	if len(b) > 0 && b[0] < utf8.RuneSelf {
+		return rune(b[0]), 1
+	}
+	return utf8.DecodeRune(b)
+}
+
+// returns true if b starts with a full rune, using the decoder of i if
+// set, otherwise UTF-8.
+func (i *Input) fullRune(b []byte) bool {
+	if i.dec != nil {
+		return i.dec.FullRune(b)
+	}
+	if len(b) > 0 && b[0] < utf8.RuneSelf {
+		return true
+	}
+	return utf8.FullRune(b)
+}
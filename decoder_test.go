@@ -0,0 +1,80 @@
+package zzterm
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// latin1Decoder decodes each byte as the rune with the same code point.
+type latin1Decoder struct{}
+
+func (latin1Decoder) DecodeRune(b []byte) (rune, int) {
+	if len(b) == 0 {
+		return utf8.RuneError, 0
+	}
+	return rune(b[0]), 1
+}
+
+func (latin1Decoder) FullRune(b []byte) bool { return len(b) > 0 }
+
+// utf8Decoder decodes UTF-8 without the ASCII fast path.
+type utf8Decoder struct{}
+
+func (utf8Decoder) DecodeRune(b []byte) (rune, int) { return utf8.DecodeRune(b) }
+func (utf8Decoder) FullRune(b []byte) bool          { return utf8.FullRune(b) }
+
+func TestWithDecoder(t *testing.T) {
+	input := NewInput(WithDecoder(latin1Decoder{}))
+	r := &scriptReader{chunks: []string{"a\xe9", "\x1b[A", "\xff\x03"}}
+	want := []Key{'a', 'é', keyFromTypeMod(KeyUp, ModNone), 'ÿ', keyFromTypeMod(KeyETX, ModNone)}
+	for _, w := range want {
+		k, err := input.ReadKey(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k != w {
+			t.Errorf("want %s, got %s", w, k)
+		}
+	}
+	if k, err := input.ReadKey(r); err != ErrTimeout {
+		t.Errorf("want ErrTimeout, got %s, %v", k, err)
+	}
+}
+
+func TestWithDecoder_Nil(t *testing.T) {
+	input := NewInput(WithDecoder(nil))
+	k, err := input.ReadKey(strings.NewReader("平"))
+	if err != nil || k != '平' {
+		t.Errorf("want 平, got %s, %v", k, err)
+	}
+}
+
+func BenchmarkInput_ReadKey_ASCII(b *testing.B) {
+	data := strings.Repeat("the quick brown fox jumps over the lazy dog ", 2)
+	cases := []struct {
+		name string
+		opts []Option
+	}{
+		{"fast", nil},
+		{"utf8", []Option{WithDecoder(utf8Decoder{})}},
+	}
+	for _, c := range cases {
+		input := NewInput(c.opts...)
+		b.Run(c.name, func(b *testing.B) {
+			r := strings.NewReader(data)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				for j := 0; j < len(data); j++ {
+					k, err := input.ReadKey(r)
+					if err != nil {
+						b.Fatal(err)
+					}
+					BenchmarkKey = k
+				}
+				r.Reset(data)
+			}
+		})
+	}
+}
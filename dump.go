@@ -0,0 +1,86 @@
+package zzterm
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ctrlNames = [...]string{
+	0x00: "NUL", 0x07: "BEL", 0x08: "BS", 0x09: "TAB", 0x0a: "LF",
+	0x0d: "CR", 0x1b: "ESC", 0x7f: "DEL",
+}
+
+var escIntroducers = map[byte]string{
+	'[':  "CSI",
+	']':  "OSC",
+	'P':  "DCS",
+	'O':  "SS3",
+	'\\': "ST",
+	'_':  "APC",
+	'^':  "PM",
+}
+
+// DumpBytes returns a human-readable representation of the raw input
+// bytes b, for use in logs and bug reports. The escape sequence
+// introducers are rendered by name (e.g. CSI for "ESC [", OSC for "ESC ]"
+// and ST for "ESC \"), the control characters by name (e.g. ESC, BEL, TAB)
+// or in caret notation (e.g. ^A), and the runs of printable runes as-is.
+// Spaces are rendered as SP and the tokens are separated by spaces, e.g.
+// "\x1b[1;5A x\a" is rendered as "CSI 1;5A SP x BEL". Invalid UTF-8 bytes
+// are rendered in hexadecimal (e.g. \xff) and non-printable runes as their
+// code point (e.g. U+200B).
+func DumpBytes(b []byte) string {
+	var sb strings.Builder
+	sep := func() {
+		if sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+	}
+
+	var inText bool // last token is a run of printable runes
+	for len(b) > 0 {
+		c, n := utf8.DecodeRune(b)
+		switch {
+		case c == '\x1b' && len(b) > 1 && escIntroducers[b[1]] != "":
+			sep()
+			sb.WriteString(escIntroducers[b[1]])
+			n = 2
+			inText = false
+
+		case c == utf8.RuneError && n <= 1:
+			sep()
+			fmt.Fprintf(&sb, `\x%02x`, b[0])
+			inText = false
+
+		case c < 0x20 || c == 0x7f:
+			sep()
+			if name := ctrlNames[c]; name != "" {
+				sb.WriteString(name)
+			} else {
+				sb.WriteString("^" + string(rune(c+'@')))
+			}
+			inText = false
+
+		case c == ' ':
+			sep()
+			sb.WriteString("SP")
+			inText = false
+
+		case !unicode.IsPrint(c):
+			sep()
+			fmt.Fprintf(&sb, "U+%04X", c)
+			inText = false
+
+		default:
+			if !inText {
+				sep()
+			}
+			sb.Write(b[:n])
+			inText = true
+		}
+		b = b[n:]
+	}
+	return sb.String()
+}
@@ -0,0 +1,32 @@
+package zzterm
+
+import "testing"
+
+func TestDumpBytes(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"abc", "abc"},
+		{"a b", "a SP b"},
+		{"\x1b[1;5A", "CSI 1;5A"},
+		{"\x1b[<0;10;20M", "CSI <0;10;20M"},
+		{"\x1b]9;hi\a", "OSC 9;hi BEL"},
+		{"\x1b]11;?\x1b\\", "OSC 11;? ST"},
+		{"\x1bP1$r0m\x1b\\", "DCS 1$r0m ST"},
+		{"\x1bOP", "SS3 P"},
+		{"\x1b", "ESC"},
+		{"\x1bx", "ESC x"},
+		{"\x1b\x1b[A", "ESC CSI A"},
+		{"\x01\x1a\x00\t\r\n\x7f", "^A ^Z NUL TAB CR LF DEL"},
+		{"😿é", "😿é"},
+		{"\xffa\xc3", `\xff a \xc3`},
+		{"a\u200bb\u009b", "a U+200B b U+009B"},
+	}
+	for _, c := range cases {
+		if got := DumpBytes([]byte(c.in)); got != c.want {
+			t.Errorf("%q: want %q, got %q", c.in, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package zzterm
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+// ErrInvalidEncoding is the error returned by DecodeEvent when the bytes
+// are not a valid event encoding.
+var ErrInvalidEncoding = errors.New("zzterm: invalid event encoding")
+
+// AppendEvent appends the compact binary encoding of an event to dst and
+// returns the extended buffer. The event is made of the key k, the mouse
+// event m (only encoded if k is of type KeyMouse) and the data payload
+// (e.g. the Input.Bytes of a KeyESCSeq, may be nil). This is intended to
+// forward events decoded by an Input to another process, e.g. over a pipe,
+// where DecodeEvent can decode it.
+//
+// The encoding is the key as a uvarint, followed for KeyMouse by a byte
+// holding the button ID (with the high bit set if the button is pressed, the
+// next bit set if the coordinates were clamped and the next one set for a
+// motion event),
+// the x and y coordinates and the held buttons bitmask as uvarints, and
+// finally the length of the payload as a uvarint followed by the payload
+// bytes.
+func AppendEvent(dst []byte, k Key, m MouseEvent, data []byte) []byte {
+	dst = appendUvarint(dst, uint64(k))
+	if k.Type() == KeyMouse {
+		dst = append(dst, mouseButtonByte(m))
+		dst = appendUvarint(dst, uint64(m.x))
+		dst = appendUvarint(dst, uint64(m.y))
+		dst = appendUvarint(dst, uint64(m.buttons))
+	}
+	dst = appendUvarint(dst, uint64(len(data)))
+	return append(dst, data...)
+}
+
+// DecodeEvent decodes the event encoded at the start of src by AppendEvent.
+// It returns the key, the mouse event (the zero value if the key is not of
+// type KeyMouse), the data payload and the number of bytes of src consumed
+// by the event. The data payload is a sub-slice of src, it is not copied.
+//
+// If src does not contain a complete event, it returns io.ErrUnexpectedEOF
+// so that the caller may read more bytes and try again. If src is not a
+// valid encoding, it returns ErrInvalidEncoding.
+func DecodeEvent(src []byte) (k Key, m MouseEvent, data []byte, n int, err error) {
+	kv, n, err := readUvarint(src, 0, 1<<32-1)
+	if err != nil {
+		return 0, m, nil, 0, err
+	}
+	k = Key(kv)
+
+	if k.Type() == KeyMouse {
+		if n >= len(src) {
+			return 0, m, nil, 0, io.ErrUnexpectedEOF
+		}
+		b := src[n]
+		n++
+		m.setButtonByte(b)
+
+		x, nn, err := readUvarint(src, n, math.MaxInt32)
+		if err != nil {
+			return 0, MouseEvent{}, nil, 0, err
+		}
+		y, nn, err := readUvarint(src, nn, math.MaxInt32)
+		if err != nil {
+			return 0, MouseEvent{}, nil, 0, err
+		}
+		btns, nn, err := readUvarint(src, nn, 1<<16-1)
+		if err != nil {
+			return 0, MouseEvent{}, nil, 0, err
+		}
+		m.x, m.y, m.buttons = int32(x), int32(y), uint16(btns)
+		n = nn
+	}
+
+	l, n, err := readUvarint(src, n, uint64(len(src)))
+	if err != nil {
+		if err == ErrInvalidEncoding {
+			// length is greater than the available bytes
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, MouseEvent{}, nil, 0, err
+	}
+	end := n + int(l)
+	if end > len(src) {
+		return 0, MouseEvent{}, nil, 0, io.ErrUnexpectedEOF
+	}
+	if l > 0 {
+		data = src[n:end:end]
+	}
+	return k, m, data, end, nil
+}
+
+// returns the byte encoding the button ID, pressed, clamped and motion
+// state of m.
+func mouseButtonByte(m MouseEvent) byte {
+	b := m.buttonID & 0x1f
+	if m.pressed {
+		b |= 0x80
+	}
+	if m.clamped {
+		b |= 0x40
+	}
+	if m.motion {
+		b |= 0x20
+	}
+	return b
+}
+
+// sets the button ID, pressed, clamped and motion state of m from the byte
+// b encoded by mouseButtonByte.
+func (m *MouseEvent) setButtonByte(b byte) {
+	m.buttonID = b & 0x1f
+	m.pressed = b&0x80 != 0
+	m.clamped = b&0x40 != 0
+	m.motion = b&0x20 != 0
+}
+
+func appendUvarint(dst []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(dst, buf[:n]...)
+}
+
+// reads a uvarint from src starting at index start, returns the value and
+// the index following it. Values greater than max are invalid.
+func readUvarint(src []byte, start int, max uint64) (uint64, int, error) {
+	v, n := binary.Uvarint(src[start:])
+	switch {
+	case n == 0:
+		return 0, 0, io.ErrUnexpectedEOF
+	case n < 0 || v > max:
+		return 0, 0, ErrInvalidEncoding
+	}
+	return v, start + n, nil
+}
@@ -0,0 +1,78 @@
+package zzterm
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestAppendDecodeEvent(t *testing.T) {
+	type event struct {
+		k    Key
+		m    MouseEvent
+		data string
+	}
+	events := []event{
+		{Key('a'), MouseEvent{}, ""},
+		{Key('👪'), MouseEvent{}, ""},
+		{keyFromTypeMod(KeyUp, ModShift|ModCtrl), MouseEvent{}, ""},
+		{keyFromTypeMod(KeyMouse, ModShift), MouseEvent{buttonID: 3, pressed: true, x: 123, y: 542, buttons: 0b101}, ""},
+		{keyFromTypeMod(KeyMouse, ModNone), MouseEvent{buttonID: 11, x: 65535, y: 1}, ""},
+		{keyFromTypeMod(KeyMouse, ModNone), MouseEvent{buttonID: 1, clamped: true, x: 1<<31 - 1, y: 70000}, ""},
+		{keyFromTypeMod(KeyESCSeq, ModNone), MouseEvent{}, "\x1b[abc"},
+	}
+
+	var buf []byte
+	for _, e := range events {
+		buf = AppendEvent(buf, e.k, e.m, []byte(e.data))
+	}
+
+	for i, want := range events {
+		k, m, data, n, err := DecodeEvent(buf)
+		if err != nil {
+			t.Fatalf("[%d]: %v", i, err)
+		}
+		if k != want.k || m != want.m || string(data) != want.data {
+			t.Fatalf("[%d]: want %s %s %q, got %s %s %q", i, want.k, want.m, want.data, k, m, data)
+		}
+		buf = buf[n:]
+	}
+	if len(buf) != 0 {
+		t.Fatalf("want all bytes consumed, %d remaining", len(buf))
+	}
+}
+
+func TestDecodeEvent_Invalid(t *testing.T) {
+	full := AppendEvent(nil, keyFromTypeMod(KeyMouse, ModNone), MouseEvent{buttonID: 1, x: 10, y: 20}, []byte("abc"))
+	for i := 0; i < len(full); i++ {
+		if _, _, _, _, err := DecodeEvent(full[:i]); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("[%d]: want io.ErrUnexpectedEOF, got %v", i, err)
+		}
+	}
+
+	// mouse coordinate overflows int32
+	invalid := appendUvarint(nil, uint64(keyFromTypeMod(KeyMouse, ModNone)))
+	invalid = append(invalid, 1)
+	invalid = appendUvarint(invalid, 1<<31)
+	invalid = append(invalid, 1, 0, 0)
+	if _, _, _, _, err := DecodeEvent(invalid); !errors.Is(err, ErrInvalidEncoding) {
+		t.Errorf("want ErrInvalidEncoding, got %v", err)
+	}
+
+	// key overflows uint32
+	invalid = appendUvarint(nil, 1<<32)
+	invalid = append(invalid, 0)
+	if _, _, _, _, err := DecodeEvent(invalid); !errors.Is(err, ErrInvalidEncoding) {
+		t.Errorf("want ErrInvalidEncoding, got %v", err)
+	}
+
+	// payload is not copied
+	_, _, data, _, err := DecodeEvent(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, []byte("abc")) || &data[0] != &full[len(full)-3] {
+		t.Errorf("want payload to be a sub-slice of the source")
+	}
+}
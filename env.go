@@ -0,0 +1,73 @@
+package zzterm
+
+import (
+	"os"
+	"strings"
+)
+
+// Environment is the terminal environment detected by DetectEnvironment.
+type Environment struct {
+	// Terminal is the name of the detected terminal or terminal
+	// multiplexer, e.g. "xterm", "wezterm", "tmux" or "windows-terminal".
+	// It is empty if nothing could be detected.
+	Terminal string
+
+	// Profile is the recommended profile for the terminal, it is never nil.
+	Profile *Profile
+
+	// Options is the recommended set of options to pass to NewInput, the
+	// caller may append its own options to override them. In addition to
+	// the profile, it enables the decoding of the extended key protocols
+	// supported by the terminal, e.g. WithKitty for kitty.
+	Options []Option
+}
+
+// DetectEnvironment inspects the standard environment variables that
+// identify the terminal (TERM, TERM_PROGRAM, WT_SESSION, KITTY_WINDOW_ID and
+// TMUX) and returns the detected environment, so that an Input can be
+// configured in a single call:
+//
+//	env := zzterm.DetectEnvironment()
+//	input := zzterm.NewInput(env.Options...)
+//
+// Terminal multiplexers take precedence over the outer terminal as they
+// rewrite its key sequences. If the terminal cannot be identified, the
+// xterm profile is recommended as it is the most widely compatible.
+func DetectEnvironment() Environment {
+	return detectEnvironment(os.Getenv)
+}
+
+func detectEnvironment(getenv func(string) string) Environment {
+	term := getenv("TERM")
+
+	var name string
+	var opts []Option
+	p := ProfileXterm
+	switch {
+	case getenv("TMUX") != "", strings.HasPrefix(term, "tmux"):
+		// tmux sends the same key sequences as screen, and the extended keys
+		// in the xterm modifyOtherKeys form if enabled
+		name, p = "tmux", ProfileScreen
+		opts = append(opts, WithModifyOtherKeys())
+	case strings.HasPrefix(term, "screen"):
+		name, p = "screen", ProfileScreen
+	case getenv("TERM_PROGRAM") == "WezTerm", strings.HasPrefix(term, "wezterm"):
+		name, p = "wezterm", ProfileWezTerm
+	case strings.HasPrefix(term, "foot"):
+		name, p = "foot", ProfileFoot
+	case getenv("KITTY_WINDOW_ID") != "", term == "xterm-kitty":
+		name = "kitty"
+		opts = append(opts, WithKitty())
+	case getenv("WT_SESSION") != "":
+		name = "windows-terminal"
+	case getenv("TERM_PROGRAM") != "":
+		name = getenv("TERM_PROGRAM")
+	case term != "" && term != "dumb":
+		name = strings.SplitN(term, "-", 2)[0]
+	}
+	return Environment{
+		Terminal: name,
+		Profile:  p,
+		Options:  append([]Option{WithProfile(p)}, opts...),
+	}
+}
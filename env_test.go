@@ -0,0 +1,66 @@
+package zzterm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectEnvironment(t *testing.T) {
+	cases := []struct {
+		env     map[string]string
+		name    string
+		profile *Profile
+		kitty   bool // kitty keyboard protocol decoded
+		modKeys bool // modifyOtherKeys decoded
+	}{
+		{nil, "", ProfileXterm, false, false},
+		{map[string]string{"TERM": "dumb"}, "", ProfileXterm, false, false},
+		{map[string]string{"TERM": "xterm-256color"}, "xterm", ProfileXterm, false, false},
+		{map[string]string{"TERM": "screen-256color"}, "screen", ProfileScreen, false, false},
+		{map[string]string{"TERM": "tmux-256color"}, "tmux", ProfileScreen, false, true},
+		{map[string]string{"TERM": "xterm-256color", "TMUX": "/tmp/tmux-1000/default,1,0"}, "tmux", ProfileScreen, false, true},
+		{map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "WezTerm"}, "wezterm", ProfileWezTerm, true, true},
+		{map[string]string{"TERM": "foot-extra"}, "foot", ProfileFoot, true, true},
+		{map[string]string{"TERM": "xterm-kitty"}, "kitty", ProfileXterm, true, false},
+		{map[string]string{"TERM": "xterm-256color", "KITTY_WINDOW_ID": "1"}, "kitty", ProfileXterm, true, false},
+		{map[string]string{"TERM": "xterm-256color", "WT_SESSION": "abc"}, "windows-terminal", ProfileXterm, false, false},
+		{map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "iTerm.app"}, "iTerm.app", ProfileXterm, false, false},
+		{map[string]string{"TERM": "screen", "TERM_PROGRAM": "WezTerm"}, "screen", ProfileScreen, false, false},
+	}
+	for _, c := range cases {
+		env := detectEnvironment(func(k string) string { return c.env[k] })
+		if env.Terminal != c.name || env.Profile != c.profile {
+			t.Errorf("%v: want %s %s, got %s %s", c.env, c.name, c.profile, env.Terminal, env.Profile)
+		}
+
+		// the options configure the profile
+		input := NewInput(env.Options...)
+		if len(input.esc) != len(c.profile.esc) {
+			t.Errorf("%v: want %d sequences, got %d", c.env, len(c.profile.esc), len(input.esc))
+		}
+		if input.kittyOn != c.kitty || input.modKeys != c.modKeys {
+			t.Errorf("%v: want kitty %t, modifyOtherKeys %t, got %t, %t", c.env, c.kitty, c.modKeys, input.kittyOn, input.modKeys)
+		}
+	}
+}
+
+func TestDetectEnvironment_Kitty(t *testing.T) {
+	env := detectEnvironment(func(k string) string {
+		return map[string]string{"TERM": "xterm-kitty"}[k]
+	})
+	if len(env.Options) != 2 {
+		t.Fatalf("want 2 options, got %d", len(env.Options))
+	}
+
+	input := NewInput(env.Options...)
+	k, err := input.ReadKey(strings.NewReader("\x1b[97;5u"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := NewRuneKey('a', ModCtrl); k != want {
+		t.Errorf("want %s, got %s", want, k)
+	}
+	if kk := input.Kitty(); kk.Code != 'a' || kk.Mod != ModCtrl {
+		t.Errorf("want kitty key Ctrl-a, got %+v", kk)
+	}
+}
@@ -2,6 +2,9 @@ package zzterm
 
 import (
 	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -25,40 +28,98 @@ func FromTerminfo(v interface{}) map[string]string {
 	return m
 }
 
-var defaultEsc = map[string]Key{
-	"\x1b[A":     keyFromTypeMod(KeyUp, ModNone),
-	"\x1b[B":     keyFromTypeMod(KeyDown, ModNone),
-	"\x1b[C":     keyFromTypeMod(KeyRight, ModNone),
-	"\x1b[D":     keyFromTypeMod(KeyLeft, ModNone),
-	"\x1b[2~":    keyFromTypeMod(KeyInsert, ModNone),
-	"\x1b[3~":    keyFromTypeMod(KeyDelete, ModNone),
-	"\x1b[Z":     keyFromTypeMod(KeyBacktab, ModNone),
-	"\x1b[H":     keyFromTypeMod(KeyHome, ModNone),
-	"\x1b[F":     keyFromTypeMod(KeyEnd, ModNone),
-	"\x1b[5~":    keyFromTypeMod(KeyPgUp, ModNone),
-	"\x1b[6~":    keyFromTypeMod(KeyPgDn, ModNone),
-	"\x1bOP":     keyFromTypeMod(KeyF1, ModNone),
-	"\x1bOQ":     keyFromTypeMod(KeyF2, ModNone),
-	"\x1bOR":     keyFromTypeMod(KeyF3, ModNone),
-	"\x1bOS":     keyFromTypeMod(KeyF4, ModNone),
-	"\x1b[15~":   keyFromTypeMod(KeyF5, ModNone),
-	"\x1b[17~":   keyFromTypeMod(KeyF6, ModNone),
-	"\x1b[18~":   keyFromTypeMod(KeyF7, ModNone),
-	"\x1b[19~":   keyFromTypeMod(KeyF8, ModNone),
-	"\x1b[20~":   keyFromTypeMod(KeyF9, ModNone),
-	"\x1b[21~":   keyFromTypeMod(KeyF10, ModNone),
-	"\x1b[23~":   keyFromTypeMod(KeyF11, ModNone),
-	"\x1b[24~":   keyFromTypeMod(KeyF12, ModNone),
-	"\x1b[1;2P":  keyFromTypeMod(KeyF13, ModNone),
-	"\x1b[1;2Q":  keyFromTypeMod(KeyF14, ModNone),
-	"\x1b[1;2R":  keyFromTypeMod(KeyF15, ModNone),
-	"\x1b[1;2S":  keyFromTypeMod(KeyF16, ModNone),
-	"\x1b[15;2~": keyFromTypeMod(KeyF17, ModNone),
-	"\x1b[17;2~": keyFromTypeMod(KeyF18, ModNone),
-	"\x1b[18;2~": keyFromTypeMod(KeyF19, ModNone),
-	"\x1b[19;2~": keyFromTypeMod(KeyF20, ModNone),
-	"\x1b[1;2D":  keyFromTypeMod(KeyLeft, ModShift),
-	"\x1b[1;2C":  keyFromTypeMod(KeyRight, ModShift),
+var defaultEsc = func() map[string]Key {
+	m := map[string]Key{
+		"\x1b[A":    keyFromTypeMod(KeyUp, ModNone),
+		"\x1b[B":    keyFromTypeMod(KeyDown, ModNone),
+		"\x1b[C":    keyFromTypeMod(KeyRight, ModNone),
+		"\x1b[D":    keyFromTypeMod(KeyLeft, ModNone),
+		"\x1b[2~":   keyFromTypeMod(KeyInsert, ModNone),
+		"\x1b[3~":   keyFromTypeMod(KeyDelete, ModNone),
+		"\x1b[Z":    keyFromTypeMod(KeyBacktab, ModNone),
+		"\x1b[H":    keyFromTypeMod(KeyHome, ModNone),
+		"\x1b[F":    keyFromTypeMod(KeyEnd, ModNone),
+		"\x1b[5~":   keyFromTypeMod(KeyPgUp, ModNone),
+		"\x1b[6~":   keyFromTypeMod(KeyPgDn, ModNone),
+		"\x1bOP":    keyFromTypeMod(KeyF1, ModNone),
+		"\x1bOQ":    keyFromTypeMod(KeyF2, ModNone),
+		"\x1bOR":    keyFromTypeMod(KeyF3, ModNone),
+		"\x1bOS":    keyFromTypeMod(KeyF4, ModNone),
+		"\x1b[15~":  keyFromTypeMod(KeyF5, ModNone),
+		"\x1b[17~":  keyFromTypeMod(KeyF6, ModNone),
+		"\x1b[18~":  keyFromTypeMod(KeyF7, ModNone),
+		"\x1b[19~":  keyFromTypeMod(KeyF8, ModNone),
+		"\x1b[20~":  keyFromTypeMod(KeyF9, ModNone),
+		"\x1b[21~":  keyFromTypeMod(KeyF10, ModNone),
+		"\x1b[23~":  keyFromTypeMod(KeyF11, ModNone),
+		"\x1b[24~":  keyFromTypeMod(KeyF12, ModNone),
+		"\x1b[1;2D": keyFromTypeMod(KeyLeft, ModShift),
+		"\x1b[1;2C": keyFromTypeMod(KeyRight, ModShift),
+	}
+	addModFuncKeys(m)
+	addKeypadESCSeq(m)
+	return m
+}()
+
+// xterm reports the function keys F1-F12 pressed with modifiers as
+// CSI 1 ; <mod> P/Q/R/S for F1-F4 and CSI <code> ; <mod> ~ for F5-F12.
+var (
+	xtermFuncKeysSS3 = [...]byte{'P', 'Q', 'R', 'S'}
+	xtermFuncKeysCSI = [...]int{15, 17, 18, 19, 20, 21, 23, 24}
+)
+
+// adds the escape sequences of the function keys F1-F12 pressed with any
+// combination of the Shift, Alt and Ctrl modifiers.
+func addModFuncKeys(m map[string]Key) {
+	for param := 2; param <= 8; param++ {
+		mod := ModFromXtermParam(param)
+		for i, c := range xtermFuncKeysSS3 {
+			m[fmt.Sprintf("\x1b[1;%d%c", param, c)] = keyFromTypeMod(KeyF1+KeyType(i), mod)
+		}
+		for i, code := range xtermFuncKeysCSI {
+			m[fmt.Sprintf("\x1b[%d;%d~", code, param)] = keyFromTypeMod(KeyF5+KeyType(i), mod)
+		}
+	}
+}
+
+// xterm reports the navigation keys pressed with modifiers as
+// CSI 1 ; <mod> A/B/C/D/H/F for the arrows, Home and End, and as
+// CSI <code> ; <mod> ~ for Insert, Delete, PgUp and PgDn.
+var (
+	xtermNavKeysFinal = map[byte]KeyType{
+		'A': KeyUp, 'B': KeyDown, 'C': KeyRight, 'D': KeyLeft, 'H': KeyHome, 'F': KeyEnd,
+	}
+	xtermNavKeysCode = map[int]KeyType{
+		2: KeyInsert, 3: KeyDelete, 5: KeyPgUp, 6: KeyPgDn,
+	}
+)
+
+// returns the navigation key decoded from the xterm form of the sequences
+// with modifiers, CSI 1 ; <mod> A/B/C/D/H/F or CSI <code> ; <mod> ~, for any
+// modifier parameter between 1 and 16. It returns a KeyESCSeq if b does not
+// start with such a sequence, otherwise it also returns the length of the
+// sequence.
+func decodeModNavKey(b []byte) (Key, int) {
+	seq, n := ParseCSI(b)
+	if n == 0 || seq.Prefix != 0 || seq.Intermediate != 0 || seq.NumParams() != 2 || seq.HasSubParams() {
+		return keyFromTypeMod(KeyESCSeq, ModNone), 0
+	}
+	param := seq.Param(1)
+	if param < 1 || param > 16 {
+		return keyFromTypeMod(KeyESCSeq, ModNone), 0
+	}
+
+	var t KeyType
+	var ok bool
+	if seq.Final == '~' {
+		t, ok = xtermNavKeysCode[seq.Param(0)]
+	} else if seq.Param(0) == 1 {
+		t, ok = xtermNavKeysFinal[seq.Final]
+	}
+	if !ok {
+		return keyFromTypeMod(KeyESCSeq, ModNone), 0
+	}
+	return keyFromTypeMod(t, ModFromXtermParam(param)), n
 }
 
 func cloneEscMap(m map[string]Key) map[string]Key {
@@ -74,6 +135,153 @@ func addFocusESCSeq(m map[string]Key) {
 	m["\x1b[O"] = keyFromTypeMod(KeyFocusOut, ModNone)
 }
 
+// Shift-Insert in the xterm form with modifiers, a paste trigger.
+func addPasteRequestESCSeq(m map[string]Key) {
+	if _, ok := m[shiftInsertSeq]; !ok {
+		m[shiftInsertSeq] = keyFromTypeMod(KeyInsert, ModShift)
+	}
+}
+
+// readline-style Meta navigation keys, as sent by terminals that send ESC
+// before the key pressed with Alt.
+func addReadlineMetaESCSeq(m map[string]Key) {
+	m["\x1bb"] = keyFromTypeMod(KeyLeft, ModAlt)
+	m["\x1bf"] = keyFromTypeMod(KeyRight, ModAlt)
+	m["\x1bd"] = keyFromTypeMod(KeyDelete, ModAlt)
+	m["\x1b\x7f"] = keyFromTypeMod(KeyDEL, ModAlt)
+	m["\x1b\b"] = keyFromTypeMod(KeyBS, ModAlt)
+}
+
+// terminfoKeys maps the supported terminfo key names to the corresponding
+// Key.
+var terminfoKeys = map[string]Key{
+	"KeyBackspace":    keyFromTypeMod(KeyBS, ModNone),
+	"KeyF1":           keyFromTypeMod(KeyF1, ModNone),
+	"KeyF2":           keyFromTypeMod(KeyF2, ModNone),
+	"KeyF3":           keyFromTypeMod(KeyF3, ModNone),
+	"KeyF4":           keyFromTypeMod(KeyF4, ModNone),
+	"KeyF5":           keyFromTypeMod(KeyF5, ModNone),
+	"KeyF6":           keyFromTypeMod(KeyF6, ModNone),
+	"KeyF7":           keyFromTypeMod(KeyF7, ModNone),
+	"KeyF8":           keyFromTypeMod(KeyF8, ModNone),
+	"KeyF9":           keyFromTypeMod(KeyF9, ModNone),
+	"KeyF10":          keyFromTypeMod(KeyF10, ModNone),
+	"KeyF11":          keyFromTypeMod(KeyF11, ModNone),
+	"KeyF12":          keyFromTypeMod(KeyF12, ModNone),
+	"KeyF13":          keyFromTypeMod(KeyF13, ModNone),
+	"KeyF14":          keyFromTypeMod(KeyF14, ModNone),
+	"KeyF15":          keyFromTypeMod(KeyF15, ModNone),
+	"KeyF16":          keyFromTypeMod(KeyF16, ModNone),
+	"KeyF17":          keyFromTypeMod(KeyF17, ModNone),
+	"KeyF18":          keyFromTypeMod(KeyF18, ModNone),
+	"KeyF19":          keyFromTypeMod(KeyF19, ModNone),
+	"KeyF20":          keyFromTypeMod(KeyF20, ModNone),
+	"KeyF21":          keyFromTypeMod(KeyF21, ModNone),
+	"KeyF22":          keyFromTypeMod(KeyF22, ModNone),
+	"KeyF23":          keyFromTypeMod(KeyF23, ModNone),
+	"KeyF24":          keyFromTypeMod(KeyF24, ModNone),
+	"KeyF25":          keyFromTypeMod(KeyF25, ModNone),
+	"KeyF26":          keyFromTypeMod(KeyF26, ModNone),
+	"KeyF27":          keyFromTypeMod(KeyF27, ModNone),
+	"KeyF28":          keyFromTypeMod(KeyF28, ModNone),
+	"KeyF29":          keyFromTypeMod(KeyF29, ModNone),
+	"KeyF30":          keyFromTypeMod(KeyF30, ModNone),
+	"KeyF31":          keyFromTypeMod(KeyF31, ModNone),
+	"KeyF32":          keyFromTypeMod(KeyF32, ModNone),
+	"KeyF33":          keyFromTypeMod(KeyF33, ModNone),
+	"KeyF34":          keyFromTypeMod(KeyF34, ModNone),
+	"KeyF35":          keyFromTypeMod(KeyF35, ModNone),
+	"KeyF36":          keyFromTypeMod(KeyF36, ModNone),
+	"KeyF37":          keyFromTypeMod(KeyF37, ModNone),
+	"KeyF38":          keyFromTypeMod(KeyF38, ModNone),
+	"KeyF39":          keyFromTypeMod(KeyF39, ModNone),
+	"KeyF40":          keyFromTypeMod(KeyF40, ModNone),
+	"KeyF41":          keyFromTypeMod(KeyF41, ModNone),
+	"KeyF42":          keyFromTypeMod(KeyF42, ModNone),
+	"KeyF43":          keyFromTypeMod(KeyF43, ModNone),
+	"KeyF44":          keyFromTypeMod(KeyF44, ModNone),
+	"KeyF45":          keyFromTypeMod(KeyF45, ModNone),
+	"KeyF46":          keyFromTypeMod(KeyF46, ModNone),
+	"KeyF47":          keyFromTypeMod(KeyF47, ModNone),
+	"KeyF48":          keyFromTypeMod(KeyF48, ModNone),
+	"KeyF49":          keyFromTypeMod(KeyF49, ModNone),
+	"KeyF50":          keyFromTypeMod(KeyF50, ModNone),
+	"KeyF51":          keyFromTypeMod(KeyF51, ModNone),
+	"KeyF52":          keyFromTypeMod(KeyF52, ModNone),
+	"KeyF53":          keyFromTypeMod(KeyF53, ModNone),
+	"KeyF54":          keyFromTypeMod(KeyF54, ModNone),
+	"KeyF55":          keyFromTypeMod(KeyF55, ModNone),
+	"KeyF56":          keyFromTypeMod(KeyF56, ModNone),
+	"KeyF57":          keyFromTypeMod(KeyF57, ModNone),
+	"KeyF58":          keyFromTypeMod(KeyF58, ModNone),
+	"KeyF59":          keyFromTypeMod(KeyF59, ModNone),
+	"KeyF60":          keyFromTypeMod(KeyF60, ModNone),
+	"KeyF61":          keyFromTypeMod(KeyF61, ModNone),
+	"KeyF62":          keyFromTypeMod(KeyF62, ModNone),
+	"KeyF63":          keyFromTypeMod(KeyF63, ModNone),
+	"KeyF64":          keyFromTypeMod(KeyF64, ModNone),
+	"KeyInsert":       keyFromTypeMod(KeyInsert, ModNone),
+	"KeyDelete":       keyFromTypeMod(KeyDelete, ModNone),
+	"KeyHome":         keyFromTypeMod(KeyHome, ModNone),
+	"KeyEnd":          keyFromTypeMod(KeyEnd, ModNone),
+	"KeyHelp":         keyFromTypeMod(KeyHelp, ModNone),
+	"KeyPgUp":         keyFromTypeMod(KeyPgUp, ModNone),
+	"KeyPgDn":         keyFromTypeMod(KeyPgDn, ModNone),
+	"KeyUp":           keyFromTypeMod(KeyUp, ModNone),
+	"KeyDown":         keyFromTypeMod(KeyDown, ModNone),
+	"KeyLeft":         keyFromTypeMod(KeyLeft, ModNone),
+	"KeyRight":        keyFromTypeMod(KeyRight, ModNone),
+	"KeyBacktab":      keyFromTypeMod(KeyBacktab, ModNone),
+	"KeyExit":         keyFromTypeMod(KeyExit, ModNone),
+	"KeyClear":        keyFromTypeMod(KeyClear, ModNone),
+	"KeyPrint":        keyFromTypeMod(KeyPrint, ModNone),
+	"KeyCancel":       keyFromTypeMod(KeyCancel, ModNone),
+	"KeyShfRight":     keyFromTypeMod(KeyRight, ModShift),
+	"KeyShfLeft":      keyFromTypeMod(KeyLeft, ModShift),
+	"KeyShfHome":      keyFromTypeMod(KeyHome, ModShift),
+	"KeyShfEnd":       keyFromTypeMod(KeyEnd, ModShift),
+	"KeyShfUp":        keyFromTypeMod(KeyUp, ModShift),
+	"KeyShfDown":      keyFromTypeMod(KeyDown, ModShift),
+	"KeyShfPgUp":      keyFromTypeMod(KeyPgUp, ModShift),
+	"KeyShfPgDn":      keyFromTypeMod(KeyPgDn, ModShift),
+	"KeyCtrlUp":       keyFromTypeMod(KeyUp, ModCtrl),
+	"KeyCtrlDown":     keyFromTypeMod(KeyDown, ModCtrl),
+	"KeyCtrlRight":    keyFromTypeMod(KeyRight, ModCtrl),
+	"KeyCtrlLeft":     keyFromTypeMod(KeyLeft, ModCtrl),
+	"KeyMetaUp":       keyFromTypeMod(KeyUp, ModMeta),
+	"KeyMetaDown":     keyFromTypeMod(KeyDown, ModMeta),
+	"KeyMetaRight":    keyFromTypeMod(KeyRight, ModMeta),
+	"KeyMetaLeft":     keyFromTypeMod(KeyLeft, ModMeta),
+	"KeyAltUp":        keyFromTypeMod(KeyUp, ModAlt),
+	"KeyAltDown":      keyFromTypeMod(KeyDown, ModAlt),
+	"KeyAltRight":     keyFromTypeMod(KeyRight, ModAlt),
+	"KeyAltLeft":      keyFromTypeMod(KeyLeft, ModAlt),
+	"KeyCtrlHome":     keyFromTypeMod(KeyHome, ModCtrl),
+	"KeyCtrlEnd":      keyFromTypeMod(KeyEnd, ModCtrl),
+	"KeyMetaHome":     keyFromTypeMod(KeyHome, ModMeta),
+	"KeyMetaEnd":      keyFromTypeMod(KeyEnd, ModMeta),
+	"KeyAltHome":      keyFromTypeMod(KeyHome, ModAlt),
+	"KeyAltEnd":       keyFromTypeMod(KeyEnd, ModAlt),
+	"KeyAltShfUp":     keyFromTypeMod(KeyUp, ModAlt|ModShift),
+	"KeyAltShfDown":   keyFromTypeMod(KeyDown, ModAlt|ModShift),
+	"KeyAltShfLeft":   keyFromTypeMod(KeyLeft, ModAlt|ModShift),
+	"KeyAltShfRight":  keyFromTypeMod(KeyRight, ModAlt|ModShift),
+	"KeyMetaShfUp":    keyFromTypeMod(KeyUp, ModMeta|ModShift),
+	"KeyMetaShfDown":  keyFromTypeMod(KeyDown, ModMeta|ModShift),
+	"KeyMetaShfLeft":  keyFromTypeMod(KeyLeft, ModMeta|ModShift),
+	"KeyMetaShfRight": keyFromTypeMod(KeyRight, ModMeta|ModShift),
+	"KeyCtrlShfUp":    keyFromTypeMod(KeyUp, ModCtrl|ModShift),
+	"KeyCtrlShfDown":  keyFromTypeMod(KeyDown, ModCtrl|ModShift),
+	"KeyCtrlShfLeft":  keyFromTypeMod(KeyLeft, ModCtrl|ModShift),
+	"KeyCtrlShfRight": keyFromTypeMod(KeyRight, ModCtrl|ModShift),
+	"KeyCtrlShfHome":  keyFromTypeMod(KeyHome, ModCtrl|ModShift),
+	"KeyCtrlShfEnd":   keyFromTypeMod(KeyEnd, ModCtrl|ModShift),
+	"KeyAltShfHome":   keyFromTypeMod(KeyHome, ModAlt|ModShift),
+	"KeyAltShfEnd":    keyFromTypeMod(KeyEnd, ModAlt|ModShift),
+	"KeyMetaShfHome":  keyFromTypeMod(KeyHome, ModMeta|ModShift),
+	"KeyMetaShfEnd":   keyFromTypeMod(KeyEnd, ModMeta|ModShift),
+}
+
 func escFromTerminfo(tinfo map[string]string) map[string]Key {
 	if tinfo == nil {
 		return cloneEscMap(defaultEsc)
@@ -84,258 +292,150 @@ func escFromTerminfo(tinfo map[string]string) map[string]Key {
 		if !strings.HasPrefix(k, "Key") || !strings.HasPrefix(v, "\x1b") {
 			continue
 		}
-		switch k {
-		case "KeyBackspace":
-			m[v] = keyFromTypeMod(KeyBS, ModNone)
-		case "KeyF1":
-			m[v] = keyFromTypeMod(KeyF1, ModNone)
-		case "KeyF2":
-			m[v] = keyFromTypeMod(KeyF2, ModNone)
-		case "KeyF3":
-			m[v] = keyFromTypeMod(KeyF3, ModNone)
-		case "KeyF4":
-			m[v] = keyFromTypeMod(KeyF4, ModNone)
-		case "KeyF5":
-			m[v] = keyFromTypeMod(KeyF5, ModNone)
-		case "KeyF6":
-			m[v] = keyFromTypeMod(KeyF6, ModNone)
-		case "KeyF7":
-			m[v] = keyFromTypeMod(KeyF7, ModNone)
-		case "KeyF8":
-			m[v] = keyFromTypeMod(KeyF8, ModNone)
-		case "KeyF9":
-			m[v] = keyFromTypeMod(KeyF9, ModNone)
-		case "KeyF10":
-			m[v] = keyFromTypeMod(KeyF10, ModNone)
-		case "KeyF11":
-			m[v] = keyFromTypeMod(KeyF11, ModNone)
-		case "KeyF12":
-			m[v] = keyFromTypeMod(KeyF12, ModNone)
-		case "KeyF13":
-			m[v] = keyFromTypeMod(KeyF13, ModNone)
-		case "KeyF14":
-			m[v] = keyFromTypeMod(KeyF14, ModNone)
-		case "KeyF15":
-			m[v] = keyFromTypeMod(KeyF15, ModNone)
-		case "KeyF16":
-			m[v] = keyFromTypeMod(KeyF16, ModNone)
-		case "KeyF17":
-			m[v] = keyFromTypeMod(KeyF17, ModNone)
-		case "KeyF18":
-			m[v] = keyFromTypeMod(KeyF18, ModNone)
-		case "KeyF19":
-			m[v] = keyFromTypeMod(KeyF19, ModNone)
-		case "KeyF20":
-			m[v] = keyFromTypeMod(KeyF20, ModNone)
-		case "KeyF21":
-			m[v] = keyFromTypeMod(KeyF21, ModNone)
-		case "KeyF22":
-			m[v] = keyFromTypeMod(KeyF22, ModNone)
-		case "KeyF23":
-			m[v] = keyFromTypeMod(KeyF23, ModNone)
-		case "KeyF24":
-			m[v] = keyFromTypeMod(KeyF24, ModNone)
-		case "KeyF25":
-			m[v] = keyFromTypeMod(KeyF25, ModNone)
-		case "KeyF26":
-			m[v] = keyFromTypeMod(KeyF26, ModNone)
-		case "KeyF27":
-			m[v] = keyFromTypeMod(KeyF27, ModNone)
-		case "KeyF28":
-			m[v] = keyFromTypeMod(KeyF28, ModNone)
-		case "KeyF29":
-			m[v] = keyFromTypeMod(KeyF29, ModNone)
-		case "KeyF30":
-			m[v] = keyFromTypeMod(KeyF30, ModNone)
-		case "KeyF31":
-			m[v] = keyFromTypeMod(KeyF31, ModNone)
-		case "KeyF32":
-			m[v] = keyFromTypeMod(KeyF32, ModNone)
-		case "KeyF33":
-			m[v] = keyFromTypeMod(KeyF33, ModNone)
-		case "KeyF34":
-			m[v] = keyFromTypeMod(KeyF34, ModNone)
-		case "KeyF35":
-			m[v] = keyFromTypeMod(KeyF35, ModNone)
-		case "KeyF36":
-			m[v] = keyFromTypeMod(KeyF36, ModNone)
-		case "KeyF37":
-			m[v] = keyFromTypeMod(KeyF37, ModNone)
-		case "KeyF38":
-			m[v] = keyFromTypeMod(KeyF38, ModNone)
-		case "KeyF39":
-			m[v] = keyFromTypeMod(KeyF39, ModNone)
-		case "KeyF40":
-			m[v] = keyFromTypeMod(KeyF40, ModNone)
-		case "KeyF41":
-			m[v] = keyFromTypeMod(KeyF41, ModNone)
-		case "KeyF42":
-			m[v] = keyFromTypeMod(KeyF42, ModNone)
-		case "KeyF43":
-			m[v] = keyFromTypeMod(KeyF43, ModNone)
-		case "KeyF44":
-			m[v] = keyFromTypeMod(KeyF44, ModNone)
-		case "KeyF45":
-			m[v] = keyFromTypeMod(KeyF45, ModNone)
-		case "KeyF46":
-			m[v] = keyFromTypeMod(KeyF46, ModNone)
-		case "KeyF47":
-			m[v] = keyFromTypeMod(KeyF47, ModNone)
-		case "KeyF48":
-			m[v] = keyFromTypeMod(KeyF48, ModNone)
-		case "KeyF49":
-			m[v] = keyFromTypeMod(KeyF49, ModNone)
-		case "KeyF50":
-			m[v] = keyFromTypeMod(KeyF50, ModNone)
-		case "KeyF51":
-			m[v] = keyFromTypeMod(KeyF51, ModNone)
-		case "KeyF52":
-			m[v] = keyFromTypeMod(KeyF52, ModNone)
-		case "KeyF53":
-			m[v] = keyFromTypeMod(KeyF53, ModNone)
-		case "KeyF54":
-			m[v] = keyFromTypeMod(KeyF54, ModNone)
-		case "KeyF55":
-			m[v] = keyFromTypeMod(KeyF55, ModNone)
-		case "KeyF56":
-			m[v] = keyFromTypeMod(KeyF56, ModNone)
-		case "KeyF57":
-			m[v] = keyFromTypeMod(KeyF57, ModNone)
-		case "KeyF58":
-			m[v] = keyFromTypeMod(KeyF58, ModNone)
-		case "KeyF59":
-			m[v] = keyFromTypeMod(KeyF59, ModNone)
-		case "KeyF60":
-			m[v] = keyFromTypeMod(KeyF60, ModNone)
-		case "KeyF61":
-			m[v] = keyFromTypeMod(KeyF61, ModNone)
-		case "KeyF62":
-			m[v] = keyFromTypeMod(KeyF62, ModNone)
-		case "KeyF63":
-			m[v] = keyFromTypeMod(KeyF63, ModNone)
-		case "KeyF64":
-			m[v] = keyFromTypeMod(KeyF64, ModNone)
-		case "KeyInsert":
-			m[v] = keyFromTypeMod(KeyInsert, ModNone)
-		case "KeyDelete":
-			m[v] = keyFromTypeMod(KeyDelete, ModNone)
-		case "KeyHome":
-			m[v] = keyFromTypeMod(KeyHome, ModNone)
-		case "KeyEnd":
-			m[v] = keyFromTypeMod(KeyEnd, ModNone)
-		case "KeyHelp":
-			m[v] = keyFromTypeMod(KeyHelp, ModNone)
-		case "KeyPgUp":
-			m[v] = keyFromTypeMod(KeyPgUp, ModNone)
-		case "KeyPgDn":
-			m[v] = keyFromTypeMod(KeyPgDn, ModNone)
-		case "KeyUp":
-			m[v] = keyFromTypeMod(KeyUp, ModNone)
-		case "KeyDown":
-			m[v] = keyFromTypeMod(KeyDown, ModNone)
-		case "KeyLeft":
-			m[v] = keyFromTypeMod(KeyLeft, ModNone)
-		case "KeyRight":
-			m[v] = keyFromTypeMod(KeyRight, ModNone)
-		case "KeyBacktab":
-			m[v] = keyFromTypeMod(KeyBacktab, ModNone)
-		case "KeyExit":
-			m[v] = keyFromTypeMod(KeyExit, ModNone)
-		case "KeyClear":
-			m[v] = keyFromTypeMod(KeyClear, ModNone)
-		case "KeyPrint":
-			m[v] = keyFromTypeMod(KeyPrint, ModNone)
-		case "KeyCancel":
-			m[v] = keyFromTypeMod(KeyCancel, ModNone)
-		case "KeyShfRight":
-			m[v] = keyFromTypeMod(KeyRight, ModShift)
-		case "KeyShfLeft":
-			m[v] = keyFromTypeMod(KeyLeft, ModShift)
-		case "KeyShfHome":
-			m[v] = keyFromTypeMod(KeyHome, ModShift)
-		case "KeyShfEnd":
-			m[v] = keyFromTypeMod(KeyEnd, ModShift)
-		case "KeyShfUp":
-			m[v] = keyFromTypeMod(KeyUp, ModShift)
-		case "KeyShfDown":
-			m[v] = keyFromTypeMod(KeyDown, ModShift)
-		case "KeyShfPgUp":
-			m[v] = keyFromTypeMod(KeyPgUp, ModShift)
-		case "KeyShfPgDn":
-			m[v] = keyFromTypeMod(KeyPgDn, ModShift)
-		case "KeyCtrlUp":
-			m[v] = keyFromTypeMod(KeyUp, ModCtrl)
-		case "KeyCtrlDown":
-			m[v] = keyFromTypeMod(KeyDown, ModCtrl)
-		case "KeyCtrlRight":
-			m[v] = keyFromTypeMod(KeyRight, ModCtrl)
-		case "KeyCtrlLeft":
-			m[v] = keyFromTypeMod(KeyLeft, ModCtrl)
-		case "KeyMetaUp":
-			m[v] = keyFromTypeMod(KeyUp, ModMeta)
-		case "KeyMetaDown":
-			m[v] = keyFromTypeMod(KeyDown, ModMeta)
-		case "KeyMetaRight":
-			m[v] = keyFromTypeMod(KeyRight, ModMeta)
-		case "KeyMetaLeft":
-			m[v] = keyFromTypeMod(KeyLeft, ModMeta)
-		case "KeyAltUp":
-			m[v] = keyFromTypeMod(KeyUp, ModAlt)
-		case "KeyAltDown":
-			m[v] = keyFromTypeMod(KeyDown, ModAlt)
-		case "KeyAltRight":
-			m[v] = keyFromTypeMod(KeyRight, ModAlt)
-		case "KeyAltLeft":
-			m[v] = keyFromTypeMod(KeyLeft, ModAlt)
-		case "KeyCtrlHome":
-			m[v] = keyFromTypeMod(KeyHome, ModCtrl)
-		case "KeyCtrlEnd":
-			m[v] = keyFromTypeMod(KeyEnd, ModCtrl)
-		case "KeyMetaHome":
-			m[v] = keyFromTypeMod(KeyHome, ModMeta)
-		case "KeyMetaEnd":
-			m[v] = keyFromTypeMod(KeyEnd, ModMeta)
-		case "KeyAltHome":
-			m[v] = keyFromTypeMod(KeyHome, ModAlt)
-		case "KeyAltEnd":
-			m[v] = keyFromTypeMod(KeyEnd, ModAlt)
-		case "KeyAltShfUp":
-			m[v] = keyFromTypeMod(KeyUp, ModAlt|ModShift)
-		case "KeyAltShfDown":
-			m[v] = keyFromTypeMod(KeyDown, ModAlt|ModShift)
-		case "KeyAltShfLeft":
-			m[v] = keyFromTypeMod(KeyLeft, ModAlt|ModShift)
-		case "KeyAltShfRight":
-			m[v] = keyFromTypeMod(KeyRight, ModAlt|ModShift)
-		case "KeyMetaShfUp":
-			m[v] = keyFromTypeMod(KeyUp, ModMeta|ModShift)
-		case "KeyMetaShfDown":
-			m[v] = keyFromTypeMod(KeyDown, ModMeta|ModShift)
-		case "KeyMetaShfLeft":
-			m[v] = keyFromTypeMod(KeyLeft, ModMeta|ModShift)
-		case "KeyMetaShfRight":
-			m[v] = keyFromTypeMod(KeyRight, ModMeta|ModShift)
-		case "KeyCtrlShfUp":
-			m[v] = keyFromTypeMod(KeyUp, ModCtrl|ModShift)
-		case "KeyCtrlShfDown":
-			m[v] = keyFromTypeMod(KeyDown, ModCtrl|ModShift)
-		case "KeyCtrlShfLeft":
-			m[v] = keyFromTypeMod(KeyLeft, ModCtrl|ModShift)
-		case "KeyCtrlShfRight":
-			m[v] = keyFromTypeMod(KeyRight, ModCtrl|ModShift)
-		case "KeyCtrlShfHome":
-			m[v] = keyFromTypeMod(KeyHome, ModCtrl|ModShift)
-		case "KeyCtrlShfEnd":
-			m[v] = keyFromTypeMod(KeyEnd, ModCtrl|ModShift)
-		case "KeyAltShfHome":
-			m[v] = keyFromTypeMod(KeyHome, ModAlt|ModShift)
-		case "KeyAltShfEnd":
-			m[v] = keyFromTypeMod(KeyEnd, ModAlt|ModShift)
-		case "KeyMetaShfHome":
-			m[v] = keyFromTypeMod(KeyHome, ModMeta|ModShift)
-		case "KeyMetaShfEnd":
-			m[v] = keyFromTypeMod(KeyEnd, ModMeta|ModShift)
+		if key, ok := terminfoKeys[k]; ok {
+			m[v] = key
+		}
+	}
+	return m
+}
+
+// KeySequences returns the escape sequence that generates each special key
+// recognized by an Input created with the WithESCSeq(tinfo) option. As for
+// WithESCSeq, a nil tinfo map returns the sequences of the default mapping.
+// If more than one sequence maps to the same key, the shortest one is
+// returned (and if they are of equal length, the smallest in byte order).
+func KeySequences(tinfo map[string]string) map[Key]string {
+	esc := escFromTerminfo(tinfo)
+	m := make(map[Key]string, len(esc))
+	for seq, k := range esc {
+		if cur, ok := m[k]; ok {
+			if len(cur) < len(seq) || (len(cur) == len(seq) && cur < seq) {
+				continue
+			}
 		}
+		m[k] = seq
 	}
 	return m
 }
+
+// ESCSeqIssueKind is the kind of issue reported by ValidateESCSeq.
+type ESCSeqIssueKind int
+
+// List of issues reported by ValidateESCSeq.
+const (
+	// IssueNoESC is reported for a key sequence that does not start with
+	// ESC, which is ignored by WithESCSeq.
+	IssueNoESC ESCSeqIssueKind = iota + 1
+
+	// IssueUnknownKey is reported for a field starting with "Key" that is not
+	// a supported key, which is ignored by WithESCSeq.
+	IssueUnknownKey
+
+	// IssueDuplicate is reported when the same sequence is used for
+	// different keys, only one of them can be reported by the Input.
+	IssueDuplicate
+
+	// IssuePrefix is reported when a sequence is a prefix of another one,
+	// the shorter one shadows the longer one when its bytes are not read
+	// at once.
+	IssuePrefix
+)
+
+var issueKindNames = [...]string{
+	IssueNoESC:      "sequence does not start with ESC",
+	IssueUnknownKey: "unknown key",
+	IssueDuplicate:  "duplicate sequence",
+	IssuePrefix:     "sequence is a prefix of another",
+}
+
+// String returns the description of the issue kind.
+func (k ESCSeqIssueKind) String() string {
+	if k > 0 && int(k) < len(issueKindNames) {
+		return issueKindNames[k]
+	}
+	return "ESCSeqIssueKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// ESCSeqIssue describes an issue with an entry of a terminfo-like map, as
+// reported by ValidateESCSeq.
+type ESCSeqIssue struct {
+	Kind ESCSeqIssueKind
+	Name string // field name of the entry, e.g. "KeyUp"
+	Seq  string // key sequence of the entry
+
+	// Other is the field name of the other entry involved in the issue, for
+	// IssueDuplicate and IssuePrefix (for the latter, Name is the prefix of
+	// Other).
+	Other string
+}
+
+// Error returns the description of the issue, so that it can be used as
+// an error.
+func (i ESCSeqIssue) Error() string {
+	msg := fmt.Sprintf("%s (%s): %s", i.Name, DumpBytes([]byte(i.Seq)), i.Kind)
+	if i.Other != "" {
+		msg += " " + i.Other
+	}
+	return msg
+}
+
+// ValidateESCSeq validates the terminfo-like map tinfo as used by the
+// WithESCSeq option, and returns the issues found, sorted by field name.
+// It returns nil if there is no issue. Only the non-empty fields starting
+// with "Key" are considered, and a single control character (e.g. DEL for
+// KeyBackspace) is not an issue as it is decoded as a control key anyway.
+//
+// This is intended to detect mapping bugs at startup, e.g. when tinfo is
+// built or patched by hand, instead of as unexpected key behaviour.
+func ValidateESCSeq(tinfo map[string]string) []ESCSeqIssue {
+	names := make([]string, 0, len(tinfo))
+	for name, seq := range tinfo {
+		if strings.HasPrefix(name, "Key") && seq != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var issues []ESCSeqIssue
+	valid := names[:0:0]
+	for _, name := range names {
+		seq := tinfo[name]
+		if _, ok := terminfoKeys[name]; !ok {
+			issues = append(issues, ESCSeqIssue{Kind: IssueUnknownKey, Name: name, Seq: seq})
+			continue
+		}
+		if !strings.HasPrefix(seq, "\x1b") {
+			if len(seq) == 1 && (KeyType(seq[0]) <= KeyUS || KeyType(seq[0]) == KeyDEL) {
+				// decoded as a control key without the mapping
+				continue
+			}
+			issues = append(issues, ESCSeqIssue{Kind: IssueNoESC, Name: name, Seq: seq})
+			continue
+		}
+		valid = append(valid, name)
+	}
+
+	for i, name := range valid {
+		seq := tinfo[name]
+		for j, other := range valid {
+			if i == j {
+				continue
+			}
+			oseq := tinfo[other]
+			switch {
+			case seq == oseq:
+				// report duplicates only once, on the first name
+				if i < j && terminfoKeys[name] != terminfoKeys[other] {
+					issues = append(issues, ESCSeqIssue{Kind: IssueDuplicate, Name: name, Seq: seq, Other: other})
+				}
+			case strings.HasPrefix(oseq, seq):
+				issues = append(issues, ESCSeqIssue{Kind: IssuePrefix, Name: name, Seq: seq, Other: other})
+			}
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Name < issues[j].Name
+	})
+	return issues
+}
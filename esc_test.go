@@ -0,0 +1,60 @@
+package zzterm
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+)
+
+func TestValidateESCSeq(t *testing.T) {
+	tinfo := map[string]string{
+		"Name":         "test",
+		"KeyUp":        "\x1b[A",
+		"KeyDown":      "\x1b[B",
+		"KeyLeft":      "\x1b[A",
+		"KeyHome":      "\x1b[1",
+		"KeyEnd":       "\x1b[1~",
+		"KeyF1":        "OP",
+		"KeyFoo":       "\x1b[X",
+		"KeyBackspace": "\x7f",
+		"KeyInsert":    "",
+	}
+	want := []ESCSeqIssue{
+		{Kind: IssueNoESC, Name: "KeyF1", Seq: "OP"},
+		{Kind: IssueUnknownKey, Name: "KeyFoo", Seq: "\x1b[X"},
+		{Kind: IssuePrefix, Name: "KeyHome", Seq: "\x1b[1", Other: "KeyEnd"},
+		{Kind: IssueDuplicate, Name: "KeyLeft", Seq: "\x1b[A", Other: "KeyUp"},
+	}
+
+	got := ValidateESCSeq(tinfo)
+	if len(got) != len(want) {
+		t.Fatalf("want %d issues, got %d: %v", len(want), len(got), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%d: want %v, got %v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestValidateESCSeq_VT100(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/vt100.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]string
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+	if issues := ValidateESCSeq(m); issues != nil {
+		t.Errorf("want no issue, got %v", issues)
+	}
+}
+
+func TestESCSeqIssue_Error(t *testing.T) {
+	err := ESCSeqIssue{Kind: IssueDuplicate, Name: "KeyLeft", Seq: "\x1b[A", Other: "KeyUp"}
+	want := `KeyLeft (CSI A): duplicate sequence KeyUp`
+	if got := err.Error(); got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
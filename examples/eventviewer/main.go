@@ -0,0 +1,96 @@
+// Command eventviewer displays the keys, mouse, focus and paste events
+// decoded by zzterm as they are received from the terminal. It is useful to
+// check what a given terminal sends for a key combination, and as an
+// example of a complete zzterm program.
+//
+// The terminal is set in raw mode with the stty command, so it must be run
+// from an interactive terminal on a Unix-like system. Press Ctrl-C to quit.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"git.sr.ht/~mna/zzterm"
+)
+
+func main() {
+	noMouse := flag.Bool("no-mouse", false, "Do not enable mouse tracking.")
+	moves := flag.Bool("moves", false, "Report mouse moves without buttons pressed.")
+	flag.Parse()
+
+	if err := run(os.Stdin, os.Stdout, !*noMouse, *moves); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(in *os.File, out io.Writer, mouse, moves bool) error {
+	restore, err := makeRaw(in)
+	if err != nil {
+		return err
+	}
+	zzterm.RegisterCleanup(restore)
+	defer zzterm.Cleanup(out)
+
+	features := []zzterm.Feature{zzterm.Focus(), zzterm.BracketedPaste()}
+	opts := []zzterm.Option{zzterm.WithFocus(), zzterm.WithPaste()}
+	if mouse {
+		mt := zzterm.MouseButton
+		if moves {
+			mt = zzterm.MouseAny
+		}
+		features = append(features, zzterm.Mouse(mt))
+		opts = append(opts, zzterm.WithMouse())
+	}
+	if err := zzterm.EnableFeatures(out, features...); err != nil {
+		return err
+	}
+
+	fmt.Fprint(out, "Press keys, click or paste to see the events, Ctrl-C to quit.\r\n")
+	input := zzterm.NewInput(opts...)
+	for {
+		k, err := input.ReadKey(in)
+		if err != nil {
+			return err
+		}
+
+		b := input.Bytes()
+		switch k.Type() {
+		case zzterm.KeyCtrlC:
+			return nil
+		case zzterm.KeyMouse:
+			fmt.Fprintf(out, "%-12s %-24s %s\r\n", k, input.Mouse(), zzterm.DumpBytes(b))
+		case zzterm.KeyFocusIn, zzterm.KeyFocusOut:
+			fmt.Fprintf(out, "%-12s %-24s %s\r\n", k, "", zzterm.DumpBytes(b))
+		case zzterm.KeyPaste:
+			p := input.Paste()
+			fmt.Fprintf(out, "%-12s %-24s %s\r\n", k, fmt.Sprintf("%d bytes", len(p)), zzterm.DumpBytes(p))
+		default:
+			fmt.Fprintf(out, "%-12s %-24s %s\r\n", k, "", zzterm.DumpBytes(b))
+		}
+	}
+}
+
+// makeRaw sets the terminal f in raw mode without echo and returns the
+// function that restores its previous state.
+func makeRaw(f *os.File) (func(), error) {
+	state, err := stty(f, "-g")
+	if err != nil {
+		return nil, fmt.Errorf("eventviewer: stdin must be a terminal: %w", err)
+	}
+	if _, err := stty(f, "raw", "-echo"); err != nil {
+		return nil, err
+	}
+	return func() { stty(f, string(bytes.TrimSpace(state))) }, nil
+}
+
+func stty(f *os.File, args ...string) ([]byte, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = f
+	return cmd.Output()
+}
@@ -0,0 +1,200 @@
+package zzterm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is a key read by an Input with the information associated with it,
+// so that it remains valid after other keys are read.
+type Event struct {
+	Key   Key
+	Mouse MouseEvent // valid if Key is of type KeyMouse
+
+	// Bytes is a copy of the uninterpreted bytes of the key, as returned by
+	// Input.Bytes when the key was read. Unlike those of Input.Bytes, they
+	// remain valid after other keys are read, so that an application can
+	// forward the exact original bytes of the events returned by Expect and
+	// of those it queued.
+	Bytes []byte
+
+	osc   [2]int
+	tparm TermParams
+	da    DeviceAttributes
+	hl    HighlightEvent
+	hlOK  bool
+	paste []byte
+	pinfo PasteInfo
+	kkey  KittyKey
+	at    time.Time
+}
+
+// String returns a single-line representation of the event for structured
+// logging, as space-separated key=value fields in a stable order: the key
+// type, the modifier flags if any (e.g. mod=Ctrl|Shift), the quoted rune for
+// a KeyRune, the coordinates, button and held buttons for a KeyMouse, and
+// the length of the raw bytes, e.g.:
+//
+//	type=Mouse mod=Ctrl x=12 y=3 button=1 pressed=true buttons=1 len=9
+func (ev Event) String() string {
+	k := ev.Key
+	var sb strings.Builder
+	sb.WriteString("type=")
+	sb.WriteString(k.Type().String())
+	if m := k.Mod(); m != ModNone {
+		sb.WriteString(" mod=")
+		sb.WriteString(m.names())
+	}
+	switch k.Type() {
+	case KeyRune:
+		sb.WriteString(" rune=")
+		sb.WriteString(strconv.QuoteRune(k.Rune()))
+	case KeyMouse:
+		x, y := ev.Mouse.Coords()
+		sb.WriteString(" x=")
+		sb.WriteString(strconv.Itoa(x))
+		sb.WriteString(" y=")
+		sb.WriteString(strconv.Itoa(y))
+		sb.WriteString(" button=")
+		sb.WriteString(strconv.Itoa(ev.Mouse.ButtonID()))
+		sb.WriteString(" pressed=")
+		sb.WriteString(strconv.FormatBool(ev.Mouse.ButtonPressed()))
+		sb.WriteString(" buttons=")
+		sb.WriteString(strconv.Itoa(int(ev.Mouse.Buttons())))
+	}
+	sb.WriteString(" len=")
+	sb.WriteString(strconv.Itoa(len(ev.Bytes)))
+	return sb.String()
+}
+
+// returns the Event of the last key k read by i.
+func (i *Input) event(k Key) Event {
+	ev := Event{
+		Key:   k,
+		Mouse: i.lastm,
+		Bytes: append([]byte{}, i.Bytes()...),
+		osc:   i.osc,
+		tparm: i.tparm,
+		da:    i.da,
+		hl:    i.hl,
+		hlOK:  i.hlOK,
+		kkey:  i.kkey,
+		at:    i.keyAt,
+	}
+	if k.Type() == KeyPaste {
+		ev.paste = append([]byte{}, i.paste...)
+		ev.pinfo = i.pinfo
+	}
+	return ev
+}
+
+// returns the first event of the queue and makes it the last key read.
+func (i *Input) replay() Key {
+	ev := i.queue[0]
+	i.queue[0] = Event{}
+	i.queue = i.queue[1:]
+	if len(i.queue) == 0 {
+		i.queue = nil
+	}
+
+	i.rbuf = ev.Bytes
+	i.lastm = ev.Mouse
+	i.osc = ev.osc
+	i.tparm = ev.tparm
+	i.da = ev.da
+	i.hl, i.hlOK = ev.hl, ev.hlOK
+	i.paste = ev.paste
+	i.pinfo = ev.pinfo
+	i.kkey = ev.kkey
+	i.keyAt = ev.at
+	return ev.Key
+}
+
+// returns true if a key can be returned by ReadKey without reading from
+// the reader, either because it is queued or because bytes remain in the
+// buffer.
+func (i *Input) buffered() bool {
+	return len(i.queue) > 0 || i.len > i.sz
+}
+
+// puts back the event of a key read by ReadKey at the front of the queue,
+// so that it is returned by the next call.
+func (i *Input) unread(ev Event) {
+	i.queue = append(i.queue, Event{})
+	copy(i.queue[1:], i.queue)
+	i.queue[0] = ev
+}
+
+// Expect reads keys from r until one matches the match function, and
+// returns it. The keys that do not match are queued and returned, in
+// order, by the next calls to ReadKey (the keys already in the queue are
+// tested first). This is useful to wait for the reply to a request sent to
+// the terminal, e.g. a Device Attributes reply, without losing the keys
+// pressed by the user in the meantime:
+//
+//	io.WriteString(os.Stdout, "\x1b[c")
+//	ev, err := input.Expect(ctx, os.Stdin, func(ev zzterm.Event) bool {
+//		seq, n := zzterm.ParseCSI(ev.Bytes)
+//		return n > 0 && seq.Prefix == '?' && seq.Final == 'c'
+//	})
+//
+// The methods that return information on the last key (e.g. Bytes and
+// Mouse) correspond to the key returned by ReadKey, even if it was
+// queued. The rest of the sequence of a queued KeyESCSeqStream key is
+// discarded.
+//
+// It returns the error of ctx when it is done. As ReadKey, the reader must
+// have a read timeout for this to be detected while no key is available,
+// timeouts are not returned as errors. Any other error is returned.
+func (i *Input) Expect(ctx context.Context, r io.Reader, match func(Event) bool) (Event, error) {
+	for j, ev := range i.queue {
+		if match(ev) {
+			copy(i.queue[j:], i.queue[j+1:])
+			i.queue[len(i.queue)-1] = Event{}
+			i.queue = i.queue[:len(i.queue)-1]
+			return ev, nil
+		}
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return Event{}, err
+		}
+		k, err := i.read(r)
+		if err != nil {
+			if errors.Is(err, ErrTimeout) {
+				continue
+			}
+			return Event{}, err
+		}
+
+		ev := i.event(k)
+		if match(ev) {
+			return ev, nil
+		}
+		i.queue = append(i.queue, ev)
+	}
+}
+
+// Pending returns the keys queued by Expect that will be returned by the
+// next calls to ReadKey without reading from the reader, in order. The
+// kind of each event is given by the key's Type. It returns nil if no key
+// is queued. The bytes that were read but not yet decoded are not
+// included.
+//
+// This can be used e.g. by a renderer to skip intermediate frames when
+// more input is already available.
+func (i *Input) Pending() []Key {
+	if len(i.queue) == 0 {
+		return nil
+	}
+	keys := make([]Key, len(i.queue))
+	for j, ev := range i.queue {
+		keys[j] = ev.Key
+	}
+	return keys
+}
@@ -0,0 +1,176 @@
+package zzterm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func isDAReply(ev Event) bool {
+	seq, n := ParseCSI(ev.Bytes)
+	return n > 0 && seq.Prefix == '?' && seq.Final == 'c'
+}
+
+func TestInput_Expect(t *testing.T) {
+	input := NewInput(WithMouse(), WithOSC())
+	r := &scriptReader{chunks: []string{"a", "\x1b[<0;1;2M", "\x1b]9;hi\a", "", "\x1b[?1;2c", "b"}}
+
+	ev, err := input.Expect(context.Background(), r, isDAReply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(ev.Bytes); got != "\x1b[?1;2c" {
+		t.Fatalf("want DA reply, got %q", got)
+	}
+
+	k, err := input.ReadKey(r)
+	if err != nil || k != 'a' || string(input.Bytes()) != "a" {
+		t.Fatalf("want 'a', got %s (%q), %v", k, input.Bytes(), err)
+	}
+
+	k, err = input.ReadKey(r)
+	if err != nil || k.Type() != KeyMouse || string(input.Bytes()) != "\x1b[<0;1;2M" {
+		t.Fatalf("want mouse, got %s (%q), %v", k, input.Bytes(), err)
+	}
+	if m := input.Mouse(); m.ButtonID() != 1 || !m.ButtonPressed() {
+		t.Errorf("want button 1 pressed, got %d %t", m.ButtonID(), m.ButtonPressed())
+	}
+
+	k, err = input.ReadKey(r)
+	if err != nil || k.Type() != KeyOSC {
+		t.Fatalf("want OSC, got %s, %v", k, err)
+	}
+	if cmd, pl := input.OSC(); cmd != 9 || string(pl) != "hi" {
+		t.Errorf("want OSC 9 hi, got %d %q", cmd, pl)
+	}
+
+	// queue is empty, reads from r
+	k, err = input.ReadKey(r)
+	if err != nil || k != 'b' || string(input.Bytes()) != "b" {
+		t.Fatalf("want 'b', got %s (%q), %v", k, input.Bytes(), err)
+	}
+	if k, err := input.ReadKey(r); err != ErrTimeout {
+		t.Errorf("want ErrTimeout, got %s, %v", k, err)
+	}
+}
+
+func TestInput_Expect_Queued(t *testing.T) {
+	input := NewInput()
+	r := strings.NewReader("xyz")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	isZ := func(ev Event) bool {
+		if ev.Key == 'z' {
+			cancel()
+		}
+		return false
+	}
+	if _, err := input.Expect(ctx, r, isZ); !errors.Is(err, context.Canceled) {
+		t.Fatalf("want context.Canceled, got %v", err)
+	}
+
+	// match from the queue, without reading
+	ev, err := input.Expect(context.Background(), r, func(ev Event) bool { return ev.Key == 'y' })
+	if err != nil || ev.Key != 'y' {
+		t.Fatalf("want 'y', got %s, %v", ev.Key, err)
+	}
+
+	for _, want := range []Key{'x', 'z'} {
+		k, err := input.ReadKey(r)
+		if err != nil || k != want {
+			t.Fatalf("want %s, got %s, %v", want, k, err)
+		}
+	}
+	if k, err := input.ReadKey(r); err != ErrTimeout {
+		t.Errorf("want ErrTimeout, got %s, %v", k, err)
+	}
+}
+
+func TestEvent_Bytes(t *testing.T) {
+	input := NewInput(WithMouse())
+	want := []string{"a", "\x1b[<0;1;2M", "\x1b[1;5A", "b"}
+	r := &scriptReader{chunks: want}
+
+	var events []Event
+	if _, err := input.Expect(context.Background(), r, func(ev Event) bool {
+		events = append(events, ev)
+		return ev.Key == 'b'
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the bytes of the events remain valid after other keys are read
+	if len(events) != len(want) {
+		t.Fatalf("want %d events, got %d", len(want), len(events))
+	}
+	for j, ev := range events {
+		if got := string(ev.Bytes); got != want[j] {
+			t.Errorf("[%d]: want %q, got %q", j, want[j], got)
+		}
+	}
+
+	// and match those returned by Bytes when the queued keys are replayed
+	for j, w := range want[:3] {
+		if _, err := input.ReadKey(r); err != nil {
+			t.Fatal(err)
+		}
+		if got := string(input.Bytes()); got != w {
+			t.Errorf("[%d]: Bytes: want %q, got %q", j, w, got)
+		}
+	}
+}
+
+func TestInput_Pending(t *testing.T) {
+	input := NewInput(WithMouse())
+	r := &scriptReader{chunks: []string{"a", "\x1b[<0;1;2M", "\x1b[A", "b"}}
+
+	if got := input.Pending(); got != nil {
+		t.Fatalf("want no pending key, got %v", got)
+	}
+	if _, err := input.Expect(context.Background(), r, func(ev Event) bool { return ev.Key == 'b' }); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []KeyType{KeyRune, KeyMouse, KeyUp}
+	for len(want) > 0 {
+		got := input.Pending()
+		if len(got) != len(want) {
+			t.Fatalf("want %d pending keys, got %d", len(want), len(got))
+		}
+		for j, k := range got {
+			if k.Type() != want[j] {
+				t.Errorf("[%d]: want %s, got %s", j, want[j], k.Type())
+			}
+		}
+		if _, err := input.ReadKey(r); err != nil {
+			t.Fatal(err)
+		}
+		want = want[1:]
+	}
+	if got := input.Pending(); got != nil {
+		t.Errorf("want no pending key, got %v", got)
+	}
+}
+
+func TestEvent_String(t *testing.T) {
+	mk, m := NewMouseEvent(1, true, 12, 3, ModCtrl)
+	cases := []struct {
+		ev   Event
+		want string
+	}{
+		{Event{Key: 'a', Bytes: []byte("a")}, `type=Rune rune='a' len=1`},
+		{Event{Key: '平', Bytes: []byte("平")}, `type=Rune rune='平' len=3`},
+		{Event{Key: keyFromTypeMod(KeyUp, ModCtrl|ModShift), Bytes: []byte("\x1b[1;6A")}, `type=Up mod=Ctrl|Shift len=6`},
+		{Event{Key: keyFromTypeMod(KeyFocusIn, ModNone), Bytes: []byte("\x1b[I")}, `type=FocusIn len=3`},
+		{Event{Key: mk, Mouse: m, Bytes: []byte("\x1b[<16;12;3M")}, `type=Mouse mod=Ctrl x=12 y=3 button=1 pressed=true buttons=1 len=11`},
+	}
+	for _, c := range cases {
+		t.Run(c.want, func(t *testing.T) {
+			if got := c.ev.String(); got != c.want {
+				t.Errorf("want %s, got %s", c.want, got)
+			}
+		})
+	}
+}
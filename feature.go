@@ -0,0 +1,207 @@
+package zzterm
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// List of the raw control sequences that enable and disable terminal
+// features, as written by EnableMouse, EnableFocus and related functions.
+// They can be used to compose a single write with other output.
+const (
+	SeqEnableMouseButton     = "\x1b[?1000;1006h"
+	SeqDisableMouseButton    = "\x1b[?1000;1006l"
+	SeqEnableMouseAny        = "\x1b[?1003;1006h"
+	SeqDisableMouseAny       = "\x1b[?1003;1006l"
+	SeqEnableMouseDrag       = "\x1b[?1002;1006h"
+	SeqDisableMouseDrag      = "\x1b[?1002;1006l"
+	SeqEnableMouseHighlight  = "\x1b[?1001;1006h"
+	SeqDisableMouseHighlight = "\x1b[?1001;1006l"
+	SeqEnableFocus           = "\x1b[?1004h"
+	SeqDisableFocus          = "\x1b[?1004l"
+	SeqEnableBracketedPaste  = "\x1b[?2004h"
+	SeqDisableBracketedPaste = "\x1b[?2004l"
+	SeqShowCursor            = "\x1b[?25h"
+	SeqHideCursor            = "\x1b[?25l"
+	SeqEnableKittyKeys       = "\x1b[>1u" // push the KittyDisambiguate flag
+	SeqDisableKittyKeys      = "\x1b[<u"
+)
+
+// Feature identifies a terminal feature that can be enabled and disabled
+// with control sequences.
+type Feature int
+
+// List of supported features.
+const (
+	FeatureMouseButton     Feature = iota + 1 // mouse button events in SGR mode
+	FeatureMouseAny                           // any mouse events in SGR mode
+	FeatureFocus                              // focus in and out events
+	FeatureBracketedPaste                     // bracketed paste
+	FeatureHideCursor                         // invisible cursor
+	FeatureMouseDrag                          // mouse button and drag events in SGR mode
+	FeatureMouseHighlight                     // mouse highlight tracking in SGR mode
+	FeatureKittyKeys                          // kitty keyboard protocol, see WithKitty
+	FeatureModifyOtherKeys                    // xterm's modifyOtherKeys mode 2, see WithModifyOtherKeys
+)
+
+// FeaturePaste is an alias of FeatureBracketedPaste.
+const FeaturePaste = FeatureBracketedPaste
+
+var featureNames = [...]string{
+	FeatureMouseButton:     "MouseButton",
+	FeatureMouseAny:        "MouseAny",
+	FeatureFocus:           "Focus",
+	FeatureBracketedPaste:  "BracketedPaste",
+	FeatureHideCursor:      "HideCursor",
+	FeatureMouseDrag:       "MouseDrag",
+	FeatureMouseHighlight:  "MouseHighlight",
+	FeatureKittyKeys:       "KittyKeys",
+	FeatureModifyOtherKeys: "ModifyOtherKeys",
+}
+
+// String returns the name of the feature.
+func (f Feature) String() string {
+	if f > 0 && int(f) < len(featureNames) {
+		return featureNames[f]
+	}
+	return "Feature(" + strconv.Itoa(int(f)) + ")"
+}
+
+// FeatureSequences holds the control sequences that enable and disable a
+// feature.
+type FeatureSequences struct {
+	Enable  string
+	Disable string
+}
+
+var featureSeqs = [...]FeatureSequences{
+	FeatureMouseButton:     {SeqEnableMouseButton, SeqDisableMouseButton},
+	FeatureMouseAny:        {SeqEnableMouseAny, SeqDisableMouseAny},
+	FeatureFocus:           {SeqEnableFocus, SeqDisableFocus},
+	FeatureBracketedPaste:  {SeqEnableBracketedPaste, SeqDisableBracketedPaste},
+	FeatureHideCursor:      {SeqHideCursor, SeqShowCursor},
+	FeatureMouseDrag:       {SeqEnableMouseDrag, SeqDisableMouseDrag},
+	FeatureMouseHighlight:  {SeqEnableMouseHighlight, SeqDisableMouseHighlight},
+	FeatureKittyKeys:       {SeqEnableKittyKeys, SeqDisableKittyKeys},
+	FeatureModifyOtherKeys: {SeqEnableModifyOtherKeys, SeqDisableModifyOtherKeys},
+}
+
+// Sequences returns the control sequences of all supported features. The
+// returned map is a copy that can be modified by the caller.
+func Sequences() map[Feature]FeatureSequences {
+	m := make(map[Feature]FeatureSequences, len(featureSeqs)-1)
+	for f := FeatureMouseButton; int(f) < len(featureSeqs); f++ {
+		m[f] = featureSeqs[f]
+	}
+	return m
+}
+
+// Sequences returns the control sequences of the feature. It returns the
+// zero value if f is not a supported feature.
+func (f Feature) Sequences() FeatureSequences {
+	if f > 0 && int(f) < len(featureSeqs) {
+		return featureSeqs[f]
+	}
+	return FeatureSequences{}
+}
+
+// Mouse returns the Feature that enables the tracking of the mouse event
+// type t in SGR mode, for use with EnableFeatures. It returns 0 (not a
+// valid feature) if t is not a supported mouse event type.
+func Mouse(t MouseEventType) Feature {
+	return t.feature()
+}
+
+// Focus returns the Feature that enables the focus in and out events, for
+// use with EnableFeatures.
+func Focus() Feature {
+	return FeatureFocus
+}
+
+// BracketedPaste returns the Feature that enables bracketed paste, for use
+// with EnableFeatures.
+func BracketedPaste() Feature {
+	return FeatureBracketedPaste
+}
+
+// EnableFeatures enables all features on the terminal represented by w in a
+// single write, e.g.:
+//
+//	zzterm.EnableFeatures(w, zzterm.Mouse(zzterm.MouseAny), zzterm.Focus())
+//
+// This reduces the number of system calls and the flicker at startup,
+// especially over high-latency links. It returns an error without writing
+// anything if a feature is not supported.
+func EnableFeatures(w io.Writer, features ...Feature) error {
+	return writeFeatures(w, features, true)
+}
+
+// DisableFeatures disables all features on the terminal represented by w in
+// a single write, in the reverse order of the arguments so that the same
+// list as for EnableFeatures can be used. It returns an error without
+// writing anything if a feature is not supported.
+func DisableFeatures(w io.Writer, features ...Feature) error {
+	return writeFeatures(w, features, false)
+}
+
+func writeFeatures(w io.Writer, features []Feature, enable bool) error {
+	var n int
+	for _, f := range features {
+		seqs := f.Sequences()
+		if seqs.Enable == "" {
+			return fmt.Errorf("zzterm: unsupported feature: %s", f)
+		}
+		n += len(seqs.Enable) + len(seqs.Disable)
+	}
+
+	b := make([]byte, 0, n)
+	for i := range features {
+		if enable {
+			b = append(b, features[i].Sequences().Enable...)
+		} else {
+			b = append(b, features[len(features)-1-i].Sequences().Disable...)
+		}
+	}
+	if len(b) == 0 {
+		return nil
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+// Supports returns true if this version of the package supports the
+// feature f, that is, if it can decode the input events that the terminal
+// sends when the feature is enabled. Features that do not produce input
+// events, such as FeatureHideCursor, are always supported. As the values of
+// the features are stable, this lets a library that embeds zzterm check
+// for the features added by newer versions (e.g. a Feature value received
+// from its caller). See Input.Supports for the features that an Input is
+// configured to decode.
+func Supports(f Feature) bool {
+	return f > 0 && int(f) < len(featureSeqs)
+}
+
+// Supports returns true if the Input is configured to decode the input
+// events of the feature f, e.g. mouse features require the WithMouse
+// option, FeatureFocus requires the WithFocus option, FeatureBracketedPaste
+// requires the WithPaste option, FeatureKittyKeys requires the WithKitty
+// option and FeatureModifyOtherKeys requires the WithModifyOtherKeys
+// option (all of them may be set by a Profile). It reflects the runtime
+// changes, e.g. by SetMouseEnabled. It returns false if the package does
+// not support the feature (see the package-level Supports function).
+func (i *Input) Supports(f Feature) bool {
+	switch f {
+	case FeatureMouseButton, FeatureMouseAny, FeatureMouseDrag, FeatureMouseHighlight:
+		return i.mouse
+	case FeatureFocus:
+		return i.focus
+	case FeatureBracketedPaste:
+		return i.pasteOn
+	case FeatureKittyKeys:
+		return i.kittyOn
+	case FeatureModifyOtherKeys:
+		return i.modKeys
+	}
+	return Supports(f)
+}
@@ -0,0 +1,137 @@
+package zzterm
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSequences(t *testing.T) {
+	m := Sequences()
+	if len(m) != len(featureNames)-1 {
+		t.Errorf("want %d features, got %d", len(featureNames)-1, len(m))
+	}
+	for f, seqs := range m {
+		if seqs != f.Sequences() || seqs.Enable == "" || seqs.Disable == "" {
+			t.Errorf("%s: invalid sequences %q", f, seqs)
+		}
+	}
+
+	// modifying the returned map does not change the registry
+	m[FeatureFocus] = FeatureSequences{}
+	if FeatureFocus.Sequences().Enable != SeqEnableFocus {
+		t.Errorf("want %q, got %q", SeqEnableFocus, FeatureFocus.Sequences().Enable)
+	}
+	if s := Feature(0).Sequences(); s != (FeatureSequences{}) {
+		t.Errorf("want zero value, got %q", s)
+	}
+}
+
+func TestEnableMouse(t *testing.T) {
+	cases := []struct {
+		typ     MouseEventType
+		enable  string
+		disable string
+	}{
+		{MouseButton, SeqEnableMouseButton, SeqDisableMouseButton},
+		{MouseAny, SeqEnableMouseAny, SeqDisableMouseAny},
+		{MouseHighlight, SeqEnableMouseHighlight, SeqDisableMouseHighlight},
+		{MouseDrag, SeqEnableMouseDrag, SeqDisableMouseDrag},
+		{5, "\x1b[?1004;1006h", "\x1b[?1004;1006l"},
+	}
+	for _, c := range cases {
+		var buf bytes.Buffer
+		if err := EnableMouse(&buf, c.typ); err != nil {
+			t.Fatal(err)
+		}
+		if err := DisableMouse(&buf, c.typ); err != nil {
+			t.Fatal(err)
+		}
+		if want := c.enable + c.disable; buf.String() != want {
+			t.Errorf("%d: want %q, got %q", c.typ, want, buf.String())
+		}
+	}
+}
+
+type countWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countWriter) Write(b []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(b)
+}
+
+func TestEnableFeatures(t *testing.T) {
+	var w countWriter
+	features := []Feature{Mouse(MouseAny), Focus(), BracketedPaste()}
+	if err := EnableFeatures(&w, features...); err != nil {
+		t.Fatal(err)
+	}
+	if want := SeqEnableMouseAny + SeqEnableFocus + SeqEnableBracketedPaste; w.String() != want || w.writes != 1 {
+		t.Errorf("want %q in 1 write, got %q in %d", want, w.String(), w.writes)
+	}
+
+	w.Reset()
+	w.writes = 0
+	if err := DisableFeatures(&w, features...); err != nil {
+		t.Fatal(err)
+	}
+	if want := SeqDisableBracketedPaste + SeqDisableFocus + SeqDisableMouseAny; w.String() != want || w.writes != 1 {
+		t.Errorf("want %q in 1 write, got %q in %d", want, w.String(), w.writes)
+	}
+
+	w.Reset()
+	w.writes = 0
+	if err := EnableFeatures(&w, Focus(), Mouse(5)); err == nil {
+		t.Error("want error for unsupported feature")
+	}
+	if err := EnableFeatures(&w); err != nil {
+		t.Fatal(err)
+	}
+	if w.writes != 0 {
+		t.Errorf("want no write, got %d", w.writes)
+	}
+}
+
+func TestSupports(t *testing.T) {
+	all := NewInput(WithMouse(), WithFocus(), WithPaste(), WithKitty(), WithModifyOtherKeys())
+	none := NewInput()
+
+	cases := []struct {
+		f              Feature
+		pkg, all, none bool
+	}{
+		{FeatureMouseButton, true, true, false},
+		{FeatureMouseAny, true, true, false},
+		{FeatureMouseDrag, true, true, false},
+		{FeatureMouseHighlight, true, true, false},
+		{FeatureFocus, true, true, false},
+		{FeatureBracketedPaste, true, true, false},
+		{FeaturePaste, true, true, false},
+		{FeatureKittyKeys, true, true, false},
+		{FeatureModifyOtherKeys, true, true, false},
+		{FeatureHideCursor, true, true, true},
+		{0, false, false, false},
+		{Feature(999), false, false, false},
+	}
+	for _, c := range cases {
+		t.Run(c.f.String(), func(t *testing.T) {
+			if got := Supports(c.f); got != c.pkg {
+				t.Errorf("package: want %t, got %t", c.pkg, got)
+			}
+			if got := all.Supports(c.f); got != c.all {
+				t.Errorf("all: want %t, got %t", c.all, got)
+			}
+			if got := none.Supports(c.f); got != c.none {
+				t.Errorf("none: want %t, got %t", c.none, got)
+			}
+		})
+	}
+
+	// the profiles set the keyboard protocols of their terminal
+	wez := NewInput(WithProfile(ProfileWezTerm))
+	if !wez.Supports(FeatureKittyKeys) || !wez.Supports(FeatureModifyOtherKeys) {
+		t.Errorf("want kitty keys and modifyOtherKeys for %s", ProfileWezTerm)
+	}
+}
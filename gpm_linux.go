@@ -0,0 +1,191 @@
+package zzterm
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"unsafe"
+)
+
+// GPMSocket is the default path of the GPM server's control socket.
+const GPMSocket = "/dev/gpmctl"
+
+// GPM is a client of the General Purpose Mouse (GPM) server, which provides
+// mouse support on the Linux virtual console where the xterm mouse
+// tracking sequences are not available. It reads the mouse events from the
+// GPM server and returns them as the same Key and MouseEvent values as those
+// decoded by Input, so that applications can support the mouse on the
+// console transparently.
+//
+// GPM is only available on Linux.
+type GPM struct {
+	conn net.Conn
+	buf  [gpmEventSize]byte
+	held uint16 // bitmask of mouse buttons held
+}
+
+// DialGPM connects to the GPM server listening on the socket at path (the
+// default is GPMSocket if path is empty) and requests all mouse events for
+// the virtual console vc - e.g. 2 for /dev/tty2. The application must be
+// running on that virtual console for the GPM server to send events.
+func DialGPM(path string, vc int) (*GPM, error) {
+	if path == "" {
+		path = GPMSocket
+	}
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Gpm_Connect struct, see gpm.h
+	var req [16]byte
+	nativeEndian.PutUint16(req[0:], 0xffff)           // eventMask: all events
+	nativeEndian.PutUint16(req[2:], ^uint16(gpmHard)) // defaultMask: do not draw the pointer
+	nativeEndian.PutUint16(req[4:], 0)                // minMod
+	nativeEndian.PutUint16(req[6:], 0xffff)           // maxMod
+	nativeEndian.PutUint32(req[8:], uint32(os.Getpid()))
+	nativeEndian.PutUint32(req[12:], uint32(vc))
+	if _, err := conn.Write(req[:]); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &GPM{conn: conn}, nil
+}
+
+// Close closes the connection to the GPM server.
+func (g *GPM) Close() error {
+	return g.conn.Close()
+}
+
+// ReadMouse reads the next mouse event from the GPM server. It returns a
+// Key of type KeyMouse with the modifier flags set, and the corresponding
+// MouseEvent. Events that cannot be represented as a MouseEvent (e.g.
+// the pointer entering or leaving a region) are skipped.
+func (g *GPM) ReadMouse() (Key, MouseEvent, error) {
+	for {
+		if _, err := io.ReadFull(g.conn, g.buf[:]); err != nil {
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				err = io.EOF
+			}
+			return 0, MouseEvent{}, err
+		}
+		if k, m, ok := decodeGPMEvent(g.buf[:]); ok {
+			m.updateHeld(&g.held)
+			return k, m, nil
+		}
+	}
+}
+
+// size of the Gpm_Event struct, see gpm.h
+const gpmEventSize = 28
+
+// Gpm_Etype flags
+const (
+	gpmMove = 1 << iota
+	gpmDrag
+	gpmDown
+	gpmUp
+	_ // single
+	_ // double
+	_ // triple
+	_ // mflag
+	gpmHard
+)
+
+// Gpm_Event buttons
+const (
+	gpmBRight  = 1
+	gpmBMiddle = 2
+	gpmBLeft   = 4
+	gpmBFourth = 8
+	gpmBUp     = 16
+	gpmBDown   = 32
+)
+
+// Gpm_Event modifiers (from the kernel's keyboard state)
+const (
+	gpmModShift = 1
+	gpmModCtrl  = 4
+	gpmModAlt   = 8
+)
+
+// decodes a Gpm_Event struct into a KeyMouse Key and its MouseEvent, returns
+// false if the event cannot be represented.
+func decodeGPMEvent(b []byte) (Key, MouseEvent, bool) {
+	var (
+		buttons = b[0]
+		mods    = b[1]
+		x       = int16(nativeEndian.Uint16(b[8:]))
+		y       = int16(nativeEndian.Uint16(b[10:]))
+		typ     = nativeEndian.Uint32(b[12:])
+		wdy     = int16(nativeEndian.Uint16(b[26:]))
+	)
+
+	var mod Mod
+	if mods&gpmModShift != 0 {
+		mod |= ModShift
+	}
+	if mods&gpmModCtrl != 0 {
+		mod |= ModCtrl
+	}
+	if mods&gpmModAlt != 0 {
+		mod |= ModMeta
+	}
+
+	var m MouseEvent
+	if x > 0 {
+		m.x = int32(x)
+	}
+	if y > 0 {
+		m.y = int32(y)
+	}
+
+	switch {
+	case wdy > 0 || buttons&gpmBUp != 0:
+		// wheel events are reported as a button press, as for xterm
+		m.buttonID, m.pressed = 4, true
+	case wdy < 0 || buttons&gpmBDown != 0:
+		m.buttonID, m.pressed = 5, true
+	case typ&(gpmDown|gpmUp) != 0:
+		m.buttonID = gpmButtonID(buttons)
+		m.pressed = typ&gpmDown != 0
+		if m.buttonID == 0 {
+			return 0, MouseEvent{}, false
+		}
+	case typ&gpmDrag != 0:
+		m.buttonID, m.pressed, m.motion = gpmButtonID(buttons), true, true
+	case typ&gpmMove != 0:
+		// mouse move without button pressed
+		m.pressed, m.motion = true, true
+	default:
+		return 0, MouseEvent{}, false
+	}
+	return keyFromTypeMod(KeyMouse, mod), m, true
+}
+
+// returns the button ID of the lowest button set in the GPM buttons, using
+// the same IDs as the xterm mouse protocol.
+func gpmButtonID(buttons byte) byte {
+	switch {
+	case buttons&gpmBLeft != 0:
+		return 1
+	case buttons&gpmBMiddle != 0:
+		return 2
+	case buttons&gpmBRight != 0:
+		return 3
+	case buttons&gpmBFourth != 0:
+		return 8
+	}
+	return 0
+}
+
+// the GPM protocol uses the native byte order of the structs.
+var nativeEndian = func() binary.ByteOrder {
+	v := uint16(1)
+	if *(*byte)(unsafe.Pointer(&v)) == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}()
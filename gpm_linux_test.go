@@ -0,0 +1,63 @@
+package zzterm
+
+import "testing"
+
+func TestDecodeGPMEvent(t *testing.T) {
+	gpmEvent := func(buttons, mods byte, x, y int16, typ uint32, wdy int16) []byte {
+		b := make([]byte, gpmEventSize)
+		b[0], b[1] = buttons, mods
+		nativeEndian.PutUint16(b[8:], uint16(x))
+		nativeEndian.PutUint16(b[10:], uint16(y))
+		nativeEndian.PutUint32(b[12:], typ)
+		nativeEndian.PutUint16(b[26:], uint16(wdy))
+		return b
+	}
+
+	cases := []struct {
+		desc string
+		in   []byte
+		ok   bool
+		m    Mod
+		btn  int
+		prs  bool
+		mot  bool
+		x, y int
+	}{
+		{"left down", gpmEvent(gpmBLeft, 0, 3, 4, gpmDown|16, 0), true, ModNone, 1, true, false, 3, 4},
+		{"right up", gpmEvent(gpmBRight, gpmModShift, 10, 20, gpmUp, 0), true, ModShift, 3, false, false, 10, 20},
+		{"middle drag", gpmEvent(gpmBMiddle, gpmModCtrl|gpmModAlt, 1, 1, gpmDrag, 0), true, ModCtrl | ModMeta, 2, true, true, 1, 1},
+		{"left drag", gpmEvent(gpmBLeft, 0, 7, 8, gpmDrag|gpmMove, 0), true, ModNone, 1, true, true, 7, 8},
+		{"move", gpmEvent(0, 0, 80, 25, gpmMove, 0), true, ModNone, 0, true, true, 80, 25},
+		{"wheel up", gpmEvent(0, 0, 5, 5, gpmMove, 1), true, ModNone, 4, true, false, 5, 5},
+		{"wheel down", gpmEvent(0, 0, 5, 5, gpmMove, -1), true, ModNone, 5, true, false, 5, 5},
+		{"no button up", gpmEvent(0, 0, 5, 5, gpmUp, 0), false, ModNone, 0, false, false, 0, 0},
+		{"enter", gpmEvent(0, 0, 5, 5, 512, 0), false, ModNone, 0, false, false, 0, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			k, m, ok := decodeGPMEvent(c.in)
+			if ok != c.ok {
+				t.Fatalf("want ok %t, got %t", c.ok, ok)
+			}
+			if !ok {
+				return
+			}
+			if k.Type() != KeyMouse || k.Mod() != c.m {
+				t.Fatalf("want mouse key with modifier flags %04b, got %s", c.m, k)
+			}
+			if m.ButtonID() != c.btn || m.ButtonPressed() != c.prs {
+				t.Errorf("want button %d pressed %t, got %d %t", c.btn, c.prs, m.ButtonID(), m.ButtonPressed())
+			}
+			// the motion flag is preserved by the event encoding
+			if got := mouseButtonByte(m)&0x20 != 0; got != c.mot {
+				t.Errorf("want motion %t, got %t", c.mot, got)
+			}
+			if m.Dragging() != (c.mot && c.btn > 0) {
+				t.Errorf("want dragging %t, got %t", c.mot && c.btn > 0, m.Dragging())
+			}
+			if x, y := m.Coords(); x != c.x || y != c.y {
+				t.Errorf("want %d, %d, got %d, %d", c.x, c.y, x, y)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package zzterm
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// HighlightEvent is the end of a highlight tracking, reported by the
+// terminal when the button 1 is released while the MouseHighlight event
+// type is enabled and the application started the tracking with
+// StartHighlight. Coordinates start at 1,1 for the upper left character
+// position.
+type HighlightEvent struct {
+	// X and Y are the position of the mouse when the button was released.
+	X, Y int
+
+	// Selected is true if text was selected, in which case the region of
+	// the selection is set in StartX, StartY, EndX and EndY.
+	Selected       bool
+	StartX, StartY int
+	EndX, EndY     int
+}
+
+// StartHighlight writes the reply of the application to a button 1 press
+// reported while the MouseHighlight event type is enabled, to start the
+// highlight tracking by the terminal at x, y (the position of the press)
+// within the rows firstRow to lastRow (lastRow is exclusive). The terminal
+// does not report any other mouse event until it receives that reply or the
+// one written by AbortHighlight. When the button is released, the terminal
+// reports the end of the tracking, decoded as a KeyMouse key for which
+// Input.Highlight returns the details.
+func StartHighlight(w io.Writer, x, y, firstRow, lastRow int) error {
+	_, err := fmt.Fprintf(w, "\x1b[1;%d;%d;%d;%dT", x, y, firstRow, lastRow)
+	return err
+}
+
+// AbortHighlight writes the reply of the application to a button 1 press
+// reported while the MouseHighlight event type is enabled, to tell the
+// terminal not to start the highlight tracking.
+func AbortHighlight(w io.Writer) error {
+	_, err := io.WriteString(w, "\x1b[0;0;0;0;0T")
+	return err
+}
+
+// Highlight returns the details of the end of a highlight tracking, and true
+// if the last key of type KeyMouse is such an event. It should be called
+// only after a key of type KeyMouse has been received from ReadKey, and
+// before any other call to ReadKey.
+func (i *Input) Highlight() (HighlightEvent, bool) {
+	return i.hl, i.hlOK
+}
+
+// prefixes of the highlight tracking reports, followed by the coordinates
+// encoded as bytes (value + 32): "CSI t CxCy" if no text is selected, and
+// "CSI T CxCy StartxStarty EndxEndy" otherwise.
+var (
+	highlightPrefix    = []byte("\x1b[t")
+	highlightSelPrefix = []byte("\x1b[T")
+)
+
+// returns either a KeyMouse key, or a KeyESCSeq if the buffer does not
+// start with a highlight tracking report. If it returns a KeyMouse key,
+// i.sz is set to the length of the report, the mouse event is the release
+// of the button 1 at the end position and i.hl is set to the report.
+func (i *Input) decodeHighlight() Key {
+	b := i.buf[:i.len]
+	n := 0
+	switch {
+	case bytes.HasPrefix(b, highlightPrefix):
+		n = len(highlightPrefix) + 2
+	case bytes.HasPrefix(b, highlightSelPrefix):
+		n = len(highlightSelPrefix) + 6
+	}
+	if n == 0 || len(b) < n {
+		return keyFromTypeMod(KeyESCSeq, ModNone)
+	}
+
+	var xy [6]int
+	for j, c := range b[3:n] {
+		if c <= 32 {
+			return keyFromTypeMod(KeyESCSeq, ModNone)
+		}
+		xy[j] = int(c) - 32
+	}
+	hl := HighlightEvent{X: xy[0], Y: xy[1]}
+	if b[2] == 'T' {
+		hl.Selected = true
+		hl.StartX, hl.StartY, hl.EndX, hl.EndY = xy[2], xy[3], xy[4], xy[5]
+	}
+
+	i.hl, i.hlOK = hl, true
+	i.lastm = MouseEvent{buttonID: 1}
+	i.lastm.setCoords(hl.X, hl.Y, defaultCoordLimit)
+	i.lastm.updateHeld(&i.held)
+	i.sz = n
+	return keyFromTypeMod(KeyMouse, ModNone)
+}
@@ -0,0 +1,63 @@
+package zzterm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestStartHighlight(t *testing.T) {
+	var buf bytes.Buffer
+	if err := StartHighlight(&buf, 10, 2, 1, 25); err != nil {
+		t.Fatal(err)
+	}
+	if err := AbortHighlight(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\x1b[1;10;2;1;25T\x1b[0;0;0;0;0T"; buf.String() != want {
+		t.Errorf("want %q, got %q", want, buf.String())
+	}
+}
+
+func TestInput_ReadKey_Highlight(t *testing.T) {
+	cases := []struct {
+		in  string
+		typ KeyType
+		hl  HighlightEvent
+		ok  bool
+	}{
+		{"\x1b[t*\"", KeyMouse, HighlightEvent{X: 10, Y: 2}, true},
+		{"\x1b[T*\"!\"+#", KeyMouse, HighlightEvent{X: 10, Y: 2, Selected: true, StartX: 1, StartY: 2, EndX: 11, EndY: 3}, true},
+		{"\x1b[<0;1;1M", KeyMouse, HighlightEvent{}, false},
+		{"\x1b[t*", KeyESCSeq, HighlightEvent{}, false},
+		{"\x1b[t \"", KeyESCSeq, HighlightEvent{}, false},
+	}
+
+	input := NewInput(WithMouse())
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			k, err := input.ReadKey(strings.NewReader(c.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if k.Type() != c.typ {
+				t.Fatalf("want %s, got %s", c.typ, k)
+			}
+			hl, ok := input.Highlight()
+			if ok != c.ok || hl != c.hl {
+				t.Errorf("want %+v %t, got %+v %t", c.hl, c.ok, hl, ok)
+			}
+			if ok {
+				m := input.Mouse()
+				if x, y := m.Coords(); m.ButtonID() != 1 || m.ButtonPressed() || x != c.hl.X || y != c.hl.Y {
+					t.Errorf("want button 1 released at %d,%d, got %s", c.hl.X, c.hl.Y, m)
+				}
+			}
+		})
+	}
+
+	input = NewInput()
+	if k, _ := input.ReadKey(strings.NewReader("\x1b[t*\"")); k.Type() != KeyESCSeq {
+		t.Errorf("want KeyESCSeq without mouse, got %s", k)
+	}
+}
@@ -2,9 +2,12 @@ package zzterm
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"time"
 	"unicode/utf8"
 )
 
@@ -26,31 +29,127 @@ const ErrTimeout = timeoutError("zzterm: timetout")
 
 // Input reads input keys from a reader and returns the key pressed.
 type Input struct {
-	buf   []byte
-	sz    int // size of the last key
-	len   int // len of bytes loaded in the buffer
-	lastm MouseEvent
+	buf    []byte
+	sz     int // size of the last key
+	len    int // len of bytes loaded in the buffer
+	lastm  MouseEvent
+	held   uint16       // bitmask of mouse buttons held
+	lastxy [2]int32     // position of the last mouse event reported
+	osc    [2]int       // start and end of the OSC payload in buf, if last key is KeyOSC
+	tparm  TermParams   // terminal parameters, if last key is KeyTermParams
+	paste  []byte       // pasted text, if last key is KeyPaste
+	praw   []byte       // raw bytes of the last paste, if read in multiple reads
+	pmore  bool         // a paste is in progress, its next part is read by ReadKey
+	pinfo  PasteInfo    // metadata of the paste, if last key is KeyPaste
+	pstart time.Time    // time when the start delimiter of the paste was read
+	kkey   KittyKey     // kitty key details, if last key is from the kitty protocol
+	tokr   bytes.Reader // reader of the token passed to Decode
+	stream *seqStream   // stream of the last KeyESCSeqStream, if not fully read
+	queue  []Event      // events queued by Expect, returned before reading more
+	rbuf   []byte       // bytes of the last key, if replayed or a paste in praw
+	susp   []Feature    // features disabled by Suspend, to enable on Resume
+	stats  Stats
 
-	// immutable after NewInput
-	esc   map[string]Key
-	mouse bool
-	focus bool // only required to add the focus-related escape sequences in esc map
+	// timestamps, if stamps is set
+	bufAt  time.Time     // arrival time of the first byte in buf
+	lastAt time.Time     // return time of the last read
+	keyAt  time.Time     // arrival time of the first byte of the last key
+	keyLat time.Duration // latency of the last key
+
+	// immutable after NewInput (except esc, see AddESCSeq, and mouse,
+	// pasteRq and pasteOn, see SetMouseEnabled and SetPasteEnabled)
+	esc     map[string]Key
+	mouse   bool
+	focus   bool // only required to add the focus-related escape sequences in esc map
+	rlMeta  bool // only required to add the readline Meta escape sequences in esc map
+	oscOn   bool
+	dropped func(b, context []byte, err error)
+	resyncp ResyncPolicy
+	dropNUL bool
+	dropDEL bool
+	escPass bool
+	pasteRq bool
+	pasteOn bool
+	pasteCC bool // remove the control characters of the pasted text
+	kittyOn bool
+	normKey bool // normalize the runes of the kitty keys for the layouts
+	modKeys bool
+	retry   RetryPolicy
+	mirror  io.Writer
+	moveMin int // minimum distance in cells of mouse move events
+	natural bool
+	ctrlMod bool
+	altPre  bool
+	maxXY   int // limit of the mouse coordinates
+	legacy  int // bit set of the legacy MouseEncoding enabled
+	tparmOn bool
+	daOn    bool
+	stamps  bool
+
+	csiHandler func(seq *CSISeq, b []byte) (Key, bool)
+	noReplies  bool
+	eofClosed  bool
+	unkTypes   bool
+	strictCSI  bool
+	strict     bool
+	maxPaste   int // maximum size of a paste, 0 for the default
+	phaseHook  func(p Phase, end bool)
+	twoKey     *twoKeyEscape
+	longPress  *longPress
+	clock      Clock
+	dec        Decoder // nil for UTF-8
+	audit      func(AuditEvent)
+	auditFull  bool
+	seqDelay   time.Duration                   // delay to wait for the rest of an escape sequence
+	escDelay   time.Duration                   // delay to wait for a sequence after a lone ESC
+	byteDelay  time.Duration                   // delay to wait for a byte after an empty read
+	maxLatency time.Duration                   // maximum total delay to wait for a sequence
+	labels     *[numPhases + 1]context.Context // labels[0] is the base context
+	da         DeviceAttributes                // device attributes, if last key is KeyDeviceAttrs
+	hl         HighlightEvent                  // end of highlight tracking, if hlOK
+	hlOK       bool
 }
 
 // MouseEventType represents a type of mouse events.
 type MouseEventType int
 
 // List of supported mouse event types.
+//
+// The highlight tracking mode (CSI ? 1001 h) requires the application to
+// reply to each press of the button 1 with StartHighlight or AbortHighlight,
+// as the terminal stops reporting mouse events until it gets that reply.
+// The end of the highlight tracking is reported when the button is
+// released, see Input.Highlight.
 const (
-	MouseButton MouseEventType = iota + 1 // CSI ? 1000 h
-	_                                     // unsupported but reserved, CSI ? 1001 h
-	_                                     // unsupported but reserved, CSI ? 1002 h
-	MouseAny                              // CSI ? 1003 h
+	MouseButton    MouseEventType = iota + 1 // CSI ? 1000 h
+	MouseHighlight                           // CSI ? 1001 h
+	MouseDrag                                // CSI ? 1002 h
+	MouseAny                                 // CSI ? 1003 h
 )
 
+// returns the Feature corresponding to the mouse event type, or 0 if there
+// is none.
+func (t MouseEventType) feature() Feature {
+	switch t {
+	case MouseButton:
+		return FeatureMouseButton
+	case MouseHighlight:
+		return FeatureMouseHighlight
+	case MouseDrag:
+		return FeatureMouseDrag
+	case MouseAny:
+		return FeatureMouseAny
+	}
+	return 0
+}
+
 // EnableMouse sends the Control Sequence Introducer (CSI) function to
 // w to enable tracking of the specified mouse event type in SGR mode.
 func EnableMouse(w io.Writer, eventType MouseEventType) error {
+	if f := eventType.feature(); f > 0 {
+		_, err := io.WriteString(w, f.Sequences().Enable)
+		return err
+	}
 	code := eventType + 1000 - 1
 	_, err := fmt.Fprintf(w, "\x1b[?%d;1006h", code)
 	return err
@@ -60,6 +159,10 @@ func EnableMouse(w io.Writer, eventType MouseEventType) error {
 // w to disable tracking of the specified mouse event type and to disable
 // SGR mode.
 func DisableMouse(w io.Writer, eventType MouseEventType) error {
+	if f := eventType.feature(); f > 0 {
+		_, err := io.WriteString(w, f.Sequences().Disable)
+		return err
+	}
 	code := eventType + 1000 - 1
 	_, err := fmt.Fprintf(w, "\x1b[?%d;1006l", code)
 	return err
@@ -68,14 +171,14 @@ func DisableMouse(w io.Writer, eventType MouseEventType) error {
 // EnableFocus sends the Control Sequence Introducer (CSI) function to
 // w to enable sending focus escape sequences.
 func EnableFocus(w io.Writer) error {
-	_, err := fmt.Fprint(w, "\x1b[?1004h")
+	_, err := io.WriteString(w, SeqEnableFocus)
 	return err
 }
 
 // DisableFocus sends the Control Sequence Introducer (CSI) function to
 // w to disable sending focus escape sequences.
 func DisableFocus(w io.Writer) error {
-	_, err := fmt.Fprint(w, "\x1b[?1004l")
+	_, err := io.WriteString(w, SeqDisableFocus)
 	return err
 }
 
@@ -90,7 +193,8 @@ func DisableFocus(w io.Writer) error {
 // terminal represented by an io.Writer.
 //
 // Only X11 xterm mouse protocol in SGR mouse mode is supported. This should
-// be widely supported by any recent terminal with mouse support.  See
+// be widely supported by any recent terminal with mouse support, see
+// WithMouseEncodings for the legacy encodings. See
 // https://invisible-island.net/xterm/ctlseqs/ctlseqs.html#h2-Mouse-Tracking
 func WithMouse() Option {
 	return func(i *Input) {
@@ -112,6 +216,60 @@ func WithFocus() Option {
 	}
 }
 
+// WithOSC enables decoding of Operating System Command (OSC) sequences sent
+// by the terminal to the application, such as replies to OSC queries or
+// notifications (e.g. OSC 9 desktop notifications echoed back or kitty's
+// OSC 99 notification acknowledgements). Such sequences start with
+// "ESC ]" and are terminated by BEL or by the String Terminator "ESC \".
+// They are reported as a key with type KeyOSC, and the command number and
+// payload can be retrieved by calling Input.OSC before the next call to
+// Input.ReadKey. Without this option, they are reported as KeyESCSeq.
+func WithOSC() Option {
+	return func(i *Input) {
+		i.oscOn = true
+	}
+}
+
+// WithReadlineMeta adds the readline-style Meta navigation keys to the
+// mapping of escape sequences to special keys, regardless of the WithESCSeq
+// or WithProfile option used. Those are the sequences sent by most
+// terminals for the Alt (or Meta) key combinations used to navigate by
+// word in readline:
+//
+//	ESC b      Alt-b, reported as Left with ModAlt
+//	ESC f      Alt-f, reported as Right with ModAlt
+//	ESC d      Alt-d, reported as Delete with ModAlt
+//	ESC DEL    Alt-Backspace, reported as DEL with ModAlt
+//	ESC BS     Alt-Backspace, reported as BS with ModAlt
+//
+// Without this option, they are reported as KeyESCSeq.
+func WithReadlineMeta() Option {
+	return func(i *Input) {
+		i.rlMeta = true
+	}
+}
+
+// WithAltPrefix decodes the ESC followed by a rune as that rune with the
+// ModAlt flag, as sent by the terminals configured to prefix the keys
+// pressed with Alt (or Meta) with ESC (e.g. the "meta sends escape" setting
+// of xterm). The ESC followed by a control character is similarly reported
+// as the key of that control character with ModAlt (e.g. "ESC ^A" is the key
+// of type KeyCtrlA with ModAlt), and "ESC ESC" as KeyESC with ModAlt. The
+// ESC followed by the escape sequence of a key, as sent by many terminals
+// for Alt with a special key (e.g. "ESC ESC [ A" for Alt+Up), is reported
+// as that key with ModAlt, and as a single KeyESCSeq if the sequence is not
+// a known key. The sequences defined in the mapping of escape sequences
+// take precedence, e.g. those added by WithReadlineMeta. The introducers of
+// escape sequences ('[', 'O', ']', 'P', '_' and '^') are only decoded as
+// Alt keys if they are not followed by other bytes.
+//
+// Without this option, they are reported as KeyESCSeq.
+func WithAltPrefix() Option {
+	return func(i *Input) {
+		i.altPre = true
+	}
+}
+
 // WithESCSeq sets the terminfo-like map that defines the interpretation of
 // escape sequences as special keys. The map has the same field names as those
 // used in the github.com/gdamore/tcell/terminfo package for the Terminfo
@@ -123,6 +281,12 @@ func WithFocus() Option {
 // pass a non-nil empty map. All escape sequences will be returned as KeyESCSeq
 // and the raw bytes of the sequence can be retrieved by calling Input.Bytes.
 //
+// The navigation keys pressed with modifiers in the xterm form that are not
+// in the map, "ESC [ 1 ; mod X" for the arrows (X being A, B, C or D), Home
+// (H) and End (F), and "ESC [ code ; mod ~" for Insert, Delete, PgUp and
+// PgDn, are always decoded as the key with the modifier flags of the xterm
+// parameter mod (see ModFromXtermParam), e.g. Ctrl-Up or Alt-PgUp.
+//
 // If you want to use tcell's terminfo definitions directly, you can use the
 // helper function FromTerminfo that accepts an interface{} and returns a
 // map[string]string that can be used here, in order to avoid adding tcell as a
@@ -139,6 +303,287 @@ func WithESCSeq(tinfo map[string]string) Option {
 	}
 }
 
+// WithLoggerForDroppedBytes sets a function that is called with the bytes
+// that the Input had to skip to resynchronize the decoding, e.g. the bytes
+// of an invalid rune, or the unread bytes of an escape sequence longer than
+// the buffer that are discarded by the next ReadKey (see SeqReader), in
+// which case err is the CSIError returned by ReadKey with WithStrictCSI, or
+// a generic error otherwise. The context argument holds all the buffered bytes at
+// the time the bytes were dropped (the dropped bytes are a prefix of
+// context), and err describes why the bytes were dropped (it is the same
+// error as the one returned by ReadKey, if any). The slices are only valid
+// for the duration of the call and must not be modified.
+//
+// This is useful to diagnose flaky connections or misbehaving terminals.
+func WithLoggerForDroppedBytes(fn func(b, context []byte, err error)) Option {
+	return func(i *Input) {
+		i.dropped = fn
+	}
+}
+
+// ResyncPolicy defines how the Input skips over invalid bytes to
+// resynchronize the decoding.
+type ResyncPolicy int
+
+// List of supported resynchronization policies.
+const (
+	// ResyncByte skips a single byte, so that each invalid byte results in an
+	// error from ReadKey. This is the default.
+	ResyncByte ResyncPolicy = iota
+
+	// ResyncUTF8 skips all bytes up to the next one that starts a valid UTF-8
+	// encoding (which includes ESC and any other ASCII byte).
+	ResyncUTF8
+
+	// ResyncESC skips all bytes up to the next ESC, which is typically the
+	// start of the next escape sequence.
+	ResyncESC
+)
+
+// WithResyncPolicy sets the policy used to skip over invalid bytes, e.g.
+// the bytes of an invalid UTF-8 encoding. Whatever the policy, ReadKey
+// returns a single error for the skipped bytes, and they are reported to the
+// logger set by WithLoggerForDroppedBytes, if any. Only the bytes already
+// read are skipped, ReadKey never reads more bytes to resynchronize.
+//
+// This can prevent a single corrupted byte over a flaky serial or SSH link
+// from cascading into a stream of errors, e.g. if a multi-byte UTF-8
+// encoding lost its first byte.
+func WithResyncPolicy(p ResyncPolicy) Option {
+	return func(i *Input) {
+		i.resyncp = p
+	}
+}
+
+// WithDropPadding drops the NUL bytes from the input as soon as they are
+// read, instead of reporting them as KeyNUL keys (or as part of an escape
+// sequence). Some terminals and line disciplines inject NUL bytes as
+// padding, which can confuse applications. If del is true, the DEL bytes
+// are dropped too, but note that most terminals send DEL when the Backspace
+// key is pressed. Similarly, Ctrl-Space usually sends a NUL byte, so that
+// key combination cannot be detected when this option is set.
+func WithDropPadding(del bool) Option {
+	return func(i *Input) {
+		i.dropNUL = true
+		i.dropDEL = del
+	}
+}
+
+// WithESCPassthrough changes how an ESC followed by bytes that are not a
+// recognized escape sequence is reported: instead of reporting the whole
+// buffered bytes as a KeyESCSeq key, the ESC is reported immediately as a
+// KeyESC key and the following bytes are decoded on the next calls to
+// ReadKey (e.g. "ESC j" is reported as ESC followed by the rune 'j', and
+// "ESC ESC [ A" as ESC followed by Up). Complete but unknown CSI sequences
+// are still reported as KeyESCSeq.
+//
+// This is intended for vi-like modal editors that prioritize ESC latency
+// over the detection of Alt-modified keys, as the ESC typed quickly before
+// another key is not merged with it.
+func WithESCPassthrough() Option {
+	return func(i *Input) {
+		i.escPass = true
+	}
+}
+
+// WithPasteRequest reports the paste triggers that can be observed in the
+// terminal input as a single key of type KeyPasteRequest, so that the
+// application can handle them in one place, e.g. by requesting the content
+// of the clipboard with OSC 52. Those triggers are the Shift-Insert key and
+// the press of the middle mouse button (if WithMouse is set). For the
+// latter, Input.Mouse returns the mouse event of the press, so that the
+// position of the click is known, and the corresponding release is
+// silently consumed.
+//
+// Note that many terminals handle those triggers themselves by pasting the
+// selection or the clipboard, in which case the application never sees
+// them.
+func WithPasteRequest() Option {
+	return func(i *Input) {
+		i.pasteRq = true
+	}
+}
+
+// RetryPolicy defines how ReadKey retries a read that returns no byte and
+// no error.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries before ReadKey returns
+	// ErrTimeout. A value <= 0 disables retries.
+	MaxRetries int
+
+	// Backoff is the delay before the first retry, it doubles for each
+	// subsequent retry.
+	Backoff time.Duration
+
+	// MaxBackoff is the maximum delay between retries. A value <= 0 means no
+	// maximum.
+	MaxBackoff time.Duration
+}
+
+// returns the delay before the retry number n (starting at 0).
+func (p RetryPolicy) delay(n int) time.Duration {
+	d := p.Backoff
+	for ; n > 0 && d > 0; n-- {
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			break
+		}
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+// WithZeroReadRetry sets the policy to retry reads that return no byte and
+// no error. By default, ReadKey returns ErrTimeout immediately in that
+// case, as it is how terminals report a read timeout, but some readers
+// (e.g. some PTY wrappers and Windows pipes) spuriously return such reads,
+// which makes the event loops of applications spin. With this option, the
+// read is retried according to p before ReadKey returns ErrTimeout.
+func WithZeroReadRetry(p RetryPolicy) Option {
+	return func(i *Input) {
+		i.retry = p
+	}
+}
+
+// WithRawMirror writes the exact bytes consumed by ReadKey to w, as they are
+// consumed, including the bytes of sequences that are not returned as keys
+// (e.g. with WithDeviceReplyFilter) and the invalid bytes skipped. This
+// can be used to record a session or to forward the input transparently
+// without parsing it a second time. Errors returned by w are ignored, so w
+// should not fail nor block (e.g. a bytes.Buffer, a bufio.Writer or a Tee).
+func WithRawMirror(w io.Writer) Option {
+	return func(i *Input) {
+		i.mirror = w
+	}
+}
+
+// SetRawMirror sets the writer of the raw bytes consumed by ReadKey at
+// runtime, as for the WithRawMirror option, e.g. to start mirroring to a
+// Tee when a diagnostic process attaches. A nil w stops the mirroring. It
+// must not be called concurrently with ReadKey.
+func (i *Input) SetRawMirror(w io.Writer) {
+	i.mirror = w
+}
+
+// WithMouseMoveThreshold suppresses the mouse move events (the events
+// without any button, see MouseEvent.ButtonID) until the mouse has moved
+// at least cells cells horizontally or vertically from the position of
+// the last mouse event reported. This reduces the number of events for
+// applications that only need coarse hover tracking when MouseAny events
+// are enabled. A value <= 1 reports all events.
+func WithMouseMoveThreshold(cells int) Option {
+	return func(i *Input) {
+		i.moveMin = cells
+	}
+}
+
+// WithNaturalScrolling inverts the direction of the mouse wheel events, so
+// that applications can honor a "natural scrolling" preference in a single
+// place: the wheel up and down buttons (IDs 4 and 5) are swapped, as well
+// as the wheel left and right buttons (IDs 6 and 7).
+func WithNaturalScrolling() Option {
+	return func(i *Input) {
+		i.natural = true
+	}
+}
+
+// WithMouseCoordLimit sets the maximum value of the mouse coordinates. The
+// coordinates reported by the terminal are parsed as 32-bit integers and the
+// values greater than max are clamped to it, in which case the mouse event
+// is marked as clamped (see MouseEvent.Clamped). The default limit is 65535,
+// which is enough for any physical display, but very large virtual terminals
+// (e.g. the pseudo-terminals of some CI pipelines) may report greater
+// values. If max is <= 0, the default is used, and it cannot be greater than
+// the maximum value of a signed 32-bit integer.
+func WithMouseCoordLimit(max int) Option {
+	return func(i *Input) {
+		if max > math.MaxInt32 {
+			max = math.MaxInt32
+		}
+		i.maxXY = max
+	}
+}
+
+// WithCtrlMod reports the C0 control characters as the KeyCtrl... alias of
+// their key type with the ModCtrl flag set, e.g. byte 0x01 is returned as
+// the key of type KeyCtrlA with ModCtrl instead of the key of type KeyNUL
+// without modifier, so that key bindings can test for ModCtrl uniformly
+// with the other keys. The rune pressed with Ctrl is available via
+// Key.CtrlRune. TAB, CR and ESC are still reported without modifier as
+// they are also sent by the dedicated Tab, Enter and Escape keys. As the
+// keys differ from those returned by the package-level CtrlKeyFor, use
+// Input.CtrlKeyFor for the key bindings.
+func WithCtrlMod() Option {
+	return func(i *Input) {
+		i.ctrlMod = true
+	}
+}
+
+// WithDeviceReplyFilter silently consumes the replies to the Device Status
+// Report (DSR) and Device Attributes (DA) queries instead of reporting them
+// as KeyESCSeq keys. Some terminal multiplexers periodically send such
+// queries to the terminal (e.g. as a keep-alive) and their replies end up
+// in the input of the inner application. The filtered replies are:
+//
+//	CSI Pn n                (DSR, operating status report)
+//	CSI Pr ; Pc R           (DSR, cursor position report)
+//	CSI ? Pr ; Pc [; Pp] R  (DECXCPR, extended cursor position report)
+//	CSI ? Ps [; Ps...] c    (primary DA)
+//	CSI > Ps [; Ps...] c    (secondary DA)
+//
+// Note that a cursor position report for row 1 and column 2 to 8 is the
+// same sequence as F3 pressed with modifiers in the default mapping, e.g.
+// "ESC [ 1 ; 5 R" for Ctrl-F3, and in that case it is reported as that key.
+func WithDeviceReplyFilter() Option {
+	return func(i *Input) {
+		i.noReplies = true
+	}
+}
+
+// WithUnknownSeqTypes reports the unknown escape sequences with a key type
+// that depends on the kind of sequence instead of KeyESCSeq: KeyCSIUnknown
+// for Control Sequence Introducer sequences (ESC [), KeyOSCUnknown for
+// Operating System Commands (ESC ]) and KeyDCSUnknown for Device Control
+// Strings (ESC P). Other unknown sequences are still reported as
+// KeyESCSeq. Input.Seq returns the parsed parameters and final byte of a
+// complete CSI sequence, and Input.Bytes returns the raw bytes of all of
+// them, so that callers can categorize unknown traffic without parsing it.
+func WithUnknownSeqTypes() Option {
+	return func(i *Input) {
+		i.unkTypes = true
+	}
+}
+
+// WithStrictCSI rejects the invalid CSI sequences instead of reporting them
+// as KeyESCSeq: the sequences with too many parameters, a parameter greater
+// than the maximum value of a signed 32-bit integer, an invalid byte or
+// that are longer than the input buffer (e.g. a hostile "CSI 9;9;9;...m"
+// flood). ReadKey skips such a sequence, reports it to the dropped bytes
+// logger if any, and returns an error of type CSIError. The next call to
+// ReadKey resumes decoding after the sequence.
+func WithStrictCSI() Option {
+	return func(i *Input) {
+		i.strictCSI = true
+	}
+}
+
+// WithStrictMode validates the protocol of the escape sequences, e.g. to
+// use the Input as a reference to test a terminal emulator. It implies
+// WithStrictCSI, and in addition rejects the truncated CSI sequences (with
+// a CSIIncomplete error) and, if the mouse events are enabled, the SGR
+// mouse reports with invalid parameters (with a CSIInvalidMouse error)
+// instead of reporting them as KeyESCSeq. As for WithStrictCSI, ReadKey
+// skips such a sequence, reports it to the dropped bytes logger if any, and
+// returns an error of type CSIError.
+func WithStrictMode() Option {
+	return func(i *Input) {
+		i.strictCSI = true
+		i.strict = true
+	}
+}
+
 // Option defines the function signatures for options to apply when
 // creating a new Input.
 type Option func(*Input)
@@ -154,19 +599,40 @@ func NewInput(opts ...Option) *Input {
 	for _, o := range opts {
 		o(i)
 	}
+	if i.clock == nil {
+		i.clock = systemClock{}
+	}
 	if i.esc == nil {
 		i.esc = cloneEscMap(defaultEsc)
 	}
 	if i.focus {
 		addFocusESCSeq(i.esc)
 	}
+	if i.rlMeta {
+		addReadlineMetaESCSeq(i.esc)
+	}
+	if i.pasteRq {
+		addPasteRequestESCSeq(i.esc)
+	}
 
 	return i
 }
 
 // Bytes returns the uninterpreted bytes from the last key read. The bytes
 // are valid only until the next call to ReadKey and should not be modified.
+//
+// This works for all key types, including decoded escape sequences such as
+// KeyMouse, KeyFocusIn/KeyFocusOut, KeyOSC and KeyPaste (with the
+// delimiters, even if the paste was read in multiple reads), so that an
+// application that does not handle a key can forward its exact original
+// bytes. If ReadKey returned an error after skipping invalid bytes, Bytes
+// returns those skipped bytes. It returns nil if ReadKey did not consume
+// any byte (e.g. on ErrTimeout). See Event.Bytes for the bytes of an
+// Event, e.g. one that was queued by Expect.
 func (i *Input) Bytes() []byte {
+	if i.rbuf != nil {
+		return i.rbuf[:len(i.rbuf):len(i.rbuf)]
+	}
 	if i.sz <= 0 {
 		return nil
 	}
@@ -180,13 +646,167 @@ func (i *Input) Mouse() MouseEvent {
 	return i.lastm
 }
 
-const sgrMouseEventPrefix = "\x1b[<"
+// OSC returns the command number and payload of the last key of type
+// KeyOSC. For example, for "ESC ] 9 ; hello BEL", the command is 9 and the
+// payload is "hello". If the command is not a number, cmd is -1 and the
+// payload is the whole content of the sequence (without the leading
+// "ESC ]" and the terminator). The payload is valid only until the next
+// call to ReadKey and should not be modified. It should be called only
+// after a key of type KeyOSC has been received from ReadKey.
+func (i *Input) OSC() (cmd int, payload []byte) {
+	buf := i.buf
+	if i.rbuf != nil {
+		buf = i.rbuf
+	}
+	b := buf[i.osc[0]:i.osc[1]:i.osc[1]]
+	ix := bytes.IndexByte(b, ';')
+	if ix < 0 {
+		ix = len(b)
+	}
+	n, err := parseUintBytes(b[:ix])
+	if err != nil {
+		return -1, b
+	}
+	if ix < len(b) {
+		ix++
+	}
+	return int(n), b[ix:]
+}
+
+// SetMouseEnabled enables or disables the decoding of mouse events at
+// runtime, as for the WithMouse option. While disabled, the mouse event
+// sequences are reported as KeyESCSeq, e.g. while a child process owns the
+// terminal. It must not be called concurrently with ReadKey.
+func (i *Input) SetMouseEnabled(on bool) {
+	i.mouse = on
+}
+
+// SetPasteEnabled enables or disables the decoding of bracketed paste and
+// the reporting of the paste triggers as KeyPasteRequest keys at runtime,
+// as for the WithPaste and WithPasteRequest options. While disabled, the
+// paste delimiters are reported as KeyESCSeq and the pasted text as regular
+// keys, and the triggers as the regular keys and mouse events that they are
+// (e.g. Shift-Insert), e.g. while a child process owns the terminal. A
+// paste in progress is ended when it is disabled. It must not be called
+// concurrently with ReadKey.
+func (i *Input) SetPasteEnabled(on bool) {
+	i.pasteRq, i.pasteOn = on, on
+	if !on {
+		i.pmore = false
+	}
+	if on {
+		addPasteRequestESCSeq(i.esc)
+	}
+}
+
+const (
+	sgrMouseEventPrefix = "\x1b[<"
+	oscPrefix           = "\x1b]"
+	shiftInsertSeq      = "\x1b[2;2~"
+)
 
 // ReadKey reads a key from r which should be the reader of a terminal set in raw
 // mode. It is recommended to set a read timeout on the raw terminal so that a
 // Read does not block indefinitely. In that case, if a call to ReadKey times out
-// witout data for a key, it returns the zero-value of Key and ErrTimeout.
-func (i *Input) ReadKey(r io.Reader) (Key, error) {
+// witout data for a key, it returns the zero-value of Key and ErrTimeout. If
+// the terminal is closed, it returns an error that matches ErrTerminalClosed.
+func (i *Input) ReadKey(r io.Reader) (k Key, err error) {
+	if len(i.queue) > 0 {
+		k = i.replay()
+	} else {
+		k, err = i.read(r)
+	}
+	if err == nil && i.stamps {
+		i.keyLat = i.clock.Now().Sub(i.keyAt)
+	}
+	return k, err
+}
+
+// reads the next key from r, applying the two-key escapes if enabled.
+func (i *Input) read(r io.Reader) (k Key, err error) {
+	i.rbuf = nil
+	if i.twoKey != nil {
+		k, err = i.readTwoKey(r)
+	} else {
+		k, err = i.readNext(r)
+	}
+	if i.longPress != nil {
+		k, err = i.detectLongPress(k, err)
+	}
+	if err == nil && i.audit != nil {
+		i.auditKey(k)
+	}
+	return k, err
+}
+
+// reads the next key, skipping the filtered sequences.
+func (i *Input) readNext(r io.Reader) (Key, error) {
+	for {
+		k, err := i.readKey(r)
+		if err == nil && i.stamps {
+			i.keyAt = i.bufAt
+		}
+		if i.mirror != nil && i.sz > 0 {
+			i.mirror.Write(i.buf[:i.sz])
+		}
+		if err == nil && i.pasteRq {
+			k, err = i.pasteRequest(k)
+		}
+		if err == nil && k.Type() == KeyMouse {
+			err = i.filterMouseMove()
+		}
+		if err != errFiltered {
+			if err == nil {
+				i.stats.count(k)
+			}
+			return k, err
+		}
+	}
+}
+
+// returns errFiltered if the last mouse event is a move event that is
+// below the move threshold, otherwise records its position.
+func (i *Input) filterMouseMove() error {
+	m := i.lastm
+	if i.moveMin > 1 && m.buttonID == 0 {
+		dx, dy := int(m.x)-int(i.lastxy[0]), int(m.y)-int(i.lastxy[1])
+		if dx < 0 {
+			dx = -dx
+		}
+		if dy < 0 {
+			dy = -dy
+		}
+		if dx < i.moveMin && dy < i.moveMin {
+			return errFiltered
+		}
+	}
+	i.lastxy = [2]int32{m.x, m.y}
+	return nil
+}
+
+// translates the paste triggers to a KeyPasteRequest key, and returns
+// errFiltered for the release of the middle mouse button.
+func (i *Input) pasteRequest(k Key) (Key, error) {
+	switch {
+	case k.Type() == KeyInsert && k.Mod() == ModShift:
+		return keyFromTypeMod(KeyPasteRequest, ModNone), nil
+	case k.Type() == KeyMouse && i.lastm.buttonID == 2:
+		if !i.lastm.pressed {
+			return 0, errFiltered
+		}
+		return keyFromTypeMod(KeyPasteRequest, ModNone), nil
+	}
+	return k, nil
+}
+
+func (i *Input) readKey(r io.Reader) (Key, error) {
+	if i.kittyOn {
+		i.kkey = KittyKey{}
+	}
+	i.hl, i.hlOK = HighlightEvent{}, false
+	if err := i.drainSeqStream(); err != nil {
+		return 0, err
+	}
 	if i.sz > 0 {
 		// move buffer start to index 0 so that the maximum buffer
 		// size is available for more reads if required and reads start
@@ -194,12 +814,21 @@ func (i *Input) ReadKey(r io.Reader) (Key, error) {
 		copy(i.buf, i.buf[i.sz:i.len])
 		i.len -= i.sz
 		i.sz = 0
+		if i.stamps {
+			// the remaining bytes are usually from the last read
+			i.bufAt = i.lastAt
+		}
+	}
+	if i.pmore {
+		return i.decodePaste(r, 0)
 	}
 
 	var rn rune = -1
 	if i.len > 0 {
 		// try to read a rune from the already loaded bytes
-		c, sz := utf8.DecodeRune(i.buf[:i.len])
+		i.enter(PhaseDecode)
+		c, sz := i.decodeRune(i.buf[:i.len])
+		i.exit(PhaseDecode)
 		if c == utf8.RuneError && sz < 2 {
 			rn = -1
 		} else {
@@ -211,127 +840,464 @@ func (i *Input) ReadKey(r io.Reader) (Key, error) {
 
 	// if no valid rune, read more bytes
 	if rn < 0 {
-		n, err := r.Read(i.buf[i.len:])
-		if err != nil || n == 0 {
-			if i.len > 0 {
-				// we have a partial (invalid) rune, skip over a byte, do
-				// not return timeout error in this case (we have a byte)
-				i.sz = 1
-				return 0, errors.New("invalid rune")
+		var retries int
+		for {
+			i.enter(PhaseRead)
+			n, err := r.Read(i.buf[i.len:])
+			i.exit(PhaseRead)
+			i.stats.Bytes += uint64(n)
+			if n == 0 && err == nil && retries < i.retry.MaxRetries {
+				i.clock.Sleep(i.retry.delay(retries))
+				retries++
+				continue
 			}
-			// otherwise we have no byte at all, return ErrTimeout if
-			// n == 0 and (err == nil || err == io.EOF || err.Timeout() == true)
-			if n == 0 {
-				to, ok := err.(interface{ Timeout() bool })
-				if err == nil || err == io.EOF || (ok && to.Timeout()) {
-					return 0, ErrTimeout
+			if n > 0 && err == nil && i.dropNUL {
+				n = i.dropPadding(i.buf[i.len : i.len+n])
+			}
+			if err != nil || n == 0 {
+				if i.len > 0 {
+					// we have a partial (invalid) rune, skip over it according to
+					// the resync policy, do not return timeout error in this case
+					// (we have a byte)
+					i.resync()
+					i.drop(errInvalidRune)
+					return 0, errInvalidRune
+				}
+				// otherwise we have no byte at all
+				if n == 0 {
+					return 0, i.readErr(err)
 				}
+				return 0, i.closedErr(err)
+			}
+
+			if i.stamps {
+				i.lastAt = i.clock.Now()
+				if i.len == 0 {
+					i.bufAt = i.lastAt
+				}
+			}
+			i.len += n
+			// if the bytes are the start of a valid but incomplete rune (e.g.
+			// it was split over multiple reads), read more bytes.
+			if i.fullRune(i.buf[:i.len]) || i.len == len(i.buf) {
+				break
 			}
-			return 0, err
 		}
 
-		i.len += n
-		c, sz := utf8.DecodeRune(i.buf[:i.len])
+		i.enter(PhaseDecode)
+		c, sz := i.decodeRune(i.buf[:i.len])
+		i.exit(PhaseDecode)
 		if c == utf8.RuneError && sz < 2 {
-			i.sz = 1 // always consume at least one byte
-			return 0, errors.New("invalid rune")
+			i.resync() // always consume at least one byte
+			i.drop(errInvalidRune)
+			return 0, errInvalidRune
 		}
 		rn = c
 		i.sz = sz
 	}
 
-	// if rn is a control character (if i.len == 1 so that if an escape
-	// sequence is read, it does not return immediately with just ESC)
-	if i.len == 1 && (KeyType(rn) <= KeyUS || KeyType(rn) == KeyDEL) {
-		return keyFromTypeMod(KeyType(rn), ModNone), nil
+	if KeyType(rn) == KeyESC && (i.seqDelay > 0 || i.escDelay > 0) {
+		i.awaitSeq(r)
+	}
+
+	// if rn is a control character (for ESC, only if i.len == 1 so that if
+	// an escape sequence is read, it does not return immediately with just
+	// ESC)
+	if KeyType(rn) <= KeyUS || KeyType(rn) == KeyDEL {
+		if KeyType(rn) != KeyESC || i.len == 1 {
+			return i.ctrlKey(KeyType(rn)), nil
+		}
 	}
 
 	// translate escape sequences
 	if KeyType(rn) == KeyESC {
-		if i.mouse && bytes.HasPrefix(i.buf[:i.len], []byte(sgrMouseEventPrefix)) {
-			if k := i.decodeMouseEvent(); k.Type() == KeyMouse {
-				i.sz = i.len
-				return k, nil
-			}
+		i.enter(PhaseMatch)
+		k, err := i.decodeESC(r)
+		i.exit(PhaseMatch)
+		return k, err
+	}
+	return Key(rn), nil
+}
+
+// returns the error to report for a read that returned no byte and err:
+// ErrTimeout if err == nil, err == io.EOF or err.Timeout() == true,
+// otherwise err, wrapped if it indicates that the terminal is closed.
+func (i *Input) readErr(err error) error {
+	to, ok := err.(interface{ Timeout() bool })
+	if err == nil || (err == io.EOF && !i.eofClosed) || (ok && to.Timeout()) {
+		return ErrTimeout
+	}
+	return i.closedErr(err)
+}
+
+// decodes the escape sequence at the start of the buffer.
+func (i *Input) decodeESC(r io.Reader) (Key, error) {
+	if i.mouse && bytes.HasPrefix(i.buf[:i.len], []byte(sgrMouseEventPrefix)) {
+		if k := i.decodeMouseEvent(); k.Type() == KeyMouse {
+			return k, nil
+		}
+		if n := skipCSI(i.buf[:i.len]); i.strict && n > 0 {
+			i.sz = n
+			err := newCSIError(CSIInvalidMouse, i.buf[:i.len])
+			i.drop(err)
+			return 0, err
 		}
-		// NOTE: important to use the string conversion exactly like that,
-		// inside the brackets of the map key - the Go compiler optimizes
-		// this to avoid any allocation.
-		if key, ok := i.esc[string(i.buf[:i.len])]; ok {
-			i.sz = i.len
-			return key, nil
+	}
+	if i.mouse && i.legacy != 0 && i.len > 2 && i.buf[1] == '[' {
+		if k := i.decodeLegacyMouse(); k.Type() == KeyMouse {
+			return k, nil
 		}
-		// if this is an unknown escape sequence, return KeyESCSeq and the
-		// caller may get the uninterpreted sequence from i.Bytes.
+	}
+	if i.mouse && i.len > 2 && (i.buf[2] == 't' || i.buf[2] == 'T') {
+		if k := i.decodeHighlight(); k.Type() == KeyMouse {
+			return k, nil
+		}
+	}
+	if i.pasteOn && bytes.HasPrefix(i.buf[:i.len], []byte(pasteStartSeq)) {
+		return i.decodePaste(r, len(pasteStartSeq))
+	}
+	if i.oscOn && bytes.HasPrefix(i.buf[:i.len], []byte(oscPrefix)) {
+		if k := i.decodeOSC(); k.Type() == KeyOSC {
+			return k, nil
+		}
+	}
+	// NOTE: important to use the string conversion exactly like that,
+	// inside the brackets of the map key - the Go compiler optimizes
+	// this to avoid any allocation.
+	if key, ok := i.esc[string(i.buf[:i.len])]; ok {
 		i.sz = i.len
-		return keyFromTypeMod(KeyESCSeq, ModNone), nil
+		return key, nil
 	}
-	return Key(rn), nil
+	if k, n := decodeModNavKey(i.buf[:i.len]); n > 0 {
+		i.sz = n
+		return k, nil
+	}
+	if i.altPre {
+		if k := i.decodeAltPrefix(); k.Type() != KeyESCSeq {
+			return k, nil
+		}
+	}
+	if i.modKeys {
+		if k := i.decodeModifyOtherKeys(); k.Type() != KeyESCSeq {
+			return k, nil
+		}
+	}
+	if i.kittyOn {
+		if k := i.decodeKitty(); k.Type() != KeyESCSeq {
+			return k, nil
+		}
+	}
+	if i.tparmOn {
+		if k := i.decodeTermParams(); k.Type() == KeyTermParams {
+			return k, nil
+		}
+	}
+	if i.daOn {
+		if k := i.decodeDeviceAttributes(); k.Type() == KeyDeviceAttrs {
+			return k, nil
+		}
+	}
+	if s := i.newSeqStream(r); s != nil {
+		i.stream = s
+		i.sz = i.len
+		if i.strictCSI && !s.st {
+			// the rest of the sequence is skipped by the next ReadKey
+			err := newCSIError(CSITooLong, i.buf[:i.len])
+			s.err = err
+			i.drop(err)
+			return 0, err
+		}
+		return keyFromTypeMod(KeyESCSeqStream, ModNone), nil
+	}
+	if i.strictCSI && i.len > 1 && i.buf[1] == '[' {
+		// skip the invalid sequence up to its final byte, if any
+		_, _, err := ParseCSIStrict(i.buf[:i.len])
+		n := skipCSI(i.buf[:i.len])
+		if i.strict && n < 0 {
+			// truncated sequence, skip all of it
+			n = i.len
+		}
+		if err != nil && n > 0 {
+			i.sz = n
+			i.drop(err)
+			return 0, err
+		}
+	}
+	if i.csiHandler != nil || i.noReplies {
+		if seq, n := ParseCSI(i.buf[:i.len]); n > 0 {
+			if i.noReplies && isDeviceReply(&seq) {
+				i.sz = n
+				return 0, errFiltered
+			}
+			if i.csiHandler != nil {
+				if k, ok := i.csiHandler(&seq, i.buf[:n:n]); ok {
+					i.sz = n
+					return k, nil
+				}
+			}
+		}
+	}
+	if i.escPass {
+		if _, n := ParseCSI(i.buf[:i.len]); n == 0 {
+			i.sz = 1
+			return keyFromTypeMod(KeyESC, ModNone), nil
+		}
+	}
+	// if this is an unknown escape sequence, return KeyESCSeq (or the type
+	// specific to the kind of sequence) and the caller may get the
+	// uninterpreted sequence from i.Bytes.
+	i.sz = i.len
+	if i.unkTypes && i.len > 1 {
+		switch i.buf[1] {
+		case '[':
+			return keyFromTypeMod(KeyCSIUnknown, ModNone), nil
+		case ']':
+			return keyFromTypeMod(KeyOSCUnknown, ModNone), nil
+		case 'P':
+			return keyFromTypeMod(KeyDCSUnknown, ModNone), nil
+		}
+	}
+	return keyFromTypeMod(KeyESCSeq, ModNone), nil
+}
+
+// sets i.sz to the number of invalid bytes to skip according to the resync
+// policy. It always skips at least one byte.
+func (i *Input) resync() {
+	buf := i.buf[:i.len]
+	j := 1
+	switch i.resyncp {
+	case ResyncUTF8:
+		for ; j < len(buf); j++ {
+			if c, sz := i.decodeRune(buf[j:]); c != utf8.RuneError || sz > 1 {
+				break
+			}
+			if !i.fullRune(buf[j:]) {
+				// may be valid with more bytes
+				break
+			}
+		}
+	case ResyncESC:
+		for j < len(buf) && buf[j] != '\x1b' {
+			j++
+		}
+	}
+	i.sz = j
+}
+
+// CtrlKeyFor returns the key of the control character sent by the terminal
+// when r is pressed with Ctrl, as returned by ReadKey. It is the key
+// returned by the package-level CtrlKeyFor, with the ModCtrl flag if the
+// WithCtrlMod option is set (except for TAB, CR, ESC and DEL), e.g.:
+//
+//	if k == input.CtrlKeyFor('a') { ... }
+//
+// It returns 0 if there is no control character for r.
+func (i *Input) CtrlKeyFor(r rune) Key {
+	k := CtrlKeyFor(r)
+	if k == 0 {
+		return 0
+	}
+	return i.ctrlKey(k.Type())
+}
+
+// returns the key of the control character of type t, as the KeyCtrl...
+// alias with ModCtrl if WithCtrlMod is set.
+func (i *Input) ctrlKey(t KeyType) Key {
+	if !i.ctrlMod || t == KeyTAB || t == KeyCR || t == KeyESC || t == KeyDEL {
+		return keyFromTypeMod(t, ModNone)
+	}
+	return keyFromTypeMod(t, ModCtrl)
+}
+
+// removes the padding bytes from b by moving the other bytes to the start of
+// b, and returns the number of bytes left.
+func (i *Input) dropPadding(b []byte) int {
+	n := 0
+	for _, c := range b {
+		if c == 0 || (c == 0x7f && i.dropDEL) {
+			continue
+		}
+		b[n] = c
+		n++
+	}
+	return n
+}
+
+// reports the i.sz bytes about to be skipped to the dropped bytes logger,
+// if any.
+func (i *Input) drop(err error) {
+	i.stats.Dropped += uint64(i.sz)
+	if i.dropped != nil {
+		i.dropped(i.buf[:i.sz:i.sz], i.buf[:i.len:i.len], err)
+	}
+}
+
+// returns a KeyOSC key if the buffer holds a complete OSC sequence, in which
+// case i.sz is set to the length of that sequence. Otherwise it returns
+// a KeyESCSeq key.
+func (i *Input) decodeOSC() Key {
+	buf := i.buf[:i.len]
+	for j := len(oscPrefix); j < len(buf); j++ {
+		switch buf[j] {
+		case '\a':
+			i.osc = [2]int{len(oscPrefix), j}
+			i.sz = j + 1
+			return keyFromTypeMod(KeyOSC, ModNone)
+		case '\x1b':
+			if j+1 < len(buf) && buf[j+1] == '\\' {
+				i.osc = [2]int{len(oscPrefix), j}
+				i.sz = j + 2
+				return keyFromTypeMod(KeyOSC, ModNone)
+			}
+		}
+	}
+	return keyFromTypeMod(KeyESCSeq, ModNone)
 }
 
 // returns either a KeyMouse key, or a KeyESCSeq if it can't properly decode
-// the mouse event.
+// the mouse event. If it returns a KeyMouse key, i.sz is set to the length
+// of the mouse event sequence.
 func (i *Input) decodeMouseEvent() Key {
-	// the prefix has already been validated, strip it from the working buffer
-	buf := i.buf[len(sgrMouseEventPrefix):i.len]
-	if len(buf) < 6 {
-		// 2 semicolons, trailing m/M, at least one byte in each section
+	seq, n := ParseCSI(i.buf[:i.len])
+	if n == 0 || seq.Prefix != '<' || seq.Intermediate != 0 || seq.NumParams() != 3 || seq.HasSubParams() {
 		return keyFromTypeMod(KeyESCSeq, ModNone)
 	}
 
 	// the final character must be M (key press) or m (key release)
 	var pressed bool
-	switch buf[len(buf)-1] {
+	switch seq.Final {
 	case 'M':
 		pressed = true
 	case 'm':
 	default:
 		return keyFromTypeMod(KeyESCSeq, ModNone)
 	}
-	buf = buf[:len(buf)-1]
 
-	// extract the 3 parameter numbers
-	var nums [3]uint16
-	for i := 0; i < 2; i++ {
-		// must have 3 semicolon-separated parts, so 2 semicolons
-		ix := bytes.IndexByte(buf, ';')
-		if ix < 0 {
-			return keyFromTypeMod(KeyESCSeq, ModNone)
-		}
-		num, err := parseUintBytes(buf[:ix])
-		if err != nil {
+	// extract the 3 parameter numbers, the button is clamped to maxUint16
+	// and the coordinates to the limit
+	var nums [3]int
+	for j := range nums {
+		v := seq.Param(j)
+		if v < 0 {
 			return keyFromTypeMod(KeyESCSeq, ModNone)
 		}
-		nums[i] = num
-		buf = buf[ix+1:]
+		nums[j] = v
 	}
-	// process the 3rd (remaining) number
-	num, err := parseUintBytes(buf)
-	if err != nil {
-		return keyFromTypeMod(KeyESCSeq, ModNone)
+	if nums[0] > 1<<16-1 {
+		nums[0] = 1<<16 - 1
 	}
-	nums[2] = num
 
-	// decode the button event (first number)
-	mod := Mod(nums[0]) & modMouseEvent
-	btn := int(nums[0] & 0b_0000_0011) // this gives a number between 0-3, but 3 is not a button
-	add := int((nums[0] & 0b_1100_0000) >> 4)
+	k := i.setMouseEvent(nums[0], pressed, nums[1], nums[2])
+	if nums[1] == math.MaxInt32 || nums[2] == math.MaxInt32 {
+		// the CSI parameters that overflow an int32 are saturated
+		i.lastm.clamped = true
+	}
+	i.sz = n
+	return k
+}
+
+// sets i.lastm to the mouse event of the button code cb and the coordinates
+// x, y, and returns the corresponding KeyMouse key.
+func (i *Input) setMouseEvent(cb int, pressed bool, x, y int) Key {
+	// decode the button event
+	mod := Mod(cb) & modMouseEvent
+	btn := cb & 0b_0000_0011 // this gives a number between 0-3, but 3 is not a button
+	add := (cb & 0b_1100_0000) >> 4
 	btn += add // button is between 0-11
 	// detect if it is a mouse move only - i.e. no button pressed
-	if (btn == 0b_0011 && (nums[0]&0b_0010_0000 != 0)) || btn == 3 {
+	if (btn == 0b_0011 && (cb&0b_0010_0000 != 0)) || btn == 3 {
 		btn = 0
 	} else if btn < 3 {
 		btn++ // because 0-1-2 values are for IDs 1-2-3
 	}
 
-	i.lastm = MouseEvent{byte(btn), pressed, nums[1], nums[2]}
+	if i.natural && btn >= 4 && btn <= 7 {
+		btn ^= 1 // swaps 4 and 5, 6 and 7
+	}
 
-	//fmt.Printf("%d - %d - %d (pressed? %t; modifier: %s)\r\n", nums[0], nums[1], nums[2], !btnRelease, mod)
+	limit := i.maxXY
+	if limit <= 0 {
+		limit = defaultCoordLimit
+	}
+	i.lastm = MouseEvent{buttonID: byte(btn), pressed: pressed, motion: cb&0b_0010_0000 != 0}
+	i.lastm.setCoords(x, y, limit)
+	i.lastm.updateHeld(&i.held)
 	return keyFromTypeMod(KeyMouse, mod)
 }
 
+// returns the key with ModAlt of the rune that follows the ESC, or a
+// KeyESCSeq if the buffer does not start with an ESC followed by a rune. If
+// it returns an Alt key, i.sz is set to the length of the ESC and the rune,
+// the bytes that follow are decoded by the next calls.
+func (i *Input) decodeAltPrefix() Key {
+	if i.len < 2 {
+		return keyFromTypeMod(KeyESCSeq, ModNone)
+	}
+	switch i.buf[1] {
+	case '[', 'O', ']', 'P', '_', '^':
+		if i.len > 2 {
+			return keyFromTypeMod(KeyESCSeq, ModNone)
+		}
+	case '\x1b':
+		if i.len > 2 {
+			return i.decodeAltSeq()
+		}
+	}
+
+	r, sz := i.decodeRune(i.buf[1:i.len])
+	if r == utf8.RuneError && sz < 2 {
+		return keyFromTypeMod(KeyESCSeq, ModNone)
+	}
+	i.sz = 1 + sz
+	if t := KeyType(r); r < 0x20 || t == KeyDEL {
+		return keyFromTypeMod(t, i.ctrlKey(t).Mod()|ModAlt)
+	}
+	return keyFromRuneMod(r, ModAlt)
+}
+
+// returns the key with ModAlt of the escape sequence that follows the ESC
+// (e.g. Alt+Up sent as ESC ESC [ A), or a KeyESCSeq if it is not a known
+// key.
+func (i *Input) decodeAltSeq() Key {
+	b := i.buf[1:i.len]
+	k, ok := i.esc[string(b)]
+	n := len(b)
+	if !ok {
+		k, n = decodeModNavKey(b)
+	}
+	if n <= 0 {
+		return keyFromTypeMod(KeyESCSeq, ModNone)
+	}
+	i.sz = 1 + n
+	if k.Type() == KeyRune {
+		return keyFromRuneMod(k.Rune(), k.Mod()|ModAlt)
+	}
+	return keyFromTypeMod(k.Type(), k.Mod()|ModAlt)
+}
+
+// returns true if seq is a reply to a DSR or DA query.
+func isDeviceReply(seq *CSISeq) bool {
+	if seq.Intermediate != 0 {
+		return false
+	}
+	switch seq.Final {
+	case 'n':
+		return seq.Prefix == 0 && seq.NumParams() == 1
+	case 'R':
+		return (seq.Prefix == 0 && seq.NumParams() == 2) ||
+			(seq.Prefix == '?' && (seq.NumParams() == 2 || seq.NumParams() == 3))
+	case 'c':
+		// a primary DA reply may be empty for some minimal terminals
+		return seq.Prefix == '?' || (seq.Prefix == '>' && seq.NumParams() > 0)
+	}
+	return false
+}
+
 var (
+	errFiltered    = errors.New("filtered sequence") // never returned by ReadKey
 	errInvalidUint = errors.New("invalid uint number")
+	errInvalidRune = errors.New("invalid rune")
+	errSeqDropped  = errors.New("unread escape sequence discarded")
 )
 
 // parse a uint16 number in base 10 from the provided bytes. If the value is
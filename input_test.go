@@ -4,11 +4,15 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"strings"
 	"testing"
+	"time"
+
+	"git.sr.ht/~mna/zzterm/termreader"
 )
 
 func TestInput_ReadKey_Multiple(t *testing.T) {
@@ -93,6 +97,66 @@ func TestInput_ReadKey_BustBuffer(t *testing.T) {
 	}
 }
 
+func TestInput_ReadKey_SplitRunes(t *testing.T) {
+	// runes split over multiple reads are decoded as if read at once.
+	const in = "a⬼👪平ø"
+	for sz := 1; sz <= 4; sz++ {
+		t.Run(fmt.Sprint(sz), func(t *testing.T) {
+			r := termreader.ChunkReader(strings.NewReader(in), sz)
+			input := NewInput()
+			var got []rune
+			for {
+				k, err := input.ReadKey(r)
+				if errors.Is(err, ErrTimeout) {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, k.Rune())
+			}
+			if string(got) != in {
+				t.Fatalf("want %q, got %q", in, string(got))
+			}
+		})
+	}
+}
+
+func TestInput_ReadKey_DropPadding(t *testing.T) {
+	cases := []struct {
+		in   string
+		del  bool
+		keys []string
+	}{
+		{"\x00", false, nil},
+		{"\x00\x00a", false, []string{"Key(U+0061 'a')"}},
+		{"\x1b[A\x00\x00", false, []string{"Key(Up)"}},
+		{"\x7f", false, []string{"Key(DEL)"}},
+		{"\x7f", true, nil},
+		{"\x00⬼\x7f", true, []string{"Key(U+2B3C '⬼')"}},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%q %t", c.in, c.del), func(t *testing.T) {
+			input := NewInput(WithDropPadding(c.del))
+			r := strings.NewReader(c.in)
+			var keys []string
+			for {
+				k, err := input.ReadKey(r)
+				if errors.Is(err, ErrTimeout) {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				keys = append(keys, k.String())
+			}
+			if fmt.Sprint(keys) != fmt.Sprint(c.keys) {
+				t.Fatalf("want %v, got %v", c.keys, keys)
+			}
+		})
+	}
+}
+
 type testcase struct {
 	in  string
 	r   rune
@@ -118,6 +182,11 @@ func TestInput_ReadKey_DefaultTinfo(t *testing.T) {
 		{"\x1b[3~", -1, KeyDelete, ModNone},
 		{"\x1b[1;2D", -1, KeyLeft, ModShift},
 		{"\x1b[1;2C", -1, KeyRight, ModShift},
+		{"\x1bOP", -1, KeyF1, ModNone},
+		{"\x1b[1;2P", -1, KeyF1, ModShift},
+		{"\x1b[1;5S", -1, KeyF4, ModCtrl},
+		{"\x1b[15;3~", -1, KeyF5, ModAlt},
+		{"\x1b[24;8~", -1, KeyF12, ModShift | ModAlt | ModCtrl},
 	}
 
 	input := NewInput()
@@ -203,6 +272,58 @@ func TestInput_ReadKey_Focus(t *testing.T) {
 	}
 }
 
+func TestInput_ReadKey_OSC(t *testing.T) {
+	cases := []struct {
+		in      string
+		cmd     int
+		payload string
+		bytes   string
+	}{
+		{"\x1b]9;hello\a", 9, "hello", "\x1b]9;hello\a"},
+		{"\x1b]99;i=1:p=title;\x1b\\", 99, "i=1:p=title;", "\x1b]99;i=1:p=title;\x1b\\"},
+		{"\x1b]777\a", 777, "", "\x1b]777\a"},
+		{"\x1b]abc\a", -1, "abc", "\x1b]abc\a"},
+		{"\x1b]11;rgb:0000/0000/0000\aa", 11, "rgb:0000/0000/0000", "\x1b]11;rgb:0000/0000/0000\a"},
+	}
+
+	input := NewInput(WithOSC())
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			k, err := input.ReadKey(strings.NewReader(c.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if k.Type() != KeyOSC {
+				t.Fatalf("want key type %s, got %s", KeyOSC, k.Type())
+			}
+			cmd, payload := input.OSC()
+			if cmd != c.cmd || string(payload) != c.payload {
+				t.Errorf("want %d %q, got %d %q", c.cmd, c.payload, cmd, payload)
+			}
+			if b := string(input.Bytes()); b != c.bytes {
+				t.Errorf("want bytes %q, got %q", c.bytes, b)
+			}
+		})
+	}
+
+	// unterminated or without the option, reported as an escape sequence
+	for _, input := range []*Input{NewInput(WithOSC()), NewInput()} {
+		in := "\x1b]9;hello"
+		if input.oscOn {
+			in = "\x1b]9;hello\x1b"
+		} else {
+			in += "\a"
+		}
+		k, err := input.ReadKey(strings.NewReader(in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k.Type() != KeyESCSeq {
+			t.Errorf("%q: want key type %s, got %s", in, KeyESCSeq, k.Type())
+		}
+	}
+}
+
 func TestInput_ReadKey_Mouse(t *testing.T) {
 	cases := []struct {
 		in      string
@@ -261,6 +382,37 @@ func TestInput_ReadKey_Mouse(t *testing.T) {
 	}
 }
 
+func TestInput_ReadKey_MouseButtons(t *testing.T) {
+	cases := []struct {
+		in      string
+		buttons uint16
+	}{
+		{"\x1b[<0;1;1M", 0b001},  // left press
+		{"\x1b[<2;1;1M", 0b101},  // right press
+		{"\x1b[<32;2;1M", 0b101}, // drag
+		{"\x1b[<64;2;1M", 0b101}, // wheel up
+		{"\x1b[<0;2;1m", 0b100},  // left release
+		{"\x1b[<1;2;1M", 0b110},  // middle press
+		{"\x1b[<2;2;1m", 0b010},  // right release
+		{"\x1b[<1;2;1m", 0b000},  // middle release
+		{"\x1b[<35;2;1M", 0b000}, // move
+	}
+
+	input := NewInput(WithMouse())
+	for _, c := range cases {
+		k, err := input.ReadKey(strings.NewReader(c.in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k.Type() != KeyMouse {
+			t.Fatalf("%q: want mouse key, got %s", c.in, k)
+		}
+		if got := input.Mouse().Buttons(); got != c.buttons {
+			t.Fatalf("%q: want buttons %03b, got %03b", c.in, c.buttons, got)
+		}
+	}
+}
+
 func TestInput_ReadKey_Bytes(t *testing.T) {
 	input := NewInput(WithESCSeq(make(map[string]string)))
 
@@ -302,6 +454,136 @@ func TestInput_ReadKey_Bytes(t *testing.T) {
 	}
 }
 
+func TestInput_ReadKey_DroppedBytes(t *testing.T) {
+	type drop struct {
+		b, ctx string
+		err    string
+	}
+	var drops []drop
+	input := NewInput(WithLoggerForDroppedBytes(func(b, ctx []byte, err error) {
+		drops = append(drops, drop{string(b), string(ctx), err.Error()})
+	}))
+
+	r := strings.NewReader("a\xff\xfeb")
+	for {
+		_, err := input.ReadKey(r)
+		if errors.Is(err, ErrTimeout) {
+			break
+		}
+	}
+
+	want := []drop{
+		{"\xff", "\xff\xfeb", "invalid rune"},
+		{"\xfe", "\xfeb", "invalid rune"},
+	}
+	if len(drops) != len(want) {
+		t.Fatalf("want %d dropped bytes, got %d", len(want), len(drops))
+	}
+	for i, w := range want {
+		if drops[i] != w {
+			t.Errorf("[%d]: want %q, got %q", i, w, drops[i])
+		}
+	}
+}
+
+func TestInput_ReadKey_ResyncPolicy(t *testing.T) {
+	// the first byte of the 3-bytes '⬼' is missing, followed by an invalid
+	// byte, and then a valid escape sequence and a valid rune.
+	in := "\xAC\xBC\xff\x1b[A"
+	cases := []struct {
+		policy ResyncPolicy
+		errs   int
+		keys   []string
+	}{
+		{ResyncByte, 3, []string{"Key(Up)"}},
+		{ResyncUTF8, 1, []string{"Key(Up)"}},
+		{ResyncESC, 1, []string{"Key(Up)"}},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprint(c.policy), func(t *testing.T) {
+			var dropped []byte
+			input := NewInput(WithResyncPolicy(c.policy), WithLoggerForDroppedBytes(func(b, _ []byte, _ error) {
+				dropped = append(dropped, b...)
+			}))
+
+			r := strings.NewReader(in)
+			var errs int
+			var keys []string
+			for {
+				k, err := input.ReadKey(r)
+				if errors.Is(err, ErrTimeout) {
+					break
+				}
+				if err != nil {
+					errs++
+					continue
+				}
+				keys = append(keys, k.String())
+			}
+			if errs != c.errs {
+				t.Errorf("want %d errors, got %d", c.errs, errs)
+			}
+			if fmt.Sprint(keys) != fmt.Sprint(c.keys) {
+				t.Errorf("want keys %v, got %v", c.keys, keys)
+			}
+			if string(dropped) != "\xAC\xBC\xff" {
+				t.Errorf("want dropped bytes %q, got %q", "\xAC\xBC\xff", dropped)
+			}
+		})
+	}
+
+	// ResyncUTF8 stops at the start of a valid rune
+	input := NewInput(WithResyncPolicy(ResyncUTF8))
+	r := strings.NewReader("\xBC\xBCa")
+	if _, err := input.ReadKey(r); err == nil {
+		t.Fatal("want error")
+	}
+	if k, err := input.ReadKey(r); err != nil || k != Key('a') {
+		t.Fatalf("want key a, got %s (%v)", k, err)
+	}
+}
+
+func TestInput_ReadKey_BytesAllTypes(t *testing.T) {
+	cases := []struct {
+		in  string
+		typ KeyType
+	}{
+		{"a", KeyRune},
+		{"\x03", KeyETX},
+		{"\x1b[A", KeyUp},
+		{"\x1b[<0;1;2M", KeyMouse},
+		{"\x1b[I", KeyFocusIn},
+		{"\x1b]9;x\a", KeyOSC},
+		{"\x1b[200~a\rb\x1b[201~", KeyPaste},
+		{"\x1b[?1;2c", KeyESCSeq},
+	}
+
+	input := NewInput(WithMouse(), WithFocus(), WithOSC(), WithPaste())
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			r := strings.NewReader(c.in)
+			k, err := input.ReadKey(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if k.Type() != c.typ {
+				t.Fatalf("want key type %s, got %s", c.typ, k.Type())
+			}
+			if b := string(input.Bytes()); b != c.in {
+				t.Fatalf("want bytes %q, got %q", c.in, b)
+			}
+
+			// on timeout, no bytes
+			if _, err := input.ReadKey(r); !errors.Is(err, ErrTimeout) {
+				t.Fatalf("want ErrTimeout, got %v", err)
+			}
+			if b := input.Bytes(); b != nil {
+				t.Fatalf("want no bytes on timeout, got %q", b)
+			}
+		})
+	}
+}
+
 func runTestcase(t *testing.T, c testcase, input *Input) {
 	t.Helper()
 
@@ -430,3 +712,570 @@ func BenchmarkInput_ReadKey_Timeout(b *testing.B) {
 		r.Reset(data)
 	}
 }
+
+func BenchmarkInput_ReadKey_Corpus(b *testing.B) {
+	src := NewBenchmarkSource(
+		"a", "B", "ø", "👪", "\x00", "\r", "\x1b", "\x1b[B", "\x1b[1;2C", "\x1bOP", "\x1b[15~",
+		"\x1b[<0;10;20M", "\x1b[<32;11;20M", "\x1b[<0;11;20m", "\x1b[<65;3;4M", "\x1b[I", "\x1b[O",
+		"\x1b[200~hello, world\x1b[201~", "\x1b]11;rgb:0000/0000/0000\x07",
+	)
+	input := NewInput(WithFocus(), WithMouse(), WithPaste(), WithOSC())
+	b.SetBytes(int64(src.Bytes()))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < src.Len(); j++ {
+			k, err := input.ReadKey(src)
+			if err != nil {
+				b.Fatal(err)
+			}
+			BenchmarkKey = k
+		}
+	}
+}
+
+func TestInput_ReadKey_DeviceReplyFilter(t *testing.T) {
+	cases := []struct {
+		in  string
+		typ KeyType
+	}{
+		{"\x1b[0na", KeyRune},
+		{"\x1b[12;40R\x1b[A", KeyUp},
+		{"\x1b[?12;40;1R\x1b[A", KeyUp},
+		{"\x1b[?64;1;2;6;22cz", KeyRune},
+		{"\x1b[?cz", KeyRune},
+		{"\x1b[>41;351;0c\x1b[?1;2c\x1b[0n\x1b[B", KeyDown},
+		{"\x1b[1;5R", KeyF3},
+		{"\x1b[5n\x1b[12;40R", KeyNUL},
+		{"\x1b[?1049;1$y", KeyESCSeq},
+		{"\x1b[c", KeyESCSeq},
+	}
+
+	input := NewInput(WithDeviceReplyFilter())
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			k, err := input.ReadKey(strings.NewReader(c.in))
+			if c.typ == KeyNUL {
+				if err != ErrTimeout {
+					t.Fatalf("want ErrTimeout, got %v (%s)", err, k)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if k.Type() != c.typ {
+				t.Errorf("want type %s, got %s", c.typ, k.Type())
+			}
+		})
+	}
+}
+
+func TestInput_ReadKey_ESCPassthrough(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []Key
+	}{
+		{"\x1b", []Key{NewKey(KeyESC, ModNone)}},
+		{"\x1bj", []Key{NewKey(KeyESC, ModNone), 'j'}},
+		{"\x1b\x1b[A", []Key{NewKey(KeyESC, ModNone), NewKey(KeyUp, ModNone)}},
+		{"\x1b[A", []Key{NewKey(KeyUp, ModNone)}},
+		{"\x1b[1;5P", []Key{NewKey(KeyF1, ModCtrl)}},
+		{"\x1b[99z", []Key{NewKey(KeyESCSeq, ModNone)}},
+		{"\x1b:wq", []Key{NewKey(KeyESC, ModNone), ':', 'w', 'q'}},
+	}
+
+	input := NewInput(WithESCPassthrough())
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			r := strings.NewReader(c.in)
+			for j, want := range c.want {
+				k, err := input.ReadKey(r)
+				if err != nil {
+					t.Fatalf("%d: %v", j, err)
+				}
+				if k != want {
+					t.Errorf("%d: want %s, got %s", j, want, k)
+				}
+			}
+			if k, err := input.ReadKey(r); err != ErrTimeout {
+				t.Fatalf("want ErrTimeout, got %v (%s)", err, k)
+			}
+		})
+	}
+}
+
+func TestInput_ReadKey_ReadlineMeta(t *testing.T) {
+	cases := []testcase{
+		{"\x1bb", -1, KeyLeft, ModAlt},
+		{"\x1bf", -1, KeyRight, ModAlt},
+		{"\x1bd", -1, KeyDelete, ModAlt},
+		{"\x1b\x7f", -1, KeyDEL, ModAlt},
+		{"\x1b\b", -1, KeyBS, ModAlt},
+		{"\x1b[D", -1, KeyLeft, ModNone},
+		{"\x1bx", -1, KeyESCSeq, ModNone},
+	}
+
+	input := NewInput(WithESCSeq(map[string]string{"KeyLeft": "\x1b[D"}), WithReadlineMeta())
+	for _, c := range cases {
+		runTestcase(t, c, input)
+	}
+
+	input = NewInput()
+	runTestcase(t, testcase{"\x1bb", -1, KeyESCSeq, ModNone}, input)
+}
+
+func TestInput_ReadKey_ModNavKeys(t *testing.T) {
+	cases := []testcase{
+		{"\x1b[1;5A", -1, KeyUp, ModCtrl},
+		{"\x1b[1;3B", -1, KeyDown, ModAlt},
+		{"\x1b[1;2C", -1, KeyRight, ModShift},
+		{"\x1b[1;6H", -1, KeyHome, ModCtrl | ModShift},
+		{"\x1b[1;8F", -1, KeyEnd, ModCtrl | ModAlt | ModShift},
+		{"\x1b[1;9D", -1, KeyLeft, ModMeta},
+		{"\x1b[1;1D", -1, KeyLeft, ModNone},
+		{"\x1b[5;3~", -1, KeyPgUp, ModAlt},
+		{"\x1b[6;5~", -1, KeyPgDn, ModCtrl},
+		{"\x1b[3;16~", -1, KeyDelete, ModMeta | ModCtrl | ModAlt | ModShift},
+		{"\x1b[2;2~", -1, KeyInsert, ModShift},
+		{"\x1b[1;17A", -1, KeyESCSeq, ModNone},
+		{"\x1b[1;0A", -1, KeyESCSeq, ModNone},
+		{"\x1b[2;5A", -1, KeyESCSeq, ModNone},
+		{"\x1b[4;5~", -1, KeyESCSeq, ModNone},
+		{"\x1b[1;5Z", -1, KeyESCSeq, ModNone},
+		{"\x1b[?1;5A", -1, KeyESCSeq, ModNone},
+		{"\x1b[1;5:2A", -1, KeyESCSeq, ModNone},
+	}
+
+	input := NewInput()
+	for _, c := range cases {
+		runTestcase(t, c, input)
+	}
+
+	// the mapping takes precedence
+	input = NewInput(WithESCSeq(map[string]string{"KeyF5": "\x1b[1;5A"}))
+	runTestcase(t, testcase{"\x1b[1;5A", -1, KeyF5, ModNone}, input)
+}
+
+func TestInput_ReadKey_AltPrefix(t *testing.T) {
+	cases := []testcase{
+		{"\x1bx", 'x', KeyRune, ModAlt},
+		{"\x1bX", 'X', KeyRune, ModAlt},
+		{"\x1bé", 'é', KeyRune, ModAlt},
+		{"\x1b[", '[', KeyRune, ModAlt},
+		{"\x1bO", 'O', KeyRune, ModAlt},
+		{"\x1b\x01", -1, KeyCtrlA, ModAlt},
+		{"\x1b\x1b", -1, KeyESC, ModAlt},
+		{"\x1b\x7f", -1, KeyDEL, ModAlt},
+		{"\x1bb", -1, KeyLeft, ModAlt},
+		{"\x1b[D", -1, KeyLeft, ModNone},
+		{"\x1b[Z", -1, KeyESCSeq, ModNone},
+		{"\x1b\xff", -1, KeyESCSeq, ModNone},
+		{"\x1b\x1b[D", -1, KeyLeft, ModAlt},
+		{"\x1b\x1b[Z", -1, KeyESCSeq, ModNone},
+	}
+
+	input := NewInput(WithESCSeq(map[string]string{"KeyLeft": "\x1b[D"}), WithReadlineMeta(), WithAltPrefix())
+	for _, c := range cases {
+		runTestcase(t, c, input)
+	}
+
+	// Alt with a special key sent as ESC followed by its escape sequence
+	input = NewInput(WithAltPrefix())
+	for _, c := range []testcase{
+		{"\x1b\x1b[A", -1, KeyUp, ModAlt},
+		{"\x1b\x1bOP", -1, KeyF1, ModAlt},
+		{"\x1b\x1b[1;5C", -1, KeyRight, ModAlt | ModCtrl},
+		{"\x1b\x1b[1;2H", -1, KeyHome, ModAlt | ModShift},
+		{"\x1b\x1b[3~", -1, KeyDelete, ModAlt},
+		{"\x1b\x1b[15~", -1, KeyF5, ModAlt},
+		{"\x1b\x1b[1;5", -1, KeyESCSeq, ModNone},
+		{"\x1b\x1b[999~", -1, KeyESCSeq, ModNone},
+		{"\x1b\x1bx", -1, KeyESCSeq, ModNone},
+	} {
+		runTestcase(t, c, input)
+	}
+
+	input = NewInput(WithAltPrefix(), WithCtrlMod())
+	runTestcase(t, testcase{"\x1b\x01", -1, KeyCtrlA, ModAlt | ModCtrl}, input)
+	runTestcase(t, testcase{"\x1b\r", -1, KeyCR, ModAlt}, input)
+
+	// the keys that follow the Alt key are decoded separately
+	r := strings.NewReader("\x1babc")
+	want := []Key{NewRuneKey('a', ModAlt), 'b', 'c'}
+	for j, w := range want {
+		k, err := input.ReadKey(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k != w {
+			t.Errorf("[%d]: want %s, got %s", j, w, k)
+		}
+	}
+
+	// Alt with a digit is not mistaken for an escape sequence of a profile
+	for _, p := range []*Profile{ProfileXterm, ProfileScreen, ProfileWezTerm, ProfileFoot} {
+		input = NewInput(WithProfile(p), WithAltPrefix())
+		for d := '0'; d <= '9'; d++ {
+			runTestcase(t, testcase{"\x1b" + string(d), d, KeyRune, ModAlt}, input)
+		}
+	}
+
+	input = NewInput()
+	runTestcase(t, testcase{"\x1bx", -1, KeyESCSeq, ModNone}, input)
+}
+
+func TestInput_ReadKey_PasteRequest(t *testing.T) {
+	input := NewInput(WithMouse(), WithPasteRequest())
+	cases := []struct {
+		in  string
+		typ KeyType
+		err error
+	}{
+		{"\x1b[2;2~", KeyPasteRequest, nil},
+		{"\x1b[2~", KeyInsert, nil},
+		{"\x1b[<1;10;20M", KeyPasteRequest, nil},
+		{"\x1b[<1;10;20m", 0, ErrTimeout},
+		{"\x1b[<0;10;20M", KeyMouse, nil},
+		{"\x1b[<0;10;20m", KeyMouse, nil},
+		{"v", KeyRune, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			k, err := input.ReadKey(strings.NewReader(c.in))
+			if err != c.err {
+				t.Fatalf("want error %v, got %v", c.err, err)
+			}
+			if err == nil && k.Type() != c.typ {
+				t.Errorf("want type %s, got %s", c.typ, k.Type())
+			}
+		})
+	}
+
+	k, _ := input.ReadKey(strings.NewReader("\x1b[<1;3;4M"))
+	if x, y := input.Mouse().Coords(); k.Type() != KeyPasteRequest || x != 3 || y != 4 {
+		t.Errorf("want KeyPasteRequest at 3,4, got %s at %d,%d", k, x, y)
+	}
+
+	input = NewInput()
+	runTestcase(t, testcase{"\x1b[2;2~", -1, KeyInsert, ModShift}, input)
+}
+
+func TestInput_ReadKey_ZeroReadRetry(t *testing.T) {
+	input := NewInput(WithZeroReadRetry(RetryPolicy{MaxRetries: 3, Backoff: time.Millisecond}))
+
+	r := &scriptReader{chunks: []string{"", "", "a"}}
+	k, err := input.ReadKey(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k != 'a' {
+		t.Errorf("want 'a', got %s", k)
+	}
+
+	r = &scriptReader{chunks: []string{"", "", "", "", "a"}}
+	if _, err := input.ReadKey(r); err != ErrTimeout {
+		t.Fatalf("want ErrTimeout, got %v", err)
+	}
+	if len(r.chunks) != 1 {
+		t.Errorf("want 1 chunk left, got %d", len(r.chunks))
+	}
+
+	input = NewInput()
+	r = &scriptReader{chunks: []string{"", "a"}}
+	if _, err := input.ReadKey(r); err != ErrTimeout {
+		t.Fatalf("want ErrTimeout, got %v", err)
+	}
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	p := RetryPolicy{Backoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}
+	want := []time.Duration{10, 20, 40, 50, 50}
+	for n, w := range want {
+		if d := p.delay(n); d != w*time.Millisecond {
+			t.Errorf("%d: want %s, got %s", n, w*time.Millisecond, d)
+		}
+	}
+	p.MaxBackoff = 0
+	if d := p.delay(4); d != 160*time.Millisecond {
+		t.Errorf("want 160ms, got %s", d)
+	}
+}
+
+func TestInput_ReadKey_RawMirror(t *testing.T) {
+	in := "a\x1b[A\xff\x1b[0n" + "\x1b]2;" + strings.Repeat("x", 200) + "\a" + "👪\x1b[<0;1;2M"
+
+	var buf bytes.Buffer
+	input := NewInput(WithMouse(), WithDeviceReplyFilter(), WithRawMirror(&buf))
+	r := termreader.ChunkReader(strings.NewReader(in), 1, 3, 1, 4, 128, 128)
+	for {
+		_, err := input.ReadKey(r)
+		if errors.Is(err, ErrTimeout) {
+			break
+		}
+	}
+	if buf.String() != in {
+		t.Errorf("want mirror\n%q\ngot\n%q", in, buf.String())
+	}
+}
+
+func TestInput_ReadKey_MouseMoveThreshold(t *testing.T) {
+	move := func(x, y int) string { return fmt.Sprintf("\x1b[<35;%d;%dM", x, y) }
+	cases := []struct {
+		in       string
+		reported bool
+	}{
+		{move(10, 10), true},
+		{move(11, 10), false},
+		{move(12, 11), false},
+		{move(13, 10), true},
+		{move(13, 8), false},
+		{move(13, 7), true},
+		{"\x1b[<0;14;7M", true}, // press is always reported
+		{move(15, 7), false},
+		{"\x1b[<0;10;10m", true},
+		{move(10, 12), false},
+		{move(7, 12), true},
+	}
+
+	input := NewInput(WithMouse(), WithMouseMoveThreshold(3))
+	for i, c := range cases {
+		k, err := input.ReadKey(strings.NewReader(c.in))
+		if c.reported {
+			if err != nil {
+				t.Fatalf("%d: %v", i, err)
+			}
+			if k.Type() != KeyMouse {
+				t.Errorf("%d: want KeyMouse, got %s", i, k)
+			}
+			continue
+		}
+		if err != ErrTimeout {
+			t.Errorf("%d: want ErrTimeout, got %s, %v", i, k, err)
+		}
+	}
+}
+
+func TestInput_ReadKey_MouseDrag(t *testing.T) {
+	cases := []struct {
+		in       string
+		btn      int
+		pressed  bool
+		dragging bool
+	}{
+		{"\x1b[<0;1;1M", 1, true, false},
+		{"\x1b[<32;2;1M", 1, true, true},
+		{"\x1b[<34;3;1M", 3, true, true},
+		{"\x1b[<48;3;2M", 1, true, true},
+		{"\x1b[<0;3;2m", 1, false, false},
+		{"\x1b[<35;4;2M", 0, true, false},
+		{"\x1b[<64;4;2M", 4, true, false},
+	}
+
+	input := NewInput(WithMouse())
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			if _, err := input.ReadKey(strings.NewReader(c.in)); err != nil {
+				t.Fatal(err)
+			}
+			m := input.Mouse()
+			if m.ButtonID() != c.btn || m.ButtonPressed() != c.pressed || m.Dragging() != c.dragging {
+				t.Errorf("want button %d pressed=%t dragging=%t, got %d %t %t", c.btn, c.pressed, c.dragging,
+					m.ButtonID(), m.ButtonPressed(), m.Dragging())
+			}
+
+			// the motion state is preserved by the encoding
+			_, got, _, _, err := DecodeEvent(AppendEvent(nil, NewKey(KeyMouse, ModNone), m, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != m {
+				t.Errorf("want %v after encoding, got %v", m, got)
+			}
+		})
+	}
+}
+
+func TestInput_ReadKey_NaturalScrolling(t *testing.T) {
+	cases := []struct {
+		in      string
+		natural int
+		normal  int
+	}{
+		{"\x1b[<64;1;1M", 5, 4},
+		{"\x1b[<65;1;1M", 4, 5},
+		{"\x1b[<66;1;1M", 7, 6},
+		{"\x1b[<67;1;1M", 6, 7},
+		{"\x1b[<0;1;1M", 1, 1},
+		{"\x1b[<35;1;1M", 0, 0},
+	}
+
+	natural := NewInput(WithMouse(), WithNaturalScrolling())
+	normal := NewInput(WithMouse())
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			if _, err := natural.ReadKey(strings.NewReader(c.in)); err != nil {
+				t.Fatal(err)
+			}
+			if got := natural.Mouse().ButtonID(); got != c.natural {
+				t.Errorf("natural: want button %d, got %d", c.natural, got)
+			}
+			if _, err := normal.ReadKey(strings.NewReader(c.in)); err != nil {
+				t.Fatal(err)
+			}
+			if got := normal.Mouse().ButtonID(); got != c.normal {
+				t.Errorf("normal: want button %d, got %d", c.normal, got)
+			}
+		})
+	}
+}
+
+func TestInput_ReadKey_MouseCoordLimit(t *testing.T) {
+	cases := []struct {
+		in      string
+		limit   int
+		x, y    int
+		clamped bool
+	}{
+		{"\x1b[<0;10;20M", 0, 10, 20, false},
+		{"\x1b[<0;65535;20M", 0, 65535, 20, false},
+		{"\x1b[<0;65536;20M", 0, 65535, 20, true},
+		{"\x1b[<0;10;100000M", 0, 10, 65535, true},
+		{"\x1b[<0;100000;200000M", 1 << 20, 100000, 200000, false},
+		{"\x1b[<0;100000;200000M", 150000, 100000, 150000, true},
+		{"\x1b[<0;100;20M", 80, 80, 20, true},
+		{"\x1b[<0;99999999999;1M", 1 << 40, 1<<31 - 1, 1, true},
+	}
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			input := NewInput(WithMouse(), WithMouseCoordLimit(c.limit))
+			k, err := input.ReadKey(strings.NewReader(c.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if k.Type() != KeyMouse {
+				t.Fatalf("want mouse key, got %s", k)
+			}
+			m := input.Mouse()
+			if x, y := m.Coords(); x != c.x || y != c.y || m.Clamped() != c.clamped {
+				t.Errorf("want %d,%d clamped=%t, got %d,%d clamped=%t", c.x, c.y, c.clamped, x, y, m.Clamped())
+			}
+		})
+	}
+}
+
+func TestInput_ReadKey_CtrlMod(t *testing.T) {
+	cases := []struct {
+		in     string
+		ctrl   Key
+		normal Key
+	}{
+		{"\x01", NewKey(KeyCtrlA, ModCtrl), NewKey(KeySOH, ModNone)},
+		{"\x00", NewKey(KeyCtrlSpace, ModCtrl), NewKey(KeyNUL, ModNone)},
+		{"\x08", NewKey(KeyCtrlH, ModCtrl), NewKey(KeyBS, ModNone)},
+		{"\x1c", NewKey(KeyCtrlBackslash, ModCtrl), NewKey(KeyFS, ModNone)},
+		{"\t", NewKey(KeyTAB, ModNone), NewKey(KeyTAB, ModNone)},
+		{"\r", NewKey(KeyCR, ModNone), NewKey(KeyCR, ModNone)},
+		{"\x1b", NewKey(KeyESC, ModNone), NewKey(KeyESC, ModNone)},
+		{"\x7f", NewKey(KeyDEL, ModNone), NewKey(KeyDEL, ModNone)},
+		{"a", 'a', 'a'},
+	}
+
+	ctrl := NewInput(WithCtrlMod())
+	normal := NewInput()
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			k, err := ctrl.ReadKey(strings.NewReader(c.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if k != c.ctrl {
+				t.Errorf("ctrl: want %s, got %s", c.ctrl, k)
+			}
+			if k, err = normal.ReadKey(strings.NewReader(c.in)); err != nil {
+				t.Fatal(err)
+			}
+			if k != c.normal {
+				t.Errorf("normal: want %s, got %s", c.normal, k)
+			}
+		})
+	}
+}
+
+func TestInput_SetMouseEnabled(t *testing.T) {
+	input := NewInput()
+	cases := []struct {
+		on  bool
+		typ KeyType
+	}{
+		{false, KeyESCSeq},
+		{true, KeyMouse},
+		{false, KeyESCSeq},
+	}
+	for _, c := range cases {
+		input.SetMouseEnabled(c.on)
+		if got := input.Supports(FeatureMouseAny); got != c.on {
+			t.Errorf("%t: want supports %t, got %t", c.on, c.on, got)
+		}
+		k, err := input.ReadKey(strings.NewReader("\x1b[<0;10;20M"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k.Type() != c.typ {
+			t.Errorf("%t: want %s, got %s", c.on, c.typ, k.Type())
+		}
+	}
+}
+
+func TestInput_SetPasteEnabled(t *testing.T) {
+	input := NewInput(WithMouse())
+	cases := []struct {
+		on       bool
+		shiftIns KeyType
+		middle   KeyType
+		paste    KeyType
+	}{
+		{false, KeyInsert, KeyMouse, KeyESCSeq},
+		{true, KeyPasteRequest, KeyPasteRequest, KeyPaste},
+		{false, KeyInsert, KeyMouse, KeyESCSeq},
+	}
+	for _, c := range cases {
+		input.SetPasteEnabled(c.on)
+		k, err := input.ReadKey(strings.NewReader("\x1b[200~abc\x1b[201~"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k.Type() != c.paste {
+			t.Errorf("%t: want %s, got %s", c.on, c.paste, k.Type())
+		}
+		k, err = input.ReadKey(strings.NewReader("\x1b[2;2~"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k.Type() != c.shiftIns {
+			t.Errorf("%t: want %s, got %s", c.on, c.shiftIns, k.Type())
+		}
+		k, err = input.ReadKey(strings.NewReader("\x1b[<1;10;20M"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k.Type() != c.middle {
+			t.Errorf("%t: want %s, got %s", c.on, c.middle, k.Type())
+		}
+		// consume the release
+		if _, err := input.ReadKey(strings.NewReader("\x1b[<1;10;20m")); err != nil && err != ErrTimeout {
+			t.Fatal(err)
+		}
+	}
+
+	// disabling ends a paste in progress, the rest is decoded as regular keys
+	input.SetPasteEnabled(true)
+	r := &scriptReader{chunks: []string{"\x1b[200~abc", "", "x"}}
+	if k, err := input.ReadKey(r); err != nil || k.Type() != KeyPaste {
+		t.Fatalf("want KeyPaste, got %s, %v", k, err)
+	}
+	input.SetPasteEnabled(false)
+	if k, err := input.ReadKey(r); err != nil || k != 'x' {
+		t.Fatalf("want 'x', got %s, %v", k, err)
+	}
+}
@@ -1,8 +1,12 @@
 package zzterm
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
+	"unicode"
 )
 
 // Key represents a single key. It contains the key type,
@@ -15,11 +19,13 @@ type Key uint32
 // * if the key is control character or a special key, the sign bit
 //   is set to negative and the first (lower) byte is the Type and
 //   the second byte is the Mod.
-// * otherwise, the (positive) value itself is the rune.
+// * otherwise, the lower 21 bits are the rune and the Mod is in the
+//   bits 24 to 31 (excluding the sign bit).
 //
-// There is no Mod set for a standard rune because generally in a raw
-// mode terminal we cannot tell if Shift or Ctrl or some other modifier
-// key was pressed to generate the rune.
+// There is usually no Mod set for a standard rune because generally in a
+// raw mode terminal we cannot tell if Shift or Ctrl or some other modifier
+// key was pressed to generate the rune. Only the protocols that report it
+// (e.g. xterm's modifyOtherKeys) set the Mod of a rune.
 func keyFromTypeMod(t KeyType, m Mod) Key {
 	k := Key(m) << 8
 	k |= Key(t)
@@ -27,16 +33,42 @@ func keyFromTypeMod(t KeyType, m Mod) Key {
 	return k
 }
 
-// String returns the string representation of k.
-func (k Key) String() string {
-	if k.Type() == KeyRune {
-		return fmt.Sprintf("Key(%#U)", k.Rune())
+const runeMask = 1<<21 - 1
+
+// returns the key of the rune r with the modifier flags m set.
+func keyFromRuneMod(r rune, m Mod) Key {
+	return Key(r)&runeMask | Key(m&0x7f)<<24
+}
+
+// NewRuneKey returns the Key for the rune r pressed with the modifier flags
+// m set, as reported by the protocols that can tell which modifiers were
+// pressed (e.g. Ctrl-i, as opposed to Tab). If m is ModNone, it returns
+// Key(r), as for the keys of type KeyRune read from a terminal that does
+// not report the modifiers.
+func NewRuneKey(r rune, m Mod) Key {
+	return keyFromRuneMod(r, m)
+}
+
+// NewKey returns the Key for the key type t with the modifier flags m set.
+// It is typically used to compare with the keys returned by Input.ReadKey,
+// e.g. to define a Chord. For keys of type KeyRune, convert the rune to a
+// Key instead (i.e. Key(r)), NewKey returns 0 if t is KeyRune.
+func NewKey(t KeyType, m Mod) Key {
+	if t == KeyRune {
+		return 0
 	}
+	return keyFromTypeMod(t, m)
+}
 
+// String returns the string representation of k.
+func (k Key) String() string {
 	flags := k.Mod().String()
 	if flags != "" {
 		flags += " "
 	}
+	if k.Type() == KeyRune {
+		return fmt.Sprintf("Key(%s%#U)", flags, k.Rune())
+	}
 	return fmt.Sprintf("Key(%s%s)", flags, k.Type())
 }
 
@@ -47,7 +79,7 @@ func (k Key) Rune() rune {
 	if r < 0 {
 		return -1
 	}
-	return rune(k)
+	return r & runeMask
 }
 
 // Type returns the KeyType for this key.
@@ -62,11 +94,73 @@ func (k Key) Type() KeyType {
 // Mod returns the key modifier flags set for this key.
 func (k Key) Mod() Mod {
 	if r := rune(k); r >= 0 {
-		return ModNone
+		return Mod(k >> 24)
 	}
 	return Mod((k >> 8) & 0xFF)
 }
 
+// Lower returns the key of the lower case of the rune if k is a key of type
+// KeyRune, e.g. to build case-insensitive key bindings. Otherwise it
+// returns k unchanged. The modifier flags are preserved.
+func (k Key) Lower() Key {
+	if r := rune(k); r >= 0 {
+		return keyFromRuneMod(unicode.ToLower(r&runeMask), k.Mod())
+	}
+	return k
+}
+
+// IsPrintable returns true if k is a key of type KeyRune without modifier
+// flags and the rune is printable as defined by unicode.IsPrint (which
+// includes the ASCII space).
+func (k Key) IsPrintable() bool {
+	r := rune(k)
+	return r >= 0 && r <= runeMask && unicode.IsPrint(r)
+}
+
+// CtrlKeyFor returns the key of the control character sent by the terminal
+// when r is pressed with Ctrl, e.g. the key of type KeyCtrlA for 'a' or 'A',
+// KeyCtrlSpace for ' ' and '@', KeyCtrlLeftSq (ESC) for '[' and KeyDEL for
+// '?'. It returns 0 if there is no control character for r.
+//
+// The key has no modifier flag, as decoded by default. Use
+// Input.CtrlKeyFor to compare with the keys read by an Input with the
+// WithCtrlMod option.
+func CtrlKeyFor(r rune) Key {
+	switch {
+	case r >= 'a' && r <= 'z':
+		return keyFromTypeMod(KeyType(r-'a')+KeyCtrlA, ModNone)
+	case r >= '@' && r <= '_':
+		return keyFromTypeMod(KeyType(r-'@'), ModNone)
+	case r == ' ':
+		return keyFromTypeMod(KeyCtrlSpace, ModNone)
+	case r == '?':
+		return keyFromTypeMod(KeyDEL, ModNone)
+	}
+	return 0
+}
+
+// CtrlRune returns the rune that is pressed with Ctrl to send the control
+// character of k, e.g. 'a' for a key of type KeyCtrlA, ' ' for KeyCtrlSpace,
+// '[' for KeyCtrlLeftSq and '?' for KeyDEL. It returns -1 if k is not a
+// control character key. It is the reverse of CtrlKeyFor, with letters in
+// lower case.
+func (k Key) CtrlRune() rune {
+	if rune(k) >= 0 {
+		return -1
+	}
+	switch t := k.Type(); {
+	case t == KeyCtrlSpace:
+		return ' '
+	case t >= KeyCtrlA && t <= KeyCtrlZ:
+		return rune(t-KeyCtrlA) + 'a'
+	case t <= KeyCtrlUnderscore:
+		return rune(t) + '@'
+	case t == KeyDEL:
+		return '?'
+	}
+	return -1
+}
+
 // Mod represents a key modifier such as pressing alt or ctrl.
 // Detection of such flags is limited.
 type Mod byte
@@ -89,6 +183,75 @@ func (m Mod) String() string {
 	return flags
 }
 
+// returns the names of the modifier flags of m separated by "|", e.g.
+// "Ctrl|Shift", in the same order as String.
+func (m Mod) names() string {
+	var names []string
+	if m&ModCtrl != 0 {
+		names = append(names, "Ctrl")
+	}
+	if m&ModShift != 0 {
+		names = append(names, "Shift")
+	}
+	if m&ModAlt != 0 {
+		names = append(names, "Alt")
+	}
+	if m&ModMeta != 0 {
+		names = append(names, "Meta")
+	}
+	return strings.Join(names, "|")
+}
+
+// ModFromXtermParam returns the modifier flags encoded in the xterm
+// modifier parameter n, as used in the escape sequences of keys pressed
+// with modifiers (e.g. the 5 in ESC [ 1 ; 5 A for Ctrl-Up). The parameter
+// is 1 plus the bitmask of 1 for Shift, 2 for Alt, 4 for Ctrl and 8 for
+// Meta. It returns ModNone if n is less than 2.
+//
+// See https://invisible-island.net/xterm/ctlseqs/ctlseqs.html#h2-PC-Style-Function-Keys
+func ModFromXtermParam(n int) Mod {
+	if n < 2 {
+		return ModNone
+	}
+
+	var m Mod
+	n--
+	if n&1 != 0 {
+		m |= ModShift
+	}
+	if n&2 != 0 {
+		m |= ModAlt
+	}
+	if n&4 != 0 {
+		m |= ModCtrl
+	}
+	if n&8 != 0 {
+		m |= ModMeta
+	}
+	return m
+}
+
+// XtermParam returns the xterm modifier parameter that encodes the
+// modifier flags of m. It is the inverse of ModFromXtermParam, and returns 1
+// for ModNone. Note that the parameter is usually omitted from escape
+// sequences when no modifier is set.
+func (m Mod) XtermParam() int {
+	n := 0
+	if m&ModShift != 0 {
+		n |= 1
+	}
+	if m&ModAlt != 0 {
+		n |= 2
+	}
+	if m&ModCtrl != 0 {
+		n |= 4
+	}
+	if m&ModMeta != 0 {
+		n |= 8
+	}
+	return n + 1
+}
+
 // List of modifier flags. Values of Shift, Meta and Ctrl are the same
 // as for the xterm mouse tracking.
 // See https://invisible-island.net/xterm/ctlseqs/ctlseqs.html#h3-Normal-tracking-mode
@@ -109,7 +272,10 @@ const (
 type MouseEvent struct {
 	buttonID byte
 	pressed  bool
-	x, y     uint16
+	clamped  bool // coordinates were clamped to the limit
+	motion   bool // motion event, as reported with MouseDrag or MouseAny
+	x, y     int32
+	buttons  uint16 // bitmask of buttons held after this event
 }
 
 // String returns the string representation of a mouse event.
@@ -142,12 +308,219 @@ func (m MouseEvent) ButtonPressed() bool {
 	return m.pressed
 }
 
+// Buttons returns the bitmask of all buttons held down after this event,
+// where the bit 1<<(ID-1) is set for each button ID currently pressed. This
+// tracks the press and release events across multiple mouse events, so that
+// e.g. a two-button gesture can be detected. The wheel buttons (IDs 4 to 7)
+// are never considered held as they report no release.
+//
+// Note that if a release happens while mouse tracking is not enabled (e.g.
+// outside the terminal window), the button may be considered held until
+// it is pressed and released again.
+func (m MouseEvent) Buttons() uint16 {
+	return m.buttons
+}
+
+// updates the held buttons bitmask with the press or release of m's button,
+// and stores the resulting bitmask in m.
+func (m *MouseEvent) updateHeld(held *uint16) {
+	if id := m.buttonID; id > 0 && (id < 4 || id > 7) {
+		bit := uint16(1) << (id - 1)
+		if m.pressed {
+			*held |= bit
+		} else {
+			*held &^= bit
+		}
+	}
+	m.buttons = *held
+}
+
 // Coords returns the screen coordinates of the mouse for this event.
 // The upper left character position on the terminal is denoted as 1,1.
+// Coordinates greater than the limit (see WithMouseCoordLimit) are reported
+// as that limit, see Clamped.
 func (m MouseEvent) Coords() (x, y int) {
 	return int(m.x), int(m.y)
 }
 
+// Clamped returns true if a coordinate reported by the terminal for this
+// event was greater than the limit and was clamped to it, so that the
+// application can detect the events with coordinates that are not exact
+// instead of silently using the wrong position.
+func (m MouseEvent) Clamped() bool {
+	return m.clamped
+}
+
+// WheelDirection is the direction of a mouse wheel event.
+type WheelDirection int
+
+// List of mouse wheel directions.
+const (
+	WheelNone  WheelDirection = iota // not a wheel event
+	WheelUp                          // button 4
+	WheelDown                        // button 5
+	WheelLeft                        // button 6
+	WheelRight                       // button 7
+)
+
+var wheelNames = [...]string{
+	WheelNone:  "None",
+	WheelUp:    "Up",
+	WheelDown:  "Down",
+	WheelLeft:  "Left",
+	WheelRight: "Right",
+}
+
+// String returns the name of the wheel direction.
+func (d WheelDirection) String() string {
+	if d >= 0 && int(d) < len(wheelNames) {
+		return wheelNames[d]
+	}
+	return strconv.Itoa(int(d))
+}
+
+// Dragging returns true if m is a motion event with a button held, as
+// reported when the MouseDrag or MouseAny event types are enabled. The
+// ButtonID is then the button held during the motion, and ButtonPressed
+// is true.
+func (m MouseEvent) Dragging() bool {
+	return m.motion && m.buttonID > 0
+}
+
+// IsWheel returns true if m is a mouse wheel (or scroll) event, that is, if
+// its button ID is between 4 and 7. The X11 mouse protocol reports them as
+// the buttons 64 to 67 of the SGR encoding, and only reports their press.
+func (m MouseEvent) IsWheel() bool {
+	return m.buttonID >= 4 && m.buttonID <= 7
+}
+
+// WheelDirection returns the direction of the mouse wheel event, or
+// WheelNone if m is not a wheel event. With WithNaturalScrolling, the
+// direction is the inverted one.
+func (m MouseEvent) WheelDirection() WheelDirection {
+	if !m.IsWheel() {
+		return WheelNone
+	}
+	return WheelDirection(m.buttonID - 3)
+}
+
+// WheelDelta returns the scroll offsets of the mouse wheel event, as the
+// number of steps on the horizontal and vertical axes in the direction of
+// the screen coordinates: dy is -1 for WheelUp and 1 for WheelDown, dx is -1
+// for WheelLeft and 1 for WheelRight. It returns 0, 0 if m is not a wheel
+// event.
+func (m MouseEvent) WheelDelta() (dx, dy int) {
+	switch m.WheelDirection() {
+	case WheelUp:
+		return 0, -1
+	case WheelDown:
+		return 0, 1
+	case WheelLeft:
+		return -1, 0
+	case WheelRight:
+		return 1, 0
+	}
+	return 0, 0
+}
+
+// NewMouseEvent returns a KeyMouse key with the modifier flags mod and the
+// corresponding MouseEvent, as would be returned by Input.ReadKey and
+// Input.Mouse. This is mostly useful for tests and simulators. The button
+// ID btn must be between 0 and 11 (see MouseEvent.ButtonID) and the
+// coordinates are clamped to the range 0-65535, in which case the event is
+// marked as clamped (see MouseEvent.Clamped). Only the Shift, Meta and
+// Ctrl modifiers can be reported for a mouse event, other flags of mod are
+// ignored. The held buttons bitmask of the event only contains the button
+// btn if it is pressed, see MouseEvent.WithButtons to set it.
+func NewMouseEvent(btn int, pressed bool, x, y int, mod Mod) (Key, MouseEvent) {
+	if btn < 0 || btn > 11 {
+		panic(fmt.Sprintf("zzterm: invalid mouse button ID: %d", btn))
+	}
+	m := MouseEvent{buttonID: byte(btn), pressed: pressed}
+	m.setCoords(x, y, defaultCoordLimit)
+	var held uint16
+	m.updateHeld(&held)
+	return keyFromTypeMod(KeyMouse, mod&modMouseEvent), m
+}
+
+// WithButtons returns a copy of m with the held buttons bitmask set to
+// buttons (see MouseEvent.Buttons).
+func (m MouseEvent) WithButtons(buttons uint16) MouseEvent {
+	m.buttons = buttons
+	return m
+}
+
+// WithMotion returns a copy of m marked as a motion event if motion is
+// true, as reported when the MouseDrag or MouseAny event types are enabled
+// (see MouseEvent.Dragging).
+func (m MouseEvent) WithMotion(motion bool) MouseEvent {
+	m.motion = motion
+	return m
+}
+
+// default limit of the mouse coordinates, see WithMouseCoordLimit.
+const defaultCoordLimit = 1<<16 - 1
+
+// sets the coordinates of m to x and y clamped to the range 0-limit, and
+// marks m as clamped if one of them is out of that range.
+func (m *MouseEvent) setCoords(x, y, limit int) {
+	m.x, m.clamped = clampCoord(x, limit)
+	var c bool
+	m.y, c = clampCoord(y, limit)
+	m.clamped = m.clamped || c
+}
+
+func clampCoord(v, limit int) (int32, bool) {
+	if v < 0 {
+		return 0, true
+	}
+	if v > limit {
+		return int32(limit), true
+	}
+	return int32(v), false
+}
+
+// JSON representation of a MouseEvent.
+type mouseEventJSON struct {
+	Button  int    `json:"button"`
+	Pressed bool   `json:"pressed"`
+	X       int    `json:"x"`
+	Y       int    `json:"y"`
+	Buttons uint16 `json:"buttons"`
+	Clamped bool   `json:"clamped,omitempty"`
+	Motion  bool   `json:"motion,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface for the mouse event.
+func (m MouseEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(mouseEventJSON{
+		Button:  int(m.buttonID),
+		Pressed: m.pressed,
+		X:       int(m.x),
+		Y:       int(m.y),
+		Buttons: m.buttons,
+		Clamped: m.clamped,
+		Motion:  m.motion,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for the mouse
+// event.
+func (m *MouseEvent) UnmarshalJSON(b []byte) error {
+	var v mouseEventJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	if v.Button < 0 || v.Button > 11 {
+		return fmt.Errorf("zzterm: invalid mouse button ID: %d", v.Button)
+	}
+	if v.X < 0 || v.X > math.MaxInt32 || v.Y < 0 || v.Y > math.MaxInt32 {
+		return fmt.Errorf("zzterm: invalid mouse coordinates: %d,%d", v.X, v.Y)
+	}
+	*m = MouseEvent{buttonID: byte(v.Button), pressed: v.Pressed, clamped: v.Clamped, motion: v.Motion, x: int32(v.X), y: int32(v.Y), buttons: v.Buttons}
+	return nil
+}
+
 // KeyType represents the type of key.
 type KeyType byte
 
@@ -277,11 +650,49 @@ const (
 	KeyESCSeq
 	KeyMouse
 	KeyFocusIn
-	KeyFocusOut // 116
+	KeyFocusOut
+	KeyOSC          // 117
+	KeyPasteRequest // 118
+	KeyESCSeqStream // 119
+	KeyCSIUnknown   // 120
+	KeyOSCUnknown   // 121
+	KeyDCSUnknown   // 122
+	KeyTermParams   // 123
+	KeyPaste        // 124
+	KeyKitty        // 125
+	KeyDeviceAttrs  // 126
 
 	KeyDEL KeyType = 127
 )
 
+// List of the key types of the numeric keypad, reported when the keypad is
+// in application mode (see SeqEnableKeypadApp).
+const (
+	KeyKP0 KeyType = iota + 128
+	KeyKP1
+	KeyKP2
+	KeyKP3
+	KeyKP4
+	KeyKP5
+	KeyKP6
+	KeyKP7
+	KeyKP8
+	KeyKP9
+	KeyKPEnter
+	KeyKPPlus
+	KeyKPComma
+	KeyKPMinus
+	KeyKPDecimal
+	KeyKPDivide
+	KeyKPMultiply // 144
+)
+
+// List of the key types of the events detected by the Input from the keys
+// read.
+const (
+	KeyLongPress KeyType = iota + 145 // see WithLongPress
+)
+
 // List of some aliases to the key types. The KeyCtrl... constants
 // match the ASCII keys at the same position (e.g. KeyCtrlSpace is
 // KeyNUL, KeyCtrlLeftSq is KeyESC, etc.).
@@ -325,121 +736,150 @@ const (
 )
 
 var keyNames = [...]string{
-	KeyNUL:      "NUL",
-	KeySOH:      "SOH",
-	KeySTX:      "STX",
-	KeyETX:      "ETX",
-	KeyEOT:      "EOT",
-	KeyENQ:      "ENQ",
-	KeyACK:      "ACK",
-	KeyBEL:      "BEL",
-	KeyBS:       "BS",
-	KeyTAB:      "TAB",
-	KeyLF:       "LF",
-	KeyVT:       "VT",
-	KeyFF:       "FF",
-	KeyCR:       "CR",
-	KeySO:       "SO",
-	KeySI:       "SI",
-	KeyDLE:      "DLE",
-	KeyDC1:      "DC1",
-	KeyDC2:      "DC2",
-	KeyDC3:      "DC3",
-	KeyDC4:      "DC4",
-	KeyNAK:      "NAK",
-	KeySYN:      "SYN",
-	KeyETB:      "ETB",
-	KeyCAN:      "CAN",
-	KeyEM:       "EM",
-	KeySUB:      "SUB",
-	KeyESC:      "ESC",
-	KeyFS:       "FS",
-	KeyGS:       "GS",
-	KeyRS:       "RS",
-	KeyUS:       "US",
-	KeyLeft:     "Left",
-	KeyRight:    "Right",
-	KeyUp:       "Up",
-	KeyDown:     "Down",
-	KeyInsert:   "Insert",
-	KeyBacktab:  "Backtab",
-	KeyDelete:   "Delete",
-	KeyHome:     "Home",
-	KeyEnd:      "End",
-	KeyPgUp:     "PgUp",
-	KeyPgDn:     "PgDn",
-	KeyF1:       "F1",
-	KeyF2:       "F2",
-	KeyF3:       "F3",
-	KeyF4:       "F4",
-	KeyF5:       "F5",
-	KeyF6:       "F6",
-	KeyF7:       "F7",
-	KeyF8:       "F8",
-	KeyF9:       "F9",
-	KeyF10:      "F10",
-	KeyF11:      "F11",
-	KeyF12:      "F12",
-	KeyF13:      "F13",
-	KeyF14:      "F14",
-	KeyF15:      "F15",
-	KeyF16:      "F16",
-	KeyF17:      "F17",
-	KeyF18:      "F18",
-	KeyF19:      "F19",
-	KeyF20:      "F20",
-	KeyF21:      "F21",
-	KeyF22:      "F22",
-	KeyF23:      "F23",
-	KeyF24:      "F24",
-	KeyF25:      "F25",
-	KeyF26:      "F26",
-	KeyF27:      "F27",
-	KeyF28:      "F28",
-	KeyF29:      "F29",
-	KeyF30:      "F30",
-	KeyF31:      "F31",
-	KeyF32:      "F32",
-	KeyF33:      "F33",
-	KeyF34:      "F34",
-	KeyF35:      "F35",
-	KeyF36:      "F36",
-	KeyF37:      "F37",
-	KeyF38:      "F38",
-	KeyF39:      "F39",
-	KeyF40:      "F40",
-	KeyF41:      "F41",
-	KeyF42:      "F42",
-	KeyF43:      "F43",
-	KeyF44:      "F44",
-	KeyF45:      "F45",
-	KeyF46:      "F46",
-	KeyF47:      "F47",
-	KeyF48:      "F48",
-	KeyF49:      "F49",
-	KeyF50:      "F50",
-	KeyF51:      "F51",
-	KeyF52:      "F52",
-	KeyF53:      "F53",
-	KeyF54:      "F54",
-	KeyF55:      "F55",
-	KeyF56:      "F56",
-	KeyF57:      "F57",
-	KeyF58:      "F58",
-	KeyF59:      "F59",
-	KeyF60:      "F60",
-	KeyF61:      "F61",
-	KeyF62:      "F62",
-	KeyF63:      "F63",
-	KeyF64:      "F64",
-	KeyHelp:     "Help",
-	KeyExit:     "Exit",
-	KeyClear:    "Clear",
-	KeyCancel:   "Cancel",
-	KeyPrint:    "Print",
-	KeyESCSeq:   "ESCSeq",
-	KeyMouse:    "Mouse",
-	KeyFocusIn:  "FocusIn",
-	KeyFocusOut: "FocusOut",
-	KeyDEL:      "DEL",
+	KeyNUL:          "NUL",
+	KeySOH:          "SOH",
+	KeySTX:          "STX",
+	KeyETX:          "ETX",
+	KeyEOT:          "EOT",
+	KeyENQ:          "ENQ",
+	KeyACK:          "ACK",
+	KeyBEL:          "BEL",
+	KeyBS:           "BS",
+	KeyTAB:          "TAB",
+	KeyLF:           "LF",
+	KeyVT:           "VT",
+	KeyFF:           "FF",
+	KeyCR:           "CR",
+	KeySO:           "SO",
+	KeySI:           "SI",
+	KeyDLE:          "DLE",
+	KeyDC1:          "DC1",
+	KeyDC2:          "DC2",
+	KeyDC3:          "DC3",
+	KeyDC4:          "DC4",
+	KeyNAK:          "NAK",
+	KeySYN:          "SYN",
+	KeyETB:          "ETB",
+	KeyCAN:          "CAN",
+	KeyEM:           "EM",
+	KeySUB:          "SUB",
+	KeyESC:          "ESC",
+	KeyFS:           "FS",
+	KeyGS:           "GS",
+	KeyRS:           "RS",
+	KeyUS:           "US",
+	KeyRune:         "Rune",
+	KeyLeft:         "Left",
+	KeyRight:        "Right",
+	KeyUp:           "Up",
+	KeyDown:         "Down",
+	KeyInsert:       "Insert",
+	KeyBacktab:      "Backtab",
+	KeyDelete:       "Delete",
+	KeyHome:         "Home",
+	KeyEnd:          "End",
+	KeyPgUp:         "PgUp",
+	KeyPgDn:         "PgDn",
+	KeyF1:           "F1",
+	KeyF2:           "F2",
+	KeyF3:           "F3",
+	KeyF4:           "F4",
+	KeyF5:           "F5",
+	KeyF6:           "F6",
+	KeyF7:           "F7",
+	KeyF8:           "F8",
+	KeyF9:           "F9",
+	KeyF10:          "F10",
+	KeyF11:          "F11",
+	KeyF12:          "F12",
+	KeyF13:          "F13",
+	KeyF14:          "F14",
+	KeyF15:          "F15",
+	KeyF16:          "F16",
+	KeyF17:          "F17",
+	KeyF18:          "F18",
+	KeyF19:          "F19",
+	KeyF20:          "F20",
+	KeyF21:          "F21",
+	KeyF22:          "F22",
+	KeyF23:          "F23",
+	KeyF24:          "F24",
+	KeyF25:          "F25",
+	KeyF26:          "F26",
+	KeyF27:          "F27",
+	KeyF28:          "F28",
+	KeyF29:          "F29",
+	KeyF30:          "F30",
+	KeyF31:          "F31",
+	KeyF32:          "F32",
+	KeyF33:          "F33",
+	KeyF34:          "F34",
+	KeyF35:          "F35",
+	KeyF36:          "F36",
+	KeyF37:          "F37",
+	KeyF38:          "F38",
+	KeyF39:          "F39",
+	KeyF40:          "F40",
+	KeyF41:          "F41",
+	KeyF42:          "F42",
+	KeyF43:          "F43",
+	KeyF44:          "F44",
+	KeyF45:          "F45",
+	KeyF46:          "F46",
+	KeyF47:          "F47",
+	KeyF48:          "F48",
+	KeyF49:          "F49",
+	KeyF50:          "F50",
+	KeyF51:          "F51",
+	KeyF52:          "F52",
+	KeyF53:          "F53",
+	KeyF54:          "F54",
+	KeyF55:          "F55",
+	KeyF56:          "F56",
+	KeyF57:          "F57",
+	KeyF58:          "F58",
+	KeyF59:          "F59",
+	KeyF60:          "F60",
+	KeyF61:          "F61",
+	KeyF62:          "F62",
+	KeyF63:          "F63",
+	KeyF64:          "F64",
+	KeyHelp:         "Help",
+	KeyExit:         "Exit",
+	KeyClear:        "Clear",
+	KeyCancel:       "Cancel",
+	KeyPrint:        "Print",
+	KeyESCSeq:       "ESCSeq",
+	KeyMouse:        "Mouse",
+	KeyFocusIn:      "FocusIn",
+	KeyFocusOut:     "FocusOut",
+	KeyOSC:          "OSC",
+	KeyPasteRequest: "PasteRequest",
+	KeyESCSeqStream: "ESCSeqStream",
+	KeyCSIUnknown:   "CSIUnknown",
+	KeyOSCUnknown:   "OSCUnknown",
+	KeyDCSUnknown:   "DCSUnknown",
+	KeyTermParams:   "TermParams",
+	KeyPaste:        "Paste",
+	KeyKitty:        "Kitty",
+	KeyDeviceAttrs:  "DeviceAttributes",
+	KeyDEL:          "DEL",
+	KeyKP0:          "KP0",
+	KeyKP1:          "KP1",
+	KeyKP2:          "KP2",
+	KeyKP3:          "KP3",
+	KeyKP4:          "KP4",
+	KeyKP5:          "KP5",
+	KeyKP6:          "KP6",
+	KeyKP7:          "KP7",
+	KeyKP8:          "KP8",
+	KeyKP9:          "KP9",
+	KeyKPEnter:      "KPEnter",
+	KeyKPPlus:       "KPPlus",
+	KeyKPComma:      "KPComma",
+	KeyKPMinus:      "KPMinus",
+	KeyKPDecimal:    "KPDecimal",
+	KeyKPDivide:     "KPDivide",
+	KeyKPMultiply:   "KPMultiply",
+	KeyLongPress:    "LongPress",
 }
@@ -1,6 +1,11 @@
 package zzterm
 
-import "testing"
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode"
+)
 
 func TestKey_String(t *testing.T) {
 	cases := []struct {
@@ -16,6 +21,8 @@ func TestKey_String(t *testing.T) {
 		{keyFromTypeMod(KeyHome, ModCtrl|ModShift), `Key(⌃⇧ Home)`},
 		{keyFromTypeMod(KeyLeft, ModAlt), `Key(⎇ Left)`},
 		{keyFromTypeMod(KeyLeft, ModMeta), `Key(⌥ Left)`},
+		{NewRuneKey('i', ModCtrl), `Key(⌃ U+0069 'i')`},
+		{NewRuneKey('👪', ModCtrl|ModAlt|ModMeta|ModShift), `Key(⌃⇧⎇⌥ U+1F46A '👪')`},
 	}
 	for _, c := range cases {
 		t.Run(c.key.String(), func(t *testing.T) {
@@ -26,3 +33,259 @@ func TestKey_String(t *testing.T) {
 		})
 	}
 }
+
+func TestNewRuneKey(t *testing.T) {
+	mods := []Mod{ModNone, ModCtrl, ModAlt | ModShift, ModCtrl | ModAlt | ModShift | ModMeta}
+	runes := []rune{0, 'a', 'é', '👪', unicode.MaxRune}
+	for _, m := range mods {
+		for _, r := range runes {
+			k := NewRuneKey(r, m)
+			if k.Type() != KeyRune || k.Rune() != r || k.Mod() != m {
+				t.Errorf("%U %s: got %s %U %s", r, m, k.Type(), k.Rune(), k.Mod())
+			}
+		}
+	}
+	if k := NewRuneKey('a', ModNone); k != 'a' {
+		t.Errorf("want 'a', got %s", k)
+	}
+}
+
+func TestMod_XtermParam(t *testing.T) {
+	cases := []struct {
+		n int
+		m Mod
+	}{
+		{1, ModNone},
+		{2, ModShift},
+		{3, ModAlt},
+		{4, ModAlt | ModShift},
+		{5, ModCtrl},
+		{6, ModCtrl | ModShift},
+		{7, ModCtrl | ModAlt},
+		{8, ModCtrl | ModAlt | ModShift},
+		{9, ModMeta},
+		{16, ModMeta | ModCtrl | ModAlt | ModShift},
+	}
+	for _, c := range cases {
+		if got := ModFromXtermParam(c.n); got != c.m {
+			t.Errorf("ModFromXtermParam(%d): want %s, got %s", c.n, c.m, got)
+		}
+		if got := c.m.XtermParam(); got != c.n {
+			t.Errorf("%s.XtermParam(): want %d, got %d", c.m, c.n, got)
+		}
+	}
+	if got := ModFromXtermParam(0); got != ModNone {
+		t.Errorf("ModFromXtermParam(0): want none, got %s", got)
+	}
+}
+
+func TestNewMouseEvent(t *testing.T) {
+	k, m := NewMouseEvent(1, true, 10, 70000, ModCtrl|ModAlt)
+	if k.Type() != KeyMouse || k.Mod() != ModCtrl {
+		t.Errorf("want KeyMouse with ModCtrl, got %s", k)
+	}
+	if x, y := m.Coords(); x != 10 || y != 65535 {
+		t.Errorf("want coords 10,65535, got %d,%d", x, y)
+	}
+	if m.ButtonID() != 1 || !m.ButtonPressed() || m.Buttons() != 1 {
+		t.Errorf("want pressed button 1 held, got %s (%b)", m, m.Buttons())
+	}
+
+	_, m = NewMouseEvent(4, true, 1, 1, ModNone)
+	if m.Buttons() != 0 {
+		t.Errorf("want no button held for wheel, got %b", m.Buttons())
+	}
+	if m = m.WithButtons(0b11); m.Buttons() != 0b11 {
+		t.Errorf("want buttons %b, got %b", 0b11, m.Buttons())
+	}
+
+	_, m = NewMouseEvent(1, true, 1, 1, ModNone)
+	if m.Dragging() {
+		t.Errorf("want no drag, got %s", m)
+	}
+	if m = m.WithMotion(true); !m.Dragging() {
+		t.Errorf("want drag, got %s", m)
+	}
+}
+
+func TestMouseEvent_Wheel(t *testing.T) {
+	cases := []struct {
+		btn    int
+		dir    WheelDirection
+		dx, dy int
+	}{
+		{0, WheelNone, 0, 0},
+		{1, WheelNone, 0, 0},
+		{3, WheelNone, 0, 0},
+		{4, WheelUp, 0, -1},
+		{5, WheelDown, 0, 1},
+		{6, WheelLeft, -1, 0},
+		{7, WheelRight, 1, 0},
+		{8, WheelNone, 0, 0},
+	}
+	for _, c := range cases {
+		_, m := NewMouseEvent(c.btn, true, 1, 1, ModNone)
+		if m.IsWheel() != (c.dir != WheelNone) {
+			t.Errorf("%d: want wheel %t, got %t", c.btn, c.dir != WheelNone, m.IsWheel())
+		}
+		if d := m.WheelDirection(); d != c.dir {
+			t.Errorf("%d: want direction %s, got %s", c.btn, c.dir, d)
+		}
+		if dx, dy := m.WheelDelta(); dx != c.dx || dy != c.dy {
+			t.Errorf("%d: want delta %d,%d, got %d,%d", c.btn, c.dx, c.dy, dx, dy)
+		}
+	}
+	if s := WheelDirection(9).String(); s != "9" {
+		t.Errorf("want 9, got %s", s)
+	}
+}
+
+func TestMouseEvent_JSON(t *testing.T) {
+	_, m := NewMouseEvent(3, false, 12, 34, ModNone)
+	m = m.WithButtons(1)
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"button":3,"pressed":false,"x":12,"y":34,"buttons":1}`
+	if string(b) != want {
+		t.Errorf("want %s, got %s", want, b)
+	}
+
+	var got MouseEvent
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != m {
+		t.Errorf("want %v, got %v", m, got)
+	}
+
+	for _, s := range []string{
+		`{"button":12}`,
+		`{"button":-1}`,
+		`{"x":2147483648}`,
+		`{"y":-1}`,
+	} {
+		if err := json.Unmarshal([]byte(s), &got); err == nil {
+			t.Errorf("%s: want error, got none", s)
+		}
+	}
+}
+
+func TestKey_Lower(t *testing.T) {
+	cases := []struct {
+		k, want Key
+	}{
+		{'a', 'a'},
+		{'A', 'a'},
+		{'É', 'é'},
+		{'1', '1'},
+		{NewKey(KeyUp, ModShift), NewKey(KeyUp, ModShift)},
+		{NewKey(KeyCtrlA, ModNone), NewKey(KeyCtrlA, ModNone)},
+		{NewRuneKey('A', ModCtrl), NewRuneKey('a', ModCtrl)},
+	}
+	for _, c := range cases {
+		if got := c.k.Lower(); got != c.want {
+			t.Errorf("%s: want %s, got %s", c.k, c.want, got)
+		}
+	}
+}
+
+func TestKey_IsPrintable(t *testing.T) {
+	cases := []struct {
+		k    Key
+		want bool
+	}{
+		{'a', true},
+		{' ', true},
+		{'😿', true},
+		{'\u200b', false},
+		{'\u009b', false},
+		{NewKey(KeyTAB, ModNone), false},
+		{NewKey(KeyF1, ModNone), false},
+		{NewRuneKey('a', ModCtrl), false},
+	}
+	for _, c := range cases {
+		if got := c.k.IsPrintable(); got != c.want {
+			t.Errorf("%s: want %t, got %t", c.k, c.want, got)
+		}
+	}
+}
+
+func TestKey_CtrlRune(t *testing.T) {
+	cases := []struct {
+		k    Key
+		want rune
+	}{
+		{NewKey(KeyCtrlA, ModNone), 'a'},
+		{NewKey(KeyCtrlZ, ModCtrl), 'z'},
+		{NewKey(KeyCtrlSpace, ModNone), ' '},
+		{NewKey(KeyCtrlLeftSq, ModNone), '['},
+		{NewKey(KeyCtrlUnderscore, ModNone), '_'},
+		{NewKey(KeyDEL, ModNone), '?'},
+		{NewKey(KeyUp, ModCtrl), -1},
+		{'a', -1},
+		{NewRuneKey('a', ModCtrl), -1},
+	}
+	for _, c := range cases {
+		if got := c.k.CtrlRune(); got != c.want {
+			t.Errorf("%s: want %q, got %q", c.k, c.want, got)
+		}
+		if c.want >= 0 && CtrlKeyFor(c.want) != NewKey(c.k.Type(), ModNone) {
+			t.Errorf("%s: CtrlKeyFor(%q) does not match", c.k, c.want)
+		}
+	}
+}
+
+func TestCtrlKeyFor(t *testing.T) {
+	cases := []struct {
+		r    rune
+		want Key
+	}{
+		{'a', NewKey(KeyCtrlA, ModNone)},
+		{'A', NewKey(KeyCtrlA, ModNone)},
+		{'z', NewKey(KeyCtrlZ, ModNone)},
+		{'x', NewKey(KeyCtrlX, ModNone)},
+		{' ', NewKey(KeyNUL, ModNone)},
+		{'@', NewKey(KeyNUL, ModNone)},
+		{'[', NewKey(KeyESC, ModNone)},
+		{'\\', NewKey(KeyCtrlBackslash, ModNone)},
+		{'_', NewKey(KeyCtrlUnderscore, ModNone)},
+		{'?', NewKey(KeyDEL, ModNone)},
+		{'1', 0},
+		{'é', 0},
+	}
+	for _, c := range cases {
+		if got := CtrlKeyFor(c.r); got != c.want {
+			t.Errorf("%q: want %s, got %s", c.r, c.want, got)
+		}
+	}
+
+	// matches the decoded control characters
+	input := NewInput()
+	k, err := input.ReadKey(strings.NewReader("\x13"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := CtrlKeyFor('s'); k != want || input.CtrlKeyFor('s') != want {
+		t.Errorf("want %s, got %s", want, k)
+	}
+
+	// and with the WithCtrlMod option, via the Input
+	input = NewInput(WithCtrlMod())
+	for _, in := range []string{"\x13", "\x00", "\x1d", "\r", "\x7f"} {
+		k, err := input.ReadKey(strings.NewReader(in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := input.CtrlKeyFor(k.CtrlRune()); k != want {
+			t.Errorf("%q: want %s, got %s", in, want, k)
+		}
+	}
+	if k := input.CtrlKeyFor('s'); k != NewKey(KeyCtrlS, ModCtrl) {
+		t.Errorf("want Ctrl+S with ModCtrl, got %s", k)
+	}
+	if k := input.CtrlKeyFor('1'); k != 0 {
+		t.Errorf("want 0, got %s", k)
+	}
+}
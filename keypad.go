@@ -0,0 +1,26 @@
+package zzterm
+
+// List of the control sequences to switch the numeric keypad to the
+// application mode (DECKPAM), in which the keypad keys are reported as SS3
+// sequences (decoded as the KeyKP... key types), and back to the numeric
+// mode (DECKPNM), in which they send the same characters as the main keys.
+const (
+	SeqEnableKeypadApp  = "\x1b="
+	SeqDisableKeypadApp = "\x1b>"
+)
+
+// final bytes of the SS3 sequences sent by the keypad keys in application
+// mode, as documented for xterm and the VT100.
+var keypadSS3 = map[byte]KeyType{
+	'p': KeyKP0, 'q': KeyKP1, 'r': KeyKP2, 's': KeyKP3, 't': KeyKP4,
+	'u': KeyKP5, 'v': KeyKP6, 'w': KeyKP7, 'x': KeyKP8, 'y': KeyKP9,
+	'M': KeyKPEnter, 'k': KeyKPPlus, 'l': KeyKPComma, 'm': KeyKPMinus,
+	'n': KeyKPDecimal, 'o': KeyKPDivide, 'j': KeyKPMultiply,
+}
+
+// adds the escape sequences of the keypad keys in application mode.
+func addKeypadESCSeq(m map[string]Key) {
+	for c, typ := range keypadSS3 {
+		m["\x1bO"+string(c)] = keyFromTypeMod(typ, ModNone)
+	}
+}
@@ -0,0 +1,35 @@
+package zzterm
+
+import "testing"
+
+func TestInput_ReadKey_Keypad(t *testing.T) {
+	cases := []testcase{
+		{"\x1bOp", -1, KeyKP0, ModNone},
+		{"\x1bOt", -1, KeyKP4, ModNone},
+		{"\x1bOy", -1, KeyKP9, ModNone},
+		{"\x1bOM", -1, KeyKPEnter, ModNone},
+		{"\x1bOk", -1, KeyKPPlus, ModNone},
+		{"\x1bOl", -1, KeyKPComma, ModNone},
+		{"\x1bOm", -1, KeyKPMinus, ModNone},
+		{"\x1bOn", -1, KeyKPDecimal, ModNone},
+		{"\x1bOo", -1, KeyKPDivide, ModNone},
+		{"\x1bOj", -1, KeyKPMultiply, ModNone},
+		{"\x1bOP", -1, KeyF1, ModNone},
+		{"\x1bOz", -1, KeyESCSeq, ModNone},
+	}
+
+	input := NewInput()
+	for _, c := range cases {
+		runTestcase(t, c, input)
+	}
+	input = NewInput(WithProfile(ProfileWezTerm))
+	runTestcase(t, testcase{"\x1bOp", -1, KeyKP0, ModNone}, input)
+}
+
+func TestKeyType_String_Keypad(t *testing.T) {
+	for typ := KeyKP0; typ <= KeyKPMultiply; typ++ {
+		if s := typ.String(); len(s) < 3 || s[:2] != "KP" {
+			t.Errorf("%d: want KP name, got %s", typ, s)
+		}
+	}
+}
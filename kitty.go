@@ -0,0 +1,285 @@
+package zzterm
+
+import (
+	"fmt"
+	"io"
+	"unicode"
+)
+
+// KittyFlags are the progressive enhancement flags of the kitty keyboard
+// protocol, see https://sw.kovidgoyal.net/kitty/keyboard-protocol/
+type KittyFlags int
+
+// List of kitty keyboard protocol enhancement flags.
+const (
+	KittyDisambiguate     KittyFlags = 1 << iota // disambiguate escape codes
+	KittyReportEvents                            // report key repeat and release events
+	KittyReportAlternates                        // report the shifted and base layout keys
+	KittyReportAllKeys                           // report all keys as escape codes
+	KittyReportText                              // report the associated text
+)
+
+// EnableKittyKeyboard pushes the kitty keyboard protocol enhancement flags
+// on the stack of the terminal represented by w ("ESC [ > flags u"). The
+// keys are then decoded by an Input created with the WithKitty option.
+func EnableKittyKeyboard(w io.Writer, flags KittyFlags) error {
+	_, err := fmt.Fprintf(w, "\x1b[>%du", flags)
+	return err
+}
+
+// DisableKittyKeyboard pops the kitty keyboard protocol enhancement flags
+// pushed by EnableKittyKeyboard from the stack of the terminal represented
+// by w ("ESC [ < u").
+func DisableKittyKeyboard(w io.Writer) error {
+	_, err := io.WriteString(w, "\x1b[<u")
+	return err
+}
+
+// KeyAction is the action of a key reported by the kitty keyboard protocol.
+type KeyAction byte
+
+// List of key actions. The repeat and release actions are only reported
+// if the KittyReportEvents flag is enabled.
+const (
+	ActionPress KeyAction = iota + 1
+	ActionRepeat
+	ActionRelease
+)
+
+var keyActionNames = [...]string{
+	ActionPress:   "Press",
+	ActionRepeat:  "Repeat",
+	ActionRelease: "Release",
+}
+
+// String returns the name of the action.
+func (a KeyAction) String() string {
+	if a > 0 && int(a) < len(keyActionNames) {
+		return keyActionNames[a]
+	}
+	return fmt.Sprintf("KeyAction(%d)", int(a))
+}
+
+// KittyKey is a key decoded from the kitty keyboard protocol, as returned
+// by Input.Kitty.
+type KittyKey struct {
+	// Code is the unicode code point of the key (the unshifted key, e.g.
+	// 'a' for Shift-a) or the kitty code of a functional key (e.g. 57441
+	// for the left Shift key), or 0 for the legacy functional keys reported
+	// with a final byte other than 'u' (e.g. the arrows).
+	Code rune
+	// Shifted and Base are the shifted key and the key in the standard
+	// layout, if reported (see KittyReportAlternates), 0 otherwise.
+	Shifted rune
+	Base    rune
+	// Text is the text of the key, if reported (see KittyReportText) and
+	// made of a single rune, 0 otherwise.
+	Text rune
+
+	Mod    Mod
+	Action KeyAction
+}
+
+// WithKitty enables decoding of the keys reported by the kitty keyboard
+// protocol ("ESC [ code ; mods u" and the legacy functional keys with
+// modifiers and actions, e.g. "ESC [ 1 ; 5 : 3 A" for the release of
+// Ctrl-Up), as supported by kitty, foot, WezTerm and Ghostty when the
+// enhancement flags are enabled (see EnableKittyKeyboard).
+//
+// The keys are returned as the corresponding Key with all the modifier
+// flags, including the key releases if reported. The runes are returned as
+// keys of type KeyRune with the modifier flags set (see NewRuneKey), so that
+// e.g. Ctrl-i can be told apart from Tab, and the runes pressed with Shift
+// as the shifted rune without ModShift if it can be determined. The
+// functional keys that have no KeyType (e.g. the modifier keys themselves)
+// are returned as a key of type KeyKitty with the modifier flags. Call
+// Input.Kitty to get the details of the key, including its action, before
+// the next call to Input.ReadKey.
+func WithKitty() Option {
+	return func(i *Input) {
+		i.kittyOn = true
+	}
+}
+
+// WithLayoutNormalization normalizes the runes of the keys decoded with the
+// WithKitty option for the international keyboard layouts, based on the
+// alternate keys and the text reported by the terminal (see
+// KittyReportAlternates and KittyReportText):
+//
+//   - a rune composed with AltGr or a dead key, reported with its text, is
+//     returned as that text without the ModAlt and ModShift flags (and
+//     without ModCtrl if reported with ModAlt, as AltGr is reported as
+//     Ctrl+Alt on some systems), e.g. AltGr+q on a German layout as '@';
+//   - a rune pressed with Ctrl, Alt or Meta, reported without text, is
+//     returned as the key of the standard (US) layout if reported, e.g.
+//     Ctrl+ф on a Russian layout as Ctrl+a, so that the shortcuts work
+//     whatever the layout.
+//
+// Input.Kitty still returns the details of the key as reported.
+func WithLayoutNormalization() Option {
+	return func(i *Input) {
+		i.normKey = true
+	}
+}
+
+// Kitty returns the kitty keyboard protocol details of the last key read,
+// if it was decoded from the kitty keyboard protocol. Otherwise it returns
+// the zero value.
+func (i *Input) Kitty() KittyKey {
+	return i.kkey
+}
+
+// kitty codes of the functional keys that have a KeyType.
+var kittyFuncKeys = map[rune]KeyType{
+	27: KeyESC, 13: KeyCR, 9: KeyTAB, 127: KeyDEL,
+	57414: KeyCR, // KP_ENTER
+	57417: KeyLeft, 57418: KeyRight, 57419: KeyUp, 57420: KeyDown,
+	57421: KeyPgUp, 57422: KeyPgDn, 57423: KeyHome, 57424: KeyEnd,
+	57425: KeyInsert, 57426: KeyDelete,
+}
+
+// kitty codes of the keypad keys that produce a rune, starting at KP_0
+// (57399): KP_0 to KP_9, KP_DECIMAL, KP_DIVIDE, KP_MULTIPLY, KP_SUBTRACT,
+// KP_ADD, KP_ENTER (not a rune), KP_EQUAL and KP_SEPARATOR.
+var kittyKeypadRunes = [...]rune{
+	'0', '1', '2', '3', '4', '5', '6', '7', '8', '9',
+	'.', '/', '*', '-', '+', 0, '=', ',',
+}
+
+// xterm codes of the functional keys reported as CSI code ~, in addition
+// to the navigation keys.
+var kittyTildeKeys = map[int]KeyType{
+	7: KeyHome, 8: KeyEnd, 11: KeyF1, 12: KeyF2, 13: KeyF3, 14: KeyF4,
+	15: KeyF5, 17: KeyF6, 18: KeyF7, 19: KeyF8, 20: KeyF9, 21: KeyF10,
+	23: KeyF11, 24: KeyF12,
+}
+
+// returns the modifier flags encoded in the kitty modifier parameter n,
+// which is 1 plus the bitmask of 1 for Shift, 2 for Alt, 4 for Ctrl, 8 for
+// Super and 32 for Meta (both reported as ModMeta). The Hyper and lock
+// modifiers are ignored.
+func kittyMod(n int) Mod {
+	if n < 2 {
+		return ModNone
+	}
+	m := ModFromXtermParam((n-1)&0xf + 1)
+	if (n-1)&32 != 0 {
+		m |= ModMeta
+	}
+	return m
+}
+
+// returns the key decoded from the kitty keyboard protocol, or a KeyESCSeq
+// if the buffer does not start with such a key. If it returns another key,
+// i.sz is set to the length of the sequence and i.kkey to its details.
+func (i *Input) decodeKitty() Key {
+	seq, n := ParseCSI(i.buf[:i.len])
+	if n == 0 || seq.Prefix != 0 || seq.Intermediate != 0 || seq.NumParams() > 3 {
+		return keyFromTypeMod(KeyESCSeq, ModNone)
+	}
+
+	kk := KittyKey{
+		Mod:    kittyMod(seq.ParamOr(1, 1)),
+		Action: KeyAction(seq.SubParam(1, 0)),
+	}
+	if kk.Action < ActionPress || kk.Action > ActionRelease {
+		kk.Action = ActionPress
+	}
+
+	var t KeyType
+	var ok bool
+	switch seq.Final {
+	case 'u':
+		if seq.NumParams() == 0 {
+			return keyFromTypeMod(KeyESCSeq, ModNone)
+		}
+		kk.Code = rune(seq.Param(0))
+		kk.Shifted = rune(seq.SubParam(0, 0))
+		kk.Base = rune(seq.SubParam(0, 1))
+		if seq.NumParams() > 2 && seq.NumSubParams(2) == 0 {
+			kk.Text = rune(seq.Param(2))
+		}
+		for _, r := range []*rune{&kk.Code, &kk.Shifted, &kk.Base, &kk.Text} {
+			if *r < 0 || *r > unicode.MaxRune {
+				*r = 0
+			}
+		}
+		i.kkey = kk
+		i.sz = n
+		k := kittyRuneKey(kk)
+		if i.normKey && k.Type() == KeyRune {
+			k = normalizeLayoutKey(k, kk)
+		}
+		return k
+
+	case '~':
+		code := seq.Param(0)
+		if t, ok = xtermNavKeysCode[code]; !ok {
+			t, ok = kittyTildeKeys[code]
+		}
+	case 'E':
+		// KP_BEGIN has no KeyType
+		t, ok = KeyKitty, true
+		kk.Code = 57427
+	case 'P', 'Q', 'S':
+		t, ok = KeyF1+KeyType(seq.Final-'P'), true
+		if seq.Final == 'S' {
+			t = KeyF4
+		}
+	default:
+		t, ok = xtermNavKeysFinal[seq.Final]
+	}
+	if !ok || seq.NumSubParams(0) > 0 {
+		return keyFromTypeMod(KeyESCSeq, ModNone)
+	}
+	i.kkey = kk
+	i.sz = n
+	return keyFromTypeMod(t, kk.Mod)
+}
+
+// returns the rune key k of the kitty key kk normalized for the
+// international layouts, see WithLayoutNormalization.
+func normalizeLayoutKey(k Key, kk KittyKey) Key {
+	mod := k.Mod()
+	switch {
+	case kk.Text != 0 && unicode.IsPrint(kk.Text):
+		if mod&ModAlt != 0 {
+			// AltGr, reported as Alt or Ctrl+Alt
+			mod &^= ModAlt | ModCtrl
+		}
+		return keyFromRuneMod(kk.Text, mod&^ModShift)
+	case kk.Base != 0 && mod&(ModCtrl|ModAlt|ModMeta) != 0:
+		return keyFromRuneMod(kk.Base, mod)
+	}
+	return k
+}
+
+// returns the Key for the kitty key kk reported with the final byte 'u'.
+func kittyRuneKey(kk KittyKey) Key {
+	code := kk.Code
+	if code >= 57399 && int(code-57399) < len(kittyKeypadRunes) && kittyKeypadRunes[code-57399] != 0 {
+		code = kittyKeypadRunes[code-57399]
+	}
+	if t, ok := kittyFuncKeys[code]; ok {
+		return keyFromTypeMod(t, kk.Mod)
+	}
+	if code >= 57376 && code <= 57398 {
+		return keyFromTypeMod(KeyF13+KeyType(code-57376), kk.Mod)
+	}
+	if code < ' ' || (code >= 57344 && code <= 63743) || !unicode.IsPrint(code) {
+		// other functional keys (in the private use area) and controls
+		return keyFromTypeMod(KeyKitty, kk.Mod)
+	}
+
+	mod := kk.Mod
+	if mod&ModShift != 0 {
+		shifted := kk.Shifted
+		if shifted == 0 && unicode.ToUpper(code) != code {
+			shifted = unicode.ToUpper(code)
+		}
+		if shifted != 0 {
+			code, mod = shifted, mod&^ModShift
+		}
+	}
+	return keyFromRuneMod(code, mod)
+}
@@ -0,0 +1,173 @@
+package zzterm
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestInput_ReadKey_Kitty(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Key
+		kk   KittyKey
+	}{
+		{"\x1b[97u", 'a', KittyKey{Code: 'a', Action: ActionPress}},
+		{"\x1b[97;5u", NewRuneKey('a', ModCtrl), KittyKey{Code: 'a', Mod: ModCtrl, Action: ActionPress}},
+		{"\x1b[97;7u", NewRuneKey('a', ModCtrl|ModAlt), KittyKey{Code: 'a', Mod: ModCtrl | ModAlt, Action: ActionPress}},
+		{"\x1b[97;3u", NewRuneKey('a', ModAlt), KittyKey{Code: 'a', Mod: ModAlt, Action: ActionPress}},
+		{"\x1b[97;2u", 'A', KittyKey{Code: 'a', Mod: ModShift, Action: ActionPress}},
+		{"\x1b[105;5u", NewRuneKey('i', ModCtrl), KittyKey{Code: 'i', Mod: ModCtrl, Action: ActionPress}},
+		{"\x1b[97;6u", NewRuneKey('A', ModCtrl), KittyKey{Code: 'a', Mod: ModCtrl | ModShift, Action: ActionPress}},
+		{"\x1b[49:33;2u", '!', KittyKey{Code: '1', Shifted: '!', Mod: ModShift, Action: ActionPress}},
+		{"\x1b[49;2u", NewRuneKey('1', ModShift), KittyKey{Code: '1', Mod: ModShift, Action: ActionPress}},
+		{"\x1b[1092::97;5u", NewRuneKey('ф', ModCtrl), KittyKey{Code: 'ф', Base: 'a', Mod: ModCtrl, Action: ActionPress}},
+		{"\x1b[113;3;64u", NewRuneKey('q', ModAlt), KittyKey{Code: 'q', Text: '@', Mod: ModAlt, Action: ActionPress}},
+		{"\x1b[97;1:3u", 'a', KittyKey{Code: 'a', Action: ActionRelease}},
+		{"\x1b[97;65u", 'a', KittyKey{Code: 'a', Action: ActionPress}},
+		{"\x1b[97;9u", NewRuneKey('a', ModMeta), KittyKey{Code: 'a', Mod: ModMeta, Action: ActionPress}},
+		{"\x1b[27u", NewKey(KeyESC, ModNone), KittyKey{Code: 27, Action: ActionPress}},
+		{"\x1b[13;2u", NewKey(KeyCR, ModShift), KittyKey{Code: 13, Mod: ModShift, Action: ActionPress}},
+		{"\x1b[127;1:2u", NewKey(KeyDEL, ModNone), KittyKey{Code: 127, Action: ActionRepeat}},
+		{"\x1b[57376u", NewKey(KeyF13, ModNone), KittyKey{Code: 57376, Action: ActionPress}},
+		{"\x1b[57399u", '0', KittyKey{Code: 57399, Action: ActionPress}},
+		{"\x1b[57441;2u", NewKey(KeyKitty, ModShift), KittyKey{Code: 57441, Mod: ModShift, Action: ActionPress}},
+		{"\x1b[1;5:3A", NewKey(KeyUp, ModCtrl), KittyKey{Mod: ModCtrl, Action: ActionRelease}},
+		{"\x1b[1;1:2B", NewKey(KeyDown, ModNone), KittyKey{Action: ActionRepeat}},
+		{"\x1b[1;2:3P", NewKey(KeyF1, ModShift), KittyKey{Mod: ModShift, Action: ActionRelease}},
+		{"\x1b[1;1:3S", NewKey(KeyF4, ModNone), KittyKey{Action: ActionRelease}},
+		{"\x1b[13;1:3~", NewKey(KeyF3, ModNone), KittyKey{Action: ActionRelease}},
+		{"\x1b[5;17~", NewKey(KeyPgUp, ModNone), KittyKey{Action: ActionPress}},
+		{"\x1b[E", NewKey(KeyKitty, ModNone), KittyKey{Code: 57427, Action: ActionPress}},
+		{"\x1b[99;5;1:2Z", NewKey(KeyESCSeq, ModNone), KittyKey{}},
+		{"\x1b[>1u", NewKey(KeyESCSeq, ModNone), KittyKey{}},
+		{"\x1b[u", NewKey(KeyESCSeq, ModNone), KittyKey{}},
+	}
+
+	input := NewInput(WithKitty())
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			k, err := input.ReadKey(strings.NewReader(c.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if k != c.want {
+				t.Errorf("want %s, got %s", c.want, k)
+			}
+			if got := input.Kitty(); got != c.kk {
+				t.Errorf("want %+v, got %+v", c.kk, got)
+			}
+		})
+	}
+
+	t.Run("keypad", func(t *testing.T) {
+		keypad := []struct {
+			code rune
+			want Key
+		}{
+			{57399, '0'}, {57400, '1'}, {57401, '2'}, {57402, '3'}, {57403, '4'},
+			{57404, '5'}, {57405, '6'}, {57406, '7'}, {57407, '8'}, {57408, '9'},
+			{57409, '.'},                        // KP_DECIMAL
+			{57410, '/'},                        // KP_DIVIDE
+			{57411, '*'},                        // KP_MULTIPLY
+			{57412, '-'},                        // KP_SUBTRACT
+			{57413, '+'},                        // KP_ADD
+			{57414, NewKey(KeyCR, ModNone)},     // KP_ENTER
+			{57415, '='},                        // KP_EQUAL
+			{57416, ','},                        // KP_SEPARATOR
+			{57417, NewKey(KeyLeft, ModNone)},   // KP_LEFT
+			{57418, NewKey(KeyRight, ModNone)},  // KP_RIGHT
+			{57419, NewKey(KeyUp, ModNone)},     // KP_UP
+			{57420, NewKey(KeyDown, ModNone)},   // KP_DOWN
+			{57421, NewKey(KeyPgUp, ModNone)},   // KP_PAGE_UP
+			{57422, NewKey(KeyPgDn, ModNone)},   // KP_PAGE_DOWN
+			{57423, NewKey(KeyHome, ModNone)},   // KP_HOME
+			{57424, NewKey(KeyEnd, ModNone)},    // KP_END
+			{57425, NewKey(KeyInsert, ModNone)}, // KP_INSERT
+			{57426, NewKey(KeyDelete, ModNone)}, // KP_DELETE
+			{57427, NewKey(KeyKitty, ModNone)},  // KP_BEGIN
+			{57428, NewKey(KeyKitty, ModNone)},  // MEDIA_PLAY
+		}
+		for _, c := range keypad {
+			k, err := input.ReadKey(strings.NewReader("\x1b[" + strconv.Itoa(int(c.code)) + "u"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if k != c.want {
+				t.Errorf("%d: want %s, got %s", c.code, c.want, k)
+			}
+			if kk := input.Kitty(); kk.Code != c.code {
+				t.Errorf("%d: want code %d, got %d", c.code, c.code, kk.Code)
+			}
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		input := NewInput()
+		k, err := input.ReadKey(strings.NewReader("\x1b[97;5u"))
+		if err != nil || k.Type() != KeyESCSeq {
+			t.Errorf("want KeyESCSeq, got %s, %v", k, err)
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		input := NewInput(WithKitty())
+		r := &scriptReader{chunks: []string{"\x1b[97;5u", "a"}}
+		for j := 0; j < 2; j++ {
+			if _, err := input.ReadKey(r); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := input.Kitty(); got != (KittyKey{}) {
+			t.Errorf("want zero value, got %+v", got)
+		}
+	})
+}
+
+func TestInput_ReadKey_KittyLayout(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Key
+	}{
+		{"\x1b[113;3;64u", '@'},                        // AltGr+q, German layout
+		{"\x1b[113;7;64u", '@'},                        // AltGr+q reported as Ctrl+Alt
+		{"\x1b[113;11;64u", NewRuneKey('@', ModMeta)},  // Meta+AltGr+q
+		{"\x1b[101;1;233u", 'é'},                       // dead key ´ then e
+		{"\x1b[50:34;2;34u", '"'},                      // Shift+2, US layout
+		{"\x1b[1092::97;5u", NewRuneKey('a', ModCtrl)}, // Ctrl+ф, Russian layout
+		{"\x1b[1092::97;3u", NewRuneKey('a', ModAlt)},  // Alt+ф
+		{"\x1b[1092::97;1;1092u", 'ф'},                 // ф
+		{"\x1b[1092::97u", 'ф'},                        // ф, without text
+		{"\x1b[97;3u", NewRuneKey('a', ModAlt)},        // Alt+a
+		{"\x1b[97;5;1u", NewRuneKey('a', ModCtrl)},     // Ctrl+a, control text
+		{"\x1b[27u", NewKey(KeyESC, ModNone)},          // not a rune
+		{"\x1b[1;3A", NewKey(KeyUp, ModAlt)},           // not a rune
+	}
+
+	input := NewInput(WithKitty(), WithLayoutNormalization())
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			k, err := input.ReadKey(strings.NewReader(c.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if k != c.want {
+				t.Errorf("want %s, got %s", c.want, k)
+			}
+		})
+	}
+}
+
+func TestEnableKittyKeyboard(t *testing.T) {
+	var buf bytes.Buffer
+	if err := EnableKittyKeyboard(&buf, KittyDisambiguate|KittyReportEvents); err != nil {
+		t.Fatal(err)
+	}
+	if err := DisableKittyKeyboard(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\x1b[>3u\x1b[<u"; buf.String() != want {
+		t.Errorf("want %q, got %q", want, buf.String())
+	}
+}
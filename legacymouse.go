@@ -0,0 +1,120 @@
+package zzterm
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// MouseEncoding is an encoding of the mouse event reports, in addition to
+// the SGR mouse mode that is always decoded when the mouse events are
+// enabled.
+type MouseEncoding int
+
+// List of supported legacy mouse encodings.
+const (
+	MouseEncodingX10   MouseEncoding = iota + 1 // CSI M Cb Cx Cy, as bytes
+	MouseEncodingUTF8                           // CSI ? 1005 h, CSI M Cb Cx Cy, as UTF-8
+	MouseEncodingURXVT                          // CSI ? 1015 h, CSI Cb ; Cx ; Cy M
+)
+
+// WithMouseEncodings enables the mouse events as for WithMouse, and the
+// decoding of the reports in the legacy encodings encs in addition to the
+// SGR mouse mode, so that the mouse works with older terminals and with
+// multiplexers configured to downgrade the mouse protocol. The encoding of
+// a report is detected automatically among those enabled, except that the
+// X10 and UTF-8 encodings are the same for coordinates up to 95: if both are
+// enabled, a report is decoded as UTF-8 if it is valid UTF-8 and as X10
+// otherwise.
+//
+// The legacy encodings do not report which button is released, the release
+// is reported for the lowest button held, if any. The X10 and UTF-8
+// encodings cannot report coordinates greater than 223 and 2015.
+func WithMouseEncodings(encs ...MouseEncoding) Option {
+	return func(i *Input) {
+		i.mouse = true
+		for _, enc := range encs {
+			if enc > 0 && enc <= MouseEncodingURXVT {
+				i.legacy |= 1 << enc
+			}
+		}
+	}
+}
+
+// prefix of the X10 and UTF-8 mouse reports.
+var legacyMousePrefix = []byte("\x1b[M")
+
+// returns either a KeyMouse key, or a KeyESCSeq if the buffer does not start
+// with a mouse report in one of the legacy encodings enabled. If it returns a
+// KeyMouse key, i.sz is set to the length of the report.
+func (i *Input) decodeLegacyMouse() Key {
+	b := i.buf[:i.len]
+	var vals [3]int
+	offsets := [3]int{32, 32, 32}
+	n := 0
+	switch {
+	case bytes.HasPrefix(b, legacyMousePrefix):
+		if i.legacy&(1<<MouseEncodingUTF8) != 0 {
+			n = decodeMouseUTF8(b, &vals)
+		}
+		if n == 0 && i.legacy&(1<<MouseEncodingX10) != 0 && len(b) >= 6 {
+			for j := range vals {
+				vals[j] = int(b[3+j])
+			}
+			n = 6
+		}
+
+	case i.legacy&(1<<MouseEncodingURXVT) != 0:
+		seq, sz := ParseCSI(b)
+		if sz == 0 || seq.Prefix != 0 || seq.Intermediate != 0 || seq.Final != 'M' ||
+			seq.NumParams() != 3 || seq.HasSubParams() {
+			break
+		}
+		for j := range vals {
+			vals[j] = seq.Param(j)
+		}
+		n = sz
+		// the coordinates are not offset
+		offsets = [3]int{32, 0, 0}
+	}
+	if n == 0 {
+		return keyFromTypeMod(KeyESCSeq, ModNone)
+	}
+
+	// the coordinates start at 1
+	for j := range vals {
+		vals[j] -= offsets[j]
+		if vals[j] < 0 || (j > 0 && vals[j] == 0) {
+			return keyFromTypeMod(KeyESCSeq, ModNone)
+		}
+	}
+
+	cb := vals[0]
+	pressed := cb&0b_0010_0011 != 0b_0000_0011
+	if !pressed {
+		// release of an unknown button, report the lowest one held
+		for id := 1; id <= 3; id++ {
+			if i.held&(1<<(id-1)) != 0 {
+				cb = cb&^0b_0000_0011 | (id - 1)
+				break
+			}
+		}
+	}
+	k := i.setMouseEvent(cb, pressed, vals[1], vals[2])
+	i.sz = n
+	return k
+}
+
+// decodes the 3 UTF-8 encoded values of the mouse report in b and returns
+// the length of the report, or 0 if it is not a valid UTF-8 report.
+func decodeMouseUTF8(b []byte, vals *[3]int) int {
+	n := len(legacyMousePrefix)
+	for j := range vals {
+		r, sz := utf8.DecodeRune(b[n:])
+		if r == utf8.RuneError {
+			return 0
+		}
+		vals[j] = int(r)
+		n += sz
+	}
+	return n
+}
@@ -0,0 +1,98 @@
+package zzterm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInput_ReadKey_LegacyMouse(t *testing.T) {
+	type event struct {
+		typ     KeyType
+		btn     int
+		pressed bool
+		x, y    int
+	}
+	none := event{typ: KeyESCSeq}
+
+	cases := []struct {
+		encs []MouseEncoding
+		in   []string
+		want []event
+	}{
+		{
+			[]MouseEncoding{MouseEncodingX10},
+			[]string{"\x1b[M !!", "\x1b[M#*\"", "\x1b[M`\xff\xff", "\x1b[M  !", "\x1b[32;1;1M", "\x1b[<0;1;1M"},
+			[]event{
+				{KeyMouse, 1, true, 1, 1}, {KeyMouse, 1, false, 10, 2}, {KeyMouse, 4, true, 223, 223},
+				none, none, {KeyMouse, 1, true, 1, 1},
+			},
+		},
+		{
+			[]MouseEncoding{MouseEncodingUTF8},
+			[]string{"\x1b[M!" + string(rune(332)) + "!", "\x1b[M#" + string(rune(332)) + "!", "\x1b[M \xff!"},
+			[]event{{KeyMouse, 2, true, 300, 1}, {KeyMouse, 2, false, 300, 1}, none},
+		},
+		{
+			[]MouseEncoding{MouseEncodingX10, MouseEncodingUTF8},
+			[]string{"\x1b[M " + string(rune(332)) + "!", "\x1b[M \xff!"},
+			[]event{{KeyMouse, 1, true, 300, 1}, {KeyMouse, 1, true, 223, 1}},
+		},
+		{
+			[]MouseEncoding{MouseEncodingURXVT},
+			[]string{"\x1b[32;10;20M", "\x1b[35;11;20M", "\x1b[67;12;20M", "\x1b[32;0;20M", "\x1b[M !!", "\x1b[1;2;3m"},
+			[]event{{KeyMouse, 1, true, 10, 20}, {KeyMouse, 1, false, 11, 20}, {KeyMouse, 0, true, 12, 20}, none, none, none},
+		},
+		{
+			nil,
+			[]string{"\x1b[M !!", "\x1b[32;10;20M"},
+			[]event{none, none},
+		},
+	}
+	for _, c := range cases {
+		input := NewInput(WithMouseEncodings(c.encs...))
+		for j, in := range c.in {
+			w := c.want[j]
+			k, err := input.ReadKey(strings.NewReader(in))
+			if err != nil {
+				t.Fatalf("%v %q: %v", c.encs, in, err)
+			}
+			if k.Type() != w.typ {
+				t.Errorf("%v %q: want %s, got %s", c.encs, in, w.typ, k)
+				continue
+			}
+			if w.typ != KeyMouse {
+				continue
+			}
+			m := input.Mouse()
+			if x, y := m.Coords(); m.ButtonID() != w.btn || m.ButtonPressed() != w.pressed || x != w.x || y != w.y {
+				t.Errorf("%v %q: want button %d pressed=%t at %d,%d, got %s", c.encs, in, w.btn, w.pressed, w.x, w.y, m)
+			}
+		}
+	}
+
+	// the bytes after the report are decoded by the next call
+	input := NewInput(WithMouseEncodings(MouseEncodingX10))
+	r := strings.NewReader("\x1b[M !!a")
+	if k, err := input.ReadKey(r); err != nil || k.Type() != KeyMouse {
+		t.Fatalf("want KeyMouse, got %s, %v", k, err)
+	}
+	if k, err := input.ReadKey(r); err != nil || k != 'a' {
+		t.Fatalf("want 'a', got %s, %v", k, err)
+	}
+
+	// the legacy reports are not decoded while the mouse is disabled
+	input = NewInput(WithMouseEncodings(MouseEncodingX10, MouseEncodingUTF8, MouseEncodingURXVT))
+	for _, on := range []bool{false, true, false} {
+		input.SetMouseEnabled(on)
+		for _, in := range []string{"\x1b[M !!", "\x1b[M ß!", "\x1b[32;1;1M"} {
+			want := KeyESCSeq
+			if on {
+				want = KeyMouse
+			}
+			k, err := input.ReadKey(strings.NewReader(in))
+			if err != nil || k.Type() != want {
+				t.Errorf("%t %q: want %s, got %s, %v", on, in, want, k, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package zzterm
+
+import "time"
+
+// number of bytes of an escape sequence that the Input waits for after an
+// ESC when the link speed is set. It covers the common special keys and
+// mouse events.
+const linkSeqBytes = 16
+
+// WithLinkSpeed sets the speed in bits per second of the link to the
+// terminal, e.g. a serial line or a high-latency connection. By default, an
+// ESC read alone is returned as a KeyESC key, and an escape sequence is
+// decoded with the bytes returned by a single read, which is correct for
+// local terminals but may split the sequences received over a slow link.
+// With this option, when the bytes read start with an incomplete escape
+// sequence, the Input keeps reading for up to the time required to transmit
+// 16 bytes at that speed (with 10 bits per byte), so that the rest of the
+// sequence can arrive. It is equivalent to WithSeqTimeout with that delay,
+// with the same delay for an ESC read alone.
+//
+// For the delay to be enforced, the reader must have a read timeout shorter
+// than that delay, otherwise ReadKey blocks until more bytes are received.
+// If bitsPerSecond is <= 0, the option is ignored.
+func WithLinkSpeed(bitsPerSecond int) Option {
+	return func(i *Input) {
+		if bitsPerSecond > 0 {
+			i.seqDelay = time.Duration(linkSeqBytes * 10 * int64(time.Second) / int64(bitsPerSecond))
+			i.escDelay = i.seqDelay
+			i.byteDelay = time.Duration(10 * int64(time.Second) / int64(bitsPerSecond))
+		}
+	}
+}
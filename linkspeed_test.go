@@ -0,0 +1,54 @@
+package zzterm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithLinkSpeed(t *testing.T) {
+	esc, up := NewKey(KeyESC, ModNone), NewKey(KeyUp, ModNone)
+	empty := make([]string, 20)
+
+	cases := []struct {
+		desc   string
+		bps    int
+		chunks []string
+		want   []Key
+	}{
+		{"no link speed", 0, []string{"\x1b", "[A"}, []Key{esc, '[', 'A'}},
+		{"split CSI", 9600, []string{"\x1b", "", "[", "A"}, []Key{up}},
+		{"split SS3", 9600, []string{"\x1b", "O", "", "P"}, []Key{NewKey(KeyF1, ModNone)}},
+		{"alt key", 9600, []string{"\x1b", "a", "b"}, []Key{NewKey(KeyESCSeq, ModNone), 'b'}},
+		{"late bytes", 9600, append(append([]string{"\x1b"}, empty...), "[A"), []Key{esc, '[', 'A'}},
+		{"within delay", 9600, append(append([]string{"\x1b"}, empty[:5]...), "[A"), []Key{up}},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			clock := &fakeClock{}
+			input := NewInput(WithClock(clock), WithLinkSpeed(c.bps))
+			r := &clockedReader{clock: clock, chunks: c.chunks, delay: time.Millisecond}
+			var got []Key
+			for {
+				k, err := input.ReadKey(r)
+				if err == ErrTimeout && len(r.chunks) == 0 {
+					break
+				}
+				if err == ErrTimeout {
+					continue
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, k)
+			}
+			if len(got) != len(c.want) {
+				t.Fatalf("want %v, got %v", c.want, got)
+			}
+			for j, w := range c.want {
+				if got[j] != w {
+					t.Errorf("[%d]: want %s, got %s", j, w, got[j])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package zzterm
+
+import "time"
+
+type longPress struct {
+	threshold time.Duration
+
+	kkey     KittyKey  // details of the key held, valid if held
+	typ      KeyType   // type of the key held, valid if held
+	at       time.Time // time the key held was pressed
+	held     bool
+	reported bool // the long press of the key held was returned
+}
+
+// WithLongPress enables the detection of long presses of the keys reported
+// by the kitty keyboard protocol with their press and release events (see
+// WithKitty and KittyReportEvents). When a key is held for at least
+// threshold, ReadKey returns a key of type KeyLongPress with the modifier
+// flags of the key held, once per press, and Input.Kitty returns the
+// details of the key held. Only the last key pressed is tracked. This enables mobile-style interactions, e.g. to
+// show a menu when a key is held.
+//
+// The long press is detected by the first call to ReadKey after the
+// threshold expires, so for it to be detected while no other key is
+// received, the reader must have a read timeout so that ReadKey is called
+// regularly. If the repeat or release event of the key held is read after
+// the threshold and before the long press is returned, the KeyLongPress key
+// is returned first and the event is returned by the next call to ReadKey.
+//
+// The legacy terminal input has no release events, so that the keys not
+// reported by the kitty keyboard protocol are never detected as held.
+func WithLongPress(threshold time.Duration) Option {
+	return func(i *Input) {
+		i.longPress = &longPress{threshold: threshold}
+	}
+}
+
+// tracks the key presses and releases of the kitty keyboard protocol, and
+// returns a KeyLongPress key if the key held expired the threshold.
+// Otherwise it returns k and err unchanged.
+func (i *Input) detectLongPress(k Key, err error) (Key, error) {
+	lp := i.longPress
+	if err != nil {
+		if err == ErrTimeout && lp.expired(i.clock.Now()) {
+			return i.longPressKey(), nil
+		}
+		return k, err
+	}
+
+	kk := i.kkey
+	switch {
+	case kk.Action == ActionPress:
+		lp.kkey, lp.typ, lp.at = kk, k.Type(), i.clock.Now()
+		lp.held, lp.reported = true, false
+	case lp.held && kk.Code == lp.kkey.Code && k.Type() == lp.typ:
+		expired := lp.expired(i.clock.Now())
+		if kk.Action == ActionRelease {
+			lp.held = false
+		}
+		if expired {
+			// return the event of the key held after the long press
+			i.unread(i.event(k))
+			return i.longPressKey(), nil
+		}
+	}
+	return k, nil
+}
+
+// returns true if the key held expired the threshold at now, and its long
+// press has not been reported yet.
+func (lp *longPress) expired(now time.Time) bool {
+	return lp.held && !lp.reported && now.Sub(lp.at) >= lp.threshold
+}
+
+// returns the KeyLongPress key of the key held and makes it the last key
+// read.
+func (i *Input) longPressKey() Key {
+	lp := i.longPress
+	lp.reported = true
+	i.kkey = lp.kkey
+	i.rbuf = []byte{}
+	return keyFromTypeMod(KeyLongPress, lp.kkey.Mod)
+}
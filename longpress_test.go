@@ -0,0 +1,59 @@
+package zzterm
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInput_LongPress(t *testing.T) {
+	press, repeat, release := "\x1b[97u", "\x1b[97;1:2u", "\x1b[97;1:3u"
+	lp := NewKey(KeyLongPress, ModNone).String()
+	a := Key('a').String()
+
+	cases := []struct {
+		desc   string
+		chunks []string
+		want   []string
+	}{
+		{"short press", []string{press, "", release}, []string{a, "timeout", a}},
+		{"polled", []string{press, "", "", "", "", "", release}, []string{a, "timeout", "timeout", lp, "timeout", "timeout", a}},
+		{"repeat", []string{press, "", "", repeat, repeat, release}, []string{a, "timeout", "timeout", lp, a, a, a}},
+		{"release", []string{press, "", "", release}, []string{a, "timeout", "timeout", lp, a}},
+		{"other key", []string{press, "b", "", "", "", release}, []string{a, Key('b').String(), "timeout", lp, "timeout", a}},
+		{"pressed again", []string{press, "", "", release, press, release}, []string{a, "timeout", "timeout", lp, a, a, a}},
+		{"ctrl", []string{"\x1b[97;5u", "", "", "", ""}, []string{NewRuneKey('a', ModCtrl).String(), "timeout", "timeout", NewKey(KeyLongPress, ModCtrl).String(), "timeout"}},
+		{"legacy", []string{"a", "", "", "", ""}, []string{a, "timeout", "timeout", "timeout", "timeout"}},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			clock := &fakeClock{}
+			input := NewInput(WithClock(clock), WithKitty(), WithLongPress(80*time.Millisecond))
+			r := &clockedReader{clock: clock, chunks: c.chunks, delay: 30 * time.Millisecond}
+
+			var got []string
+			for len(r.chunks) > 0 || input.buffered() {
+				k, err := input.ReadKey(r)
+				switch {
+				case err == ErrTimeout:
+					got = append(got, "timeout")
+				case err != nil:
+					t.Fatal(err)
+				case k.Type() == KeyLongPress:
+					if kk := input.Kitty(); kk.Code != 'a' {
+						t.Errorf("want long press of 'a', got %#v", kk)
+					}
+					if b := input.Bytes(); len(b) != 0 {
+						t.Errorf("want no bytes for the long press, got %q", b)
+					}
+					fallthrough
+				default:
+					got = append(got, k.String())
+				}
+			}
+			if strings.Join(got, " ") != strings.Join(c.want, " ") {
+				t.Errorf("want %v, got %v", c.want, got)
+			}
+		})
+	}
+}
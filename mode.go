@@ -0,0 +1,202 @@
+package zzterm
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// ModeState represents the known state of a DEC private mode.
+type ModeState int
+
+// List of mode states.
+const (
+	ModeUnknown ModeState = iota // state has not been set nor reported
+	ModeSet                      // mode is enabled (CSI ? Pm h)
+	ModeReset                    // mode is disabled (CSI ? Pm l)
+)
+
+// String returns the string representation of the mode state.
+func (s ModeState) String() string {
+	switch s {
+	case ModeSet:
+		return "set"
+	case ModeReset:
+		return "reset"
+	default:
+		return "unknown"
+	}
+}
+
+// ModeTracker is an io.Writer that keeps track of the DEC private modes
+// (DECSET/DECRST, e.g. mouse tracking or focus reporting) enabled and
+// disabled by the sequences written through it, before forwarding the
+// bytes to the underlying writer. It is typically wrapped around the
+// terminal's writer and passed to the EnableMouse, EnableFocus, etc.
+// helpers so that the terminal can be restored to a known state on exit
+// by calling Reset.
+//
+// The replies to mode requests (DECRQM, see Request) may also be fed to
+// the tracker by calling ObserveReply, so that the initial state of a mode
+// is known. It is safe for concurrent use.
+type ModeTracker struct {
+	w io.Writer
+
+	mu      sync.Mutex
+	modes   map[int]ModeState
+	initial map[int]ModeState // state before the first change through the tracker
+}
+
+// NewModeTracker returns a ModeTracker that writes to w.
+func NewModeTracker(w io.Writer) *ModeTracker {
+	return &ModeTracker{
+		w:       w,
+		modes:   make(map[int]ModeState),
+		initial: make(map[int]ModeState),
+	}
+}
+
+// Write records the DEC private mode changes in p and writes p to the
+// underlying writer. Only complete sequences are recognized, a mode
+// change sequence split over multiple calls to Write is not recorded.
+func (t *ModeTracker) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	t.scan(p)
+	t.mu.Unlock()
+	return t.w.Write(p)
+}
+
+// Query returns the known state of the DEC private mode.
+func (t *ModeTracker) Query(mode int) ModeState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.modes[mode]
+}
+
+// Request writes the DEC Request Mode (DECRQM) sequence for the mode to the
+// underlying writer. The terminal replies with a report that can be fed to
+// ObserveReply.
+func (t *ModeTracker) Request(mode int) error {
+	_, err := fmt.Fprintf(t.w, "\x1b[?%d$p", mode)
+	return err
+}
+
+// ObserveReply records the mode state reported in b if it is a DEC Report
+// Mode (DECRPM) reply (CSI ? Ps ; Pm $ y), as sent by the terminal in
+// response to Request. It returns true if b is such a reply, false
+// otherwise. This is typically called with the Input.Bytes of a KeyESCSeq
+// key.
+func (t *ModeTracker) ObserveReply(b []byte) bool {
+	if !bytes.HasPrefix(b, []byte("\x1b[?")) || !bytes.HasSuffix(b, []byte("$y")) {
+		return false
+	}
+	b = b[3 : len(b)-2]
+	ix := bytes.IndexByte(b, ';')
+	if ix < 0 {
+		return false
+	}
+	mode, err := parseUintBytes(b[:ix])
+	if err != nil {
+		return false
+	}
+	val, err := parseUintBytes(b[ix+1:])
+	if err != nil {
+		return false
+	}
+
+	var st ModeState
+	switch val {
+	case 1, 3: // set, permanently set
+		st = ModeSet
+	case 2, 4: // reset, permanently reset
+		st = ModeReset
+	default: // 0 means not recognized
+		st = ModeUnknown
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.modes[int(mode)] = st
+	return true
+}
+
+// Reset restores all modes changed through the tracker to the state they
+// were in before the first change: modes that were known to be set are set
+// again, all others are reset. It is typically called when the application
+// exits.
+func (t *ModeTracker) Reset() error {
+	t.mu.Lock()
+	modes := make([]int, 0, len(t.initial))
+	for m := range t.initial {
+		modes = append(modes, m)
+	}
+	sort.Ints(modes)
+
+	var buf bytes.Buffer
+	for _, m := range modes {
+		st := t.initial[m]
+		final := byte('l')
+		if st == ModeSet {
+			final = 'h'
+		} else {
+			st = ModeReset
+		}
+		fmt.Fprintf(&buf, "\x1b[?%d%c", m, final)
+		t.modes[m] = st
+	}
+	t.initial = make(map[int]ModeState)
+	t.mu.Unlock()
+
+	if buf.Len() == 0 {
+		return nil
+	}
+	_, err := t.w.Write(buf.Bytes())
+	return err
+}
+
+// scan records the DEC private mode changes (CSI ? Pm ; ... h or l) in p.
+func (t *ModeTracker) scan(p []byte) {
+	const prefix = "\x1b[?"
+
+	for {
+		ix := bytes.Index(p, []byte(prefix))
+		if ix < 0 {
+			return
+		}
+		p = p[ix+len(prefix):]
+
+		// find the end of the parameters
+		end := 0
+		for end < len(p) && (p[end] == ';' || ('0' <= p[end] && p[end] <= '9')) {
+			end++
+		}
+		if end == len(p) {
+			return
+		}
+
+		var st ModeState
+		switch p[end] {
+		case 'h':
+			st = ModeSet
+		case 'l':
+			st = ModeReset
+		default:
+			continue
+		}
+
+		for _, param := range bytes.Split(p[:end], []byte(";")) {
+			mode, err := parseUintBytes(param)
+			if err != nil {
+				continue
+			}
+			m := int(mode)
+			if _, ok := t.initial[m]; !ok {
+				t.initial[m] = t.modes[m]
+			}
+			t.modes[m] = st
+		}
+		p = p[end+1:]
+	}
+}
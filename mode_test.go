@@ -0,0 +1,70 @@
+package zzterm
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestModeTracker(t *testing.T) {
+	var buf bytes.Buffer
+	mt := NewModeTracker(&buf)
+
+	if st := mt.Query(1000); st != ModeUnknown {
+		t.Fatalf("want unknown state, got %s", st)
+	}
+
+	// focus is reported as initially set by the terminal
+	if !mt.ObserveReply([]byte("\x1b[?1004;1$y")) {
+		t.Fatal("want reply to be observed")
+	}
+	if mt.ObserveReply([]byte("\x1b[?1004;1R")) {
+		t.Fatal("want reply to be ignored")
+	}
+
+	if err := EnableMouse(mt, MouseAny); err != nil {
+		t.Fatal(err)
+	}
+	if err := DisableFocus(mt); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "\x1b[?1003;1006h\x1b[?1004l" {
+		t.Fatalf("unexpected bytes written: %q", got)
+	}
+
+	wantStates := map[int]ModeState{
+		1000: ModeUnknown,
+		1003: ModeSet,
+		1006: ModeSet,
+		1004: ModeReset,
+	}
+	for m, want := range wantStates {
+		if got := mt.Query(m); got != want {
+			t.Errorf("mode %d: want %s, got %s", m, want, got)
+		}
+	}
+
+	buf.Reset()
+	if err := mt.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "\x1b[?1003l\x1b[?1004h\x1b[?1006l" {
+		t.Fatalf("unexpected reset bytes written: %q", got)
+	}
+	wantStates[1003] = ModeReset
+	wantStates[1006] = ModeReset
+	wantStates[1004] = ModeSet
+	for m, want := range wantStates {
+		if got := mt.Query(m); got != want {
+			t.Errorf("after reset: mode %d: want %s, got %s", m, want, got)
+		}
+	}
+
+	// nothing more to reset
+	buf.Reset()
+	if err := mt.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("want no bytes written, got %q", buf.String())
+	}
+}
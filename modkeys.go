@@ -0,0 +1,61 @@
+package zzterm
+
+import "unicode"
+
+// List of the xterm modifyOtherKeys control sequences. In mode 2, the
+// terminal reports all the keys pressed with modifiers as escape sequences.
+const (
+	SeqEnableModifyOtherKeys  = "\x1b[>4;2m"
+	SeqDisableModifyOtherKeys = "\x1b[>4m"
+)
+
+// WithModifyOtherKeys enables decoding of the keys reported by xterm's
+// modifyOtherKeys mode, "ESC [ 27 ; mod ; code ~" (or "ESC [ code ; mod u"
+// if the formatOtherKeys resource is set). They are returned as keys of
+// type KeyRune with the modifier flags set (see NewRuneKey), so that e.g.
+// Ctrl-i can be told apart from Tab and Ctrl-m from Enter. As the rune
+// reported is the shifted one (e.g. 'A' for Ctrl-Shift-a), ModShift is not
+// set for printable runes. The control characters (e.g. Tab or Enter
+// pressed with modifiers) are returned as the corresponding key type with
+// the modifier flags. It is the responsibility of the caller to enable the
+// mode on the terminal, e.g. by writing SeqEnableModifyOtherKeys. Without
+// this option, those sequences are reported as KeyESCSeq.
+func WithModifyOtherKeys() Option {
+	return func(i *Input) {
+		i.modKeys = true
+	}
+}
+
+// returns the key decoded from a modifyOtherKeys sequence, or a KeyESCSeq
+// if the buffer does not start with such a sequence. If it returns another
+// key, i.sz is set to the length of the sequence.
+func (i *Input) decodeModifyOtherKeys() Key {
+	seq, n := ParseCSI(i.buf[:i.len])
+	if n == 0 || seq.Prefix != 0 || seq.Intermediate != 0 || seq.HasSubParams() {
+		return keyFromTypeMod(KeyESCSeq, ModNone)
+	}
+
+	var code, param int
+	switch {
+	case seq.Final == '~' && seq.NumParams() == 3 && seq.Param(0) == 27:
+		param, code = seq.Param(1), seq.Param(2)
+	case seq.Final == 'u' && seq.NumParams() == 2:
+		code, param = seq.Param(0), seq.Param(1)
+	default:
+		return keyFromTypeMod(KeyESCSeq, ModNone)
+	}
+	if code < 0 || code > unicode.MaxRune || param < 1 {
+		return keyFromTypeMod(KeyESCSeq, ModNone)
+	}
+
+	i.sz = n
+	mod := ModFromXtermParam(param)
+	r := rune(code)
+	if KeyType(r) <= KeyUS || KeyType(r) == KeyDEL {
+		return keyFromTypeMod(KeyType(r), mod)
+	}
+	if unicode.IsPrint(r) {
+		mod &^= ModShift
+	}
+	return keyFromRuneMod(r, mod)
+}
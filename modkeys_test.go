@@ -0,0 +1,51 @@
+package zzterm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInput_ReadKey_ModifyOtherKeys(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Key
+	}{
+		{"\x1b[27;5;105~", NewRuneKey('i', ModCtrl)},
+		{"\x1b[27;5;9~", NewKey(KeyTAB, ModCtrl)},
+		{"\x1b[27;2;9~", NewKey(KeyTAB, ModShift)},
+		{"\x1b[27;5;13~", NewKey(KeyCR, ModCtrl)},
+		{"\x1b[27;5;109~", NewRuneKey('m', ModCtrl)},
+		{"\x1b[27;6;65~", NewRuneKey('A', ModCtrl)},
+		{"\x1b[27;3;97~", NewRuneKey('a', ModAlt)},
+		{"\x1b[27;7;49~", NewRuneKey('1', ModCtrl|ModAlt)},
+		{"\x1b[27;2;32~", ' '},
+		{"\x1b[27;9;127~", NewKey(KeyDEL, ModMeta)},
+		{"\x1b[105;5u", NewRuneKey('i', ModCtrl)},
+		{"\x1b[233;3u", NewRuneKey('é', ModAlt)},
+		{"\x1b[27;5~", NewKey(KeyESCSeq, ModNone)},
+		{"\x1b[28;5;105~", NewKey(KeyESCSeq, ModNone)},
+		{"\x1b[27;5;2000000~", NewKey(KeyESCSeq, ModNone)},
+		{"\x1b[105u", NewKey(KeyESCSeq, ModNone)},
+	}
+
+	input := NewInput(WithModifyOtherKeys())
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			k, err := input.ReadKey(strings.NewReader(c.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if k != c.want {
+				t.Errorf("want %s, got %s", c.want, k)
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		input := NewInput()
+		k, err := input.ReadKey(strings.NewReader("\x1b[27;5;105~"))
+		if err != nil || k.Type() != KeyESCSeq {
+			t.Errorf("want KeyESCSeq, got %s, %v", k, err)
+		}
+	})
+}
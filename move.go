@@ -0,0 +1,85 @@
+package zzterm
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+// MoveReader reads keys from an Input and coalesces the runs of identical
+// movement keys (the arrows, PgUp and PgDn, with the same modifier flags)
+// into a single key with a count, like vim's count prefix. This reduces the
+// redraw churn of list UIs when keys are auto-repeated faster than the UI
+// can render.
+//
+// The keys already available without reading from the reader are always
+// coalesced. If Window is set, the run also continues while identical keys
+// are read within Window of the previous one, which delays the movement by
+// at least Window. For this to be detected while no key is pressed, the
+// reader must have a read timeout so that Input.ReadKey returns ErrTimeout
+// regularly.
+type MoveReader struct {
+	input *Input
+	r     io.Reader
+	err   error // error to return on the next call, read after a run
+
+	// Window is the maximum delay between two keys of a run. A zero value
+	// only coalesces the keys already available.
+	Window time.Duration
+}
+
+// NewMoveReader returns a MoveReader that reads keys from r using input.
+func NewMoveReader(input *Input, r io.Reader) *MoveReader {
+	return &MoveReader{input: input, r: r}
+}
+
+// ReadMove returns the next key and the number of times it was repeated.
+// The count is 1 for keys that are not movement keys, and 0 if an error is
+// returned (e.g. ErrTimeout if no key is available). The key that ends a
+// run of movement keys is returned by the next call to ReadMove (or to
+// Input.ReadKey) with its Input.Bytes and Input.Mouse information. For a
+// movement key, Input.Bytes returns the bytes of the last key of the run.
+// If reading the keys after a run fails, the run is returned and the error
+// is returned by the next call.
+func (m *MoveReader) ReadMove() (Key, int, error) {
+	if err := m.err; err != nil {
+		m.err = nil
+		return 0, 0, err
+	}
+
+	k, err := m.input.ReadKey(m.r)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !isMoveKey(k) {
+		return k, 1, nil
+	}
+
+	n := 1
+	last := m.input.clock.Now()
+	for m.input.buffered() || m.input.clock.Now().Sub(last) < m.Window {
+		next, err := m.input.ReadKey(m.r)
+		if err != nil {
+			if errors.Is(err, ErrTimeout) {
+				continue
+			}
+			m.err = err
+			break
+		}
+		if next != k {
+			m.input.unread(m.input.event(next))
+			break
+		}
+		n++
+		last = m.input.clock.Now()
+	}
+	return k, n, nil
+}
+
+func isMoveKey(k Key) bool {
+	switch k.Type() {
+	case KeyUp, KeyDown, KeyLeft, KeyRight, KeyPgUp, KeyPgDn:
+		return true
+	}
+	return false
+}
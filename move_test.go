@@ -0,0 +1,68 @@
+package zzterm
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type moveResult struct {
+	k   Key
+	n   int
+	err error
+}
+
+func TestMoveReader(t *testing.T) {
+	up, down := NewKey(KeyUp, ModNone), NewKey(KeyDown, ModNone)
+	pgdn := NewKey(KeyPgDn, ModNone)
+
+	t.Run("buffered", func(t *testing.T) {
+		input := NewInput()
+		r := &scriptReader{chunks: []string{"\x1b[A", "\x1b[A", "\x1b[D", "\x1b[6~", "\x1b[6~", "x", "\x1b[A"}}
+		if _, err := input.Expect(context.Background(), r, func(ev Event) bool { return ev.Key == 'x' }); err != nil {
+			t.Fatal(err)
+		}
+
+		mr := NewMoveReader(input, r)
+		want := []moveResult{
+			{up, 2, nil},
+			{NewKey(KeyLeft, ModNone), 1, nil},
+			{pgdn, 2, nil},
+			{up, 1, nil},
+			{0, 0, ErrTimeout},
+		}
+		checkMoves(t, mr, want)
+	})
+
+	t.Run("window", func(t *testing.T) {
+		clock := &fakeClock{}
+		input := NewInput(WithClock(clock))
+		r := &clockedReader{
+			clock:  clock,
+			chunks: []string{"\x1b[A", "\x1b[A", "\x1b[A", "\x1b[B", "x", "\x1b[B", "", "", "", "", "", "", "\x1b[B"},
+			delay:  10 * time.Millisecond,
+		}
+
+		mr := NewMoveReader(input, r)
+		mr.Window = 50 * time.Millisecond
+		want := []moveResult{
+			{up, 3, nil},
+			{down, 1, nil},
+			{'x', 1, nil},
+			{down, 1, nil},
+			{0, 0, ErrTimeout},
+			{down, 1, nil},
+		}
+		checkMoves(t, mr, want)
+	})
+}
+
+func checkMoves(t *testing.T, mr *MoveReader, want []moveResult) {
+	t.Helper()
+	for i, w := range want {
+		k, n, err := mr.ReadMove()
+		if k != w.k || n != w.n || err != w.err {
+			t.Errorf("%d: want %s x%d, %v, got %s x%d, %v", i, w.k, w.n, w.err, k, n, err)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package zzterm
+
+import (
+	"bytes"
+	"io"
+	"time"
+)
+
+const (
+	pasteStartSeq = "\x1b[200~"
+	pasteEndSeq   = "\x1b[201~"
+)
+
+// EnableBracketedPaste sends the Control Sequence Introducer (CSI) function
+// to w to enable bracketed paste, so that the pasted text is enclosed in
+// "ESC [ 200 ~" and "ESC [ 201 ~".
+func EnableBracketedPaste(w io.Writer) error {
+	_, err := io.WriteString(w, SeqEnableBracketedPaste)
+	return err
+}
+
+// DisableBracketedPaste sends the Control Sequence Introducer (CSI)
+// function to w to disable bracketed paste.
+func DisableBracketedPaste(w io.Writer) error {
+	_, err := io.WriteString(w, SeqDisableBracketedPaste)
+	return err
+}
+
+// WithPaste enables decoding of bracketed paste. The pasted text, enclosed
+// in "ESC [ 200 ~" and "ESC [ 201 ~" by the terminal, is reported as a
+// key of type KeyPaste, and the text can be retrieved by calling
+// Input.Paste before the next call to Input.ReadKey. This way, the pasted
+// newlines are not mistaken for Enter presses. It is the responsibility of
+// the caller to enable bracketed paste on the terminal, e.g. with
+// EnableBracketedPaste. Without this option, the delimiters are reported
+// as KeyESCSeq and the pasted text as regular keys.
+//
+// The pasted text may be longer than the buffer of the Input, in which case
+// it is read in multiple reads until the end delimiter is found. If a read
+// times out or fails before the end delimiter, or if the text reaches the
+// paste limit (see WithPasteLimit), the text read so far is returned as a
+// KeyPaste key and the Input stays in paste mode: the next calls to ReadKey
+// return the rest of the text as more KeyPaste keys, until the end
+// delimiter is read (see Input.PasteInfo to tell them apart). This way, the
+// pasted text is never decoded as regular keys. As for the other keys,
+// Input.Bytes returns the raw bytes of each KeyPaste key, including the
+// start delimiter for the first one and the end delimiter for the last one.
+func WithPaste() Option {
+	return func(i *Input) {
+		i.pasteOn = true
+	}
+}
+
+// defaultPasteLimit is the default maximum size of a pasted text.
+const defaultPasteLimit = 1 << 20
+
+// WithPasteLimit sets the maximum size in bytes of the text of a KeyPaste
+// key decoded with the WithPaste option, so that a long paste or a missing
+// end delimiter does not make the Input buffer all the input. When the text
+// read so far reaches max bytes, it is returned as a KeyPaste key (it may
+// exceed max by up to the size of the buffer of the Input) and the rest of
+// the paste is returned by the next calls to ReadKey. The default limit is
+// 1 MiB, a value <= 0 sets the default.
+func WithPasteLimit(max int) Option {
+	return func(i *Input) {
+		i.maxPaste = max
+	}
+}
+
+// WithPasteSanitize removes the control characters from the text of the
+// pastes decoded with the WithPaste option, except for tab, carriage return
+// and newline, so that a pasted text cannot inject escape sequences (e.g.
+// to change the title of the terminal) when the application displays it.
+// PasteInfo.Sanitized reports whether characters were removed, Input.Bytes
+// still returns the raw bytes.
+func WithPasteSanitize() Option {
+	return func(i *Input) {
+		i.pasteCC = true
+	}
+}
+
+// PasteInfo is the metadata of a key of type KeyPaste, see Input.PasteInfo.
+type PasteInfo struct {
+	// Len is the length in bytes of the text of the paste returned so far,
+	// including the text of this key.
+	Len int
+
+	// Partial is true if the paste continues in the next KeyPaste key,
+	// because the text reached the paste limit or a read returned no byte
+	// before the end delimiter.
+	Partial bool
+
+	// Truncated is true if the text of the key was cut because it reached
+	// the paste limit (see WithPasteLimit), Partial is then true too.
+	Truncated bool
+
+	// Elapsed is the time between the read of the start delimiter and the
+	// return of the key.
+	Elapsed time.Duration
+
+	// Sanitized is true if control characters were removed from the text of
+	// the key, see WithPasteSanitize.
+	Sanitized bool
+}
+
+// PasteInfo returns the metadata of the last key of type KeyPaste, e.g. to
+// ask for a confirmation before inserting a long paste. It should be called
+// only after a key of type KeyPaste has been received from ReadKey, and
+// before any other call to ReadKey.
+func (i *Input) PasteInfo() PasteInfo {
+	return i.pinfo
+}
+
+// Paste returns the text of the last key of type KeyPaste, without the
+// delimiters. The text is valid only until the next call to ReadKey and
+// should not be modified. It should be called only after a key of type
+// KeyPaste has been received from ReadKey.
+func (i *Input) Paste() []byte {
+	return i.paste[:len(i.paste):len(i.paste)]
+}
+
+// decodes the bracketed paste at the start of the buffer, reading from r
+// until the end delimiter is found, and returns a KeyPaste key. The text
+// starts at index start of the buffer, after the start delimiter or at 0 if
+// the paste continues from a previous KeyPaste key. The buffer is used to
+// read the text, which is accumulated in i.paste, and the raw bytes moved
+// out of the buffer are accumulated in i.praw. If the paste limit is
+// reached or a read returns no byte before the end delimiter, the text read
+// so far is returned and i.pmore is set so that the next call continues
+// the paste.
+func (i *Input) decodePaste(r io.Reader, start int) (Key, error) {
+	i.paste = i.paste[:0]
+	i.praw = i.praw[:0]
+	if start > 0 {
+		i.pinfo = PasteInfo{}
+		i.pstart = i.clock.Now()
+	}
+	i.pmore = true
+	max := i.maxPaste
+	if max <= 0 {
+		max = defaultPasteLimit
+	}
+
+	for {
+		b := i.buf[start:i.len]
+		if ix := bytes.Index(b, []byte(pasteEndSeq)); ix >= 0 {
+			i.paste = append(i.paste, b[:ix]...)
+			i.sz = start + ix + len(pasteEndSeq)
+			i.pmore = false
+			return i.pasteKey(false), nil
+		}
+
+		// move the text out of the buffer, except for the bytes that may be
+		// the start of the end delimiter.
+		keep := endSeqPrefix(b)
+		i.paste = append(i.paste, b[:len(b)-keep]...)
+		i.praw = append(i.praw, i.buf[:i.len-keep]...)
+		if i.mirror != nil {
+			i.mirror.Write(i.buf[:i.len-keep])
+		}
+		copy(i.buf, b[len(b)-keep:])
+		i.len, start = keep, 0
+
+		// the kept bytes are decoded with the rest of the paste by the next
+		// call
+		i.sz = 0
+		if len(i.paste) >= max {
+			return i.pasteKey(true), nil
+		}
+
+		i.enter(PhaseRead)
+		n, err := r.Read(i.buf[i.len:])
+		i.exit(PhaseRead)
+		i.stats.Bytes += uint64(n)
+		i.len += n
+		if n == 0 {
+			if len(i.praw) > 0 {
+				return i.pasteKey(false), nil
+			}
+			return 0, i.readErr(err)
+		}
+	}
+}
+
+// returns the length of the longest suffix of b that is a prefix of the end
+// delimiter of a paste.
+func endSeqPrefix(b []byte) int {
+	for n := len(pasteEndSeq) - 1; n > 0; n-- {
+		if bytes.HasSuffix(b, []byte(pasteEndSeq[:n])) {
+			return n
+		}
+	}
+	return 0
+}
+
+// returns the KeyPaste key of the paste decoded, sets its metadata and sets
+// i.rbuf to its raw bytes if it was read in multiple reads.
+func (i *Input) pasteKey(truncated bool) Key {
+	if len(i.praw) > 0 {
+		i.praw = append(i.praw, i.buf[:i.sz]...)
+		i.rbuf = i.praw
+	}
+
+	var sanitized bool
+	if i.pasteCC {
+		i.paste, sanitized = sanitizePaste(i.paste)
+	}
+	i.pinfo.Len += len(i.paste)
+	i.pinfo.Partial = i.pmore
+	i.pinfo.Truncated = truncated
+	i.pinfo.Elapsed = i.clock.Now().Sub(i.pstart)
+	i.pinfo.Sanitized = sanitized
+	return keyFromTypeMod(KeyPaste, ModNone)
+}
+
+// removes the control characters from b in place, except for tab, carriage
+// return and newline, and returns the result and true if any was removed.
+func sanitizePaste(b []byte) ([]byte, bool) {
+	out := b[:0]
+	for _, c := range b {
+		if (c < 0x20 && c != '\t' && c != '\r' && c != '\n') || c == 0x7f {
+			continue
+		}
+		out = append(out, c)
+	}
+	return out, len(out) < len(b)
+}
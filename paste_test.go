@@ -0,0 +1,255 @@
+package zzterm
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInput_ReadKey_Paste(t *testing.T) {
+	long := strings.Repeat("line\r\n", 50)
+	cases := []struct {
+		desc   string
+		chunks []string
+		paste  string
+		next   Key
+	}{
+		{"single read", []string{"\x1b[200~hello\rworld\x1b[201~"}, "hello\rworld", 0},
+		{"empty", []string{"\x1b[200~\x1b[201~"}, "", 0},
+		{"split end", []string{"\x1b[200~abc\x1b[2", "01~x"}, "abc", 'x'},
+		{"split end at ESC", []string{"\x1b[200~abc\x1b", "[201~x"}, "abc", 'x'},
+		{"split end before final", []string{"\x1b[200~abc\x1b[201", "~x"}, "abc", 'x'},
+		{"split end bytewise", []string{"\x1b[200~abc", "\x1b", "[", "2", "0", "1", "~", "x"}, "abc", 'x'},
+		{"split start", []string{"\x1b[200~", "abc\x1b[201~"}, "abc", 0},
+		{"near miss final", []string{"\x1b[200~a\x1b[201xb\x1b[201~"}, "a\x1b[201xb", 0},
+		{"near miss split", []string{"\x1b[200~a\x1b[201", "xb\x1b[201~"}, "a\x1b[201xb", 0},
+		{"near miss param", []string{"\x1b[200~a\x1b[2015~\x1b[201;2~b\x1b[201~"}, "a\x1b[2015~\x1b[201;2~b", 0},
+		{"near miss prefix", []string{"\x1b[200~a\x1b[20\x1b[201~x"}, "a\x1b[20", 'x'},
+		{"ESC before end", []string{"\x1b[200~a\x1b\x1b[201~"}, "a\x1b", 0},
+		{"nested start", []string{"\x1b[200~a\x1b[200~b\x1b[201~x"}, "a\x1b[200~b", 'x'},
+		{"binary", []string{"\x1b[200~\x00\xff\x1b[\x9b201~\x1b[201~"}, "\x00\xff\x1b[\x9b201~", 0},
+		{"many reads", []string{"\x1b[200~" + long[:100], long[100:200], long[200:], "\x1b[201~"}, long, 0},
+		{"with escape", []string{"\x1b[200~a\x1b[Ab\x1b[201~"}, "a\x1b[Ab", 0},
+		{"timeout", []string{"\x1b[200~abc", ""}, "abc", 0},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			var mirror bytes.Buffer
+			input := NewInput(WithPaste(), WithRawMirror(&mirror))
+			r := &scriptReader{chunks: c.chunks}
+			k, err := input.ReadKey(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if k.Type() != KeyPaste {
+				t.Fatalf("want KeyPaste, got %s", k)
+			}
+			if got := string(input.Paste()); got != c.paste {
+				t.Errorf("want paste %q, got %q", c.paste, got)
+			}
+			if c.next != 0 {
+				if k, err := input.ReadKey(r); err != nil || k != c.next {
+					t.Errorf("want %s, got %s, %v", c.next, k, err)
+				}
+			}
+			raw := strings.Join(c.chunks, "")
+			if got := string(input.Bytes()); c.next == 0 && got != raw {
+				t.Errorf("want bytes %q, got %q", raw, got)
+			}
+			if mirror.String() != raw {
+				t.Errorf("want mirror %q, got %q", raw, mirror.String())
+			}
+		})
+	}
+
+	t.Run("limit", func(t *testing.T) {
+		var mirror bytes.Buffer
+		input := NewInput(WithPaste(), WithPasteLimit(100), WithRawMirror(&mirror))
+		r := &scriptReader{chunks: []string{"\x1b[200~" + long[:100], long[100:200], "ab\x1b[201~x"}}
+
+		// the paste is returned in multiple KeyPaste keys, none of the text is
+		// decoded as regular keys
+		var paste, raw string
+		for {
+			k, err := input.ReadKey(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if k.Type() != KeyPaste {
+				if k != 'x' {
+					t.Fatalf("want 'x' after the paste, got %s", k)
+				}
+				break
+			}
+			if n := len(input.Paste()); n > 100+len(input.buf) {
+				t.Errorf("want paste of at most %d bytes, got %d", 100+len(input.buf), n)
+			}
+			paste += string(input.Paste())
+			raw += string(input.Bytes())
+		}
+		if want := long[:200] + "ab"; paste != want {
+			t.Errorf("want paste %q, got %q", want, paste)
+		}
+		if want := "\x1b[200~" + long[:200] + "ab\x1b[201~"; raw != want {
+			t.Errorf("want bytes %q, got %q", want, raw)
+		}
+		if want := "\x1b[200~" + long[:200] + "ab\x1b[201~x"; mirror.String() != want {
+			t.Errorf("want mirror %q, got %q", want, mirror.String())
+		}
+	})
+
+	// the text after an interrupted paste is returned as KeyPaste keys until
+	// the end delimiter, never as regular keys.
+	for _, c := range []struct {
+		desc   string
+		limit  int
+		chunks []string
+		want   []string // KeyPaste texts as P(text), other keys and errors
+	}{
+		{"limit then command", 8, []string{"\x1b[200~abcdefghijkl", "mn\rrm -rf /\r", "\x1b[201~"},
+			[]string{"P(abcdefghijkl)", "P(mn\rrm -rf /\r)", "P()"}},
+		{"timeout then command", 0, []string{"\x1b[200~line1\r", "", "line2\r", "\x1b[201~x"},
+			[]string{"P(line1\r)", "P(line2\r)", "x"}},
+		{"timeout in end", 0, []string{"\x1b[200~a\x1b[2", "", "01~x"},
+			[]string{"P(a)", "P()", "x"}},
+		{"timeouts", 0, []string{"\x1b[200~a", "", "", "b\x1b[201~"},
+			[]string{"P(a)", ErrTimeout.Error(), "P(b)"}},
+		{"timeout at start", 0, []string{"\x1b[200~", "", "a\x1b[201~"},
+			[]string{"P()", "P(a)"}},
+	} {
+		t.Run(c.desc, func(t *testing.T) {
+			input := NewInput(WithPaste(), WithPasteLimit(c.limit))
+			r := &scriptReader{chunks: c.chunks}
+
+			var got []string
+			for len(r.chunks) > 0 || input.buffered() {
+				k, err := input.ReadKey(r)
+				switch {
+				case err != nil:
+					got = append(got, err.Error())
+				case k.Type() == KeyPaste:
+					got = append(got, "P("+string(input.Paste())+")")
+				case k.Type() == KeyRune:
+					got = append(got, string(k.Rune()))
+				default:
+					got = append(got, k.String())
+				}
+			}
+			if strings.Join(got, " ") != strings.Join(c.want, " ") {
+				t.Errorf("want %q, got %q", c.want, got)
+			}
+		})
+	}
+
+	t.Run("info", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+		input := NewInput(WithPaste(), WithPasteLimit(4), WithPasteSanitize(), WithClock(clock))
+		r := &clockedReader{clock: clock, delay: time.Second, chunks: []string{
+			"\x1b[200~abcdef", "gh", "", "i\x1b]0;x\a\r\n\x1b[201~",
+			"\x1b[200~h\x1b[201~",
+		}}
+
+		want := []struct {
+			text string
+			info PasteInfo
+		}{
+			{"abcdef", PasteInfo{Len: 6, Partial: true, Truncated: true}},
+			{"gh", PasteInfo{Len: 8, Partial: true, Elapsed: 2 * time.Second}},
+			{"i]0;x\r\n", PasteInfo{Len: 15, Elapsed: 3 * time.Second, Sanitized: true}},
+			{"h", PasteInfo{Len: 1}},
+		}
+		var events []Event
+		for j, w := range want {
+			k, err := input.ReadKey(r)
+			if err != nil || k.Type() != KeyPaste {
+				t.Fatalf("[%d]: want KeyPaste, got %s, %v", j, k, err)
+			}
+			if got := string(input.Paste()); got != w.text {
+				t.Errorf("[%d]: want paste %q, got %q", j, w.text, got)
+			}
+			if got := input.PasteInfo(); got != w.info {
+				t.Errorf("[%d]: want info %+v, got %+v", j, w.info, got)
+			}
+			events = append(events, input.event(k))
+		}
+
+		// the info is kept by the queued events
+		input.queue = events
+		for j, w := range want {
+			if _, err := input.ReadKey(r); err != nil {
+				t.Fatal(err)
+			}
+			if got := input.PasteInfo(); got != w.info {
+				t.Errorf("[%d]: replay: want info %+v, got %+v", j, w.info, got)
+			}
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		input := NewInput()
+		k, err := input.ReadKey(strings.NewReader("\x1b[200~"))
+		if err != nil || k.Type() != KeyESCSeq {
+			t.Errorf("want KeyESCSeq, got %s, %v", k, err)
+		}
+	})
+
+	t.Run("expect", func(t *testing.T) {
+		input := NewInput(WithPaste())
+		r := &scriptReader{chunks: []string{"\x1b[200~abc\x1b[201~", "\x1b[200~def\x1b[201~", "x"}}
+		if _, err := input.Expect(context.Background(), r, func(ev Event) bool { return ev.Key == 'x' }); err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{"abc", "def"} {
+			k, err := input.ReadKey(r)
+			if err != nil || k.Type() != KeyPaste {
+				t.Fatalf("want KeyPaste, got %s, %v", k, err)
+			}
+			if got := string(input.Paste()); got != want {
+				t.Errorf("want paste %q, got %q", want, got)
+			}
+		}
+	})
+}
+
+func FuzzPaste(f *testing.F) {
+	f.Add([]byte("hello\rworld"), uint8(3))
+	f.Add([]byte("a\x1b[201xb"), uint8(1))
+	f.Add([]byte("\x1b[20\x1b[2"), uint8(2))
+	f.Add([]byte("\x1b[200~\x1b"), uint8(5))
+	f.Add([]byte("\x00\xff\x9b201~"), uint8(7))
+
+	f.Fuzz(func(t *testing.T, text []byte, chunk uint8) {
+		if bytes.Contains(text, []byte(pasteEndSeq)) {
+			t.Skip()
+		}
+		// the start delimiter is read at once, the rest is split in chunks of
+		// size bytes
+		size := int(chunk%16) + 1
+		data := string(text) + pasteEndSeq + "x"
+		chunks := []string{pasteStartSeq}
+		for len(data) > 0 {
+			n := size
+			if n > len(data) {
+				n = len(data)
+			}
+			chunks = append(chunks, data[:n])
+			data = data[n:]
+		}
+
+		input := NewInput(WithPaste())
+		r := &scriptReader{chunks: chunks}
+		k, err := input.ReadKey(r)
+		if err != nil || k.Type() != KeyPaste {
+			t.Fatalf("want KeyPaste, got %s, %v", k, err)
+		}
+		if got := input.Paste(); !bytes.Equal(got, text) {
+			t.Fatalf("want paste %q, got %q", text, got)
+		}
+		// the parser is in sync after the paste
+		if k, err := input.ReadKey(r); err != nil || k != 'x' {
+			t.Fatalf("want 'x' after paste, got %s, %v", k, err)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package zzterm
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// Phase identifies a phase of the processing done by ReadKey.
+type Phase int
+
+// List of ReadKey phases.
+const (
+	PhaseRead   Phase = iota + 1 // reading bytes from the io.Reader
+	PhaseDecode                  // decoding the UTF-8 encoding of a rune
+	PhaseMatch                   // matching an escape sequence to a key
+
+	numPhases = iota
+)
+
+var phaseNames = [...]string{
+	PhaseRead:   "read",
+	PhaseDecode: "decode",
+	PhaseMatch:  "match",
+}
+
+// String returns the name of the phase.
+func (p Phase) String() string {
+	if p > 0 && int(p) < len(phaseNames) {
+		return phaseNames[p]
+	}
+	return "Phase(?)"
+}
+
+// WithPhaseHook sets a function that is called at the start (with end set
+// to false) and at the end (with end set to true) of each phase of ReadKey,
+// on the goroutine that calls ReadKey. This can be used to measure the time
+// spent in each phase, e.g. to attribute CPU between the read syscalls and
+// the decoding of the input. The function must be fast as it may be called
+// many times for each key.
+func WithPhaseHook(fn func(p Phase, end bool)) Option {
+	return func(i *Input) {
+		i.phaseHook = fn
+	}
+}
+
+// WithPprofLabels sets the pprof label "zzterm" of the goroutine that calls
+// ReadKey to the name of the current phase (see Phase) while it runs, so
+// that CPU profiles can be filtered by phase (e.g. with "go tool pprof
+// -tagfocus zzterm=match"). The labels of ctx are added to it, and the
+// goroutine's labels are set to those of ctx at the end of each phase, so
+// ctx should hold the labels of the calling goroutine, if any (typically it
+// is context.Background() or the context passed to pprof.Do).
+func WithPprofLabels(ctx context.Context) Option {
+	return func(i *Input) {
+		var labels [numPhases + 1]context.Context
+		labels[0] = ctx
+		for p := PhaseRead; p <= numPhases; p++ {
+			labels[p] = pprof.WithLabels(ctx, pprof.Labels("zzterm", p.String()))
+		}
+		i.labels = &labels
+	}
+}
+
+func (i *Input) enter(p Phase) {
+	if i.phaseHook != nil {
+		i.phaseHook(p, false)
+	}
+	if i.labels != nil {
+		pprof.SetGoroutineLabels(i.labels[p])
+	}
+}
+
+func (i *Input) exit(p Phase) {
+	if i.labels != nil {
+		pprof.SetGoroutineLabels(i.labels[0])
+	}
+	if i.phaseHook != nil {
+		i.phaseHook(p, true)
+	}
+}
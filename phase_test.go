@@ -0,0 +1,36 @@
+package zzterm
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestInput_ReadKey_PhaseHook(t *testing.T) {
+	var got []string
+	input := NewInput(WithPprofLabels(context.Background()), WithPhaseHook(func(p Phase, end bool) {
+		s := "+" + p.String()
+		if end {
+			s = "-" + p.String()
+		}
+		got = append(got, s)
+	}))
+
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"a", "+read -read +decode -decode"},
+		{"\x1b[A", "+read -read +decode -decode +match -match"},
+		{"", "+read -read"},
+	}
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			got = got[:0]
+			input.ReadKey(strings.NewReader(c.in))
+			if s := strings.Join(got, " "); s != c.want {
+				t.Errorf("want %q, got %q", c.want, s)
+			}
+		})
+	}
+}
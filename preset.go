@@ -0,0 +1,62 @@
+package zzterm
+
+import "time"
+
+// Delays used by the presets to wait for the bytes that follow an ESC and
+// for the rest of an escape sequence split over multiple reads.
+const (
+	presetESCTimeout = 50 * time.Millisecond
+	presetSeqTimeout = 100 * time.Millisecond
+)
+
+// PresetTUI returns an option that groups the options commonly used by
+// full-screen terminal applications: the decoding of mouse events
+// (WithMouse), focus events (WithFocus) and bracketed paste (WithPaste), and
+// delays of 50ms to tell a lone ESC from the start of a sequence
+// (WithESCTimeout) and of 100ms for the sequences split over multiple reads
+// (WithSeqTimeout). The delays require a reader with a read timeout (see
+// the termreader package).
+//
+// It is the responsibility of the caller to enable the matching features on
+// the terminal, e.g. with:
+//
+//	EnableFeatures(w, Mouse(MouseAny), Focus(), BracketedPaste())
+//
+// Terminal resize is not reported by the terminal input, it must be handled
+// separately (e.g. with the SIGWINCH signal on Unix). The options passed
+// after the preset to NewInput override its settings.
+func PresetTUI() Option {
+	return presetOf(
+		WithMouse(),
+		WithFocus(),
+		WithPaste(),
+		WithESCTimeout(presetESCTimeout),
+		WithSeqTimeout(presetSeqTimeout),
+	)
+}
+
+// PresetREPL returns an option that groups the options commonly used by
+// line-oriented interactive programs such as shells and REPLs: the decoding
+// of bracketed paste (WithPaste) and of the keys pressed with Alt as an ESC
+// prefix (WithAltPrefix), and the same delays as PresetTUI for the ESC and
+// the split sequences. Mouse and focus events are not decoded.
+//
+// It is the responsibility of the caller to enable bracketed paste on the
+// terminal, e.g. with EnableFeatures(w, BracketedPaste()). The options
+// passed after the preset to NewInput override its settings.
+func PresetREPL() Option {
+	return presetOf(
+		WithPaste(),
+		WithAltPrefix(),
+		WithESCTimeout(presetESCTimeout),
+		WithSeqTimeout(presetSeqTimeout),
+	)
+}
+
+func presetOf(opts ...Option) Option {
+	return func(i *Input) {
+		for _, o := range opts {
+			o(i)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package zzterm
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPresets(t *testing.T) {
+	cases := []struct {
+		desc   string
+		preset Option
+		in     string
+		want   Key
+	}{
+		{"tui mouse", PresetTUI(), "\x1b[<0;1;2M", NewKey(KeyMouse, ModNone)},
+		{"tui focus", PresetTUI(), "\x1b[I", NewKey(KeyFocusIn, ModNone)},
+		{"tui paste", PresetTUI(), "\x1b[200~abc\x1b[201~", NewKey(KeyPaste, ModNone)},
+		{"tui alt", PresetTUI(), "\x1bx", NewKey(KeyESCSeq, ModNone)},
+		{"repl mouse", PresetREPL(), "\x1b[<0;1;2M", NewKey(KeyESCSeq, ModNone)},
+		{"repl focus", PresetREPL(), "\x1b[I", NewKey(KeyESCSeq, ModNone)},
+		{"repl paste", PresetREPL(), "\x1b[200~abc\x1b[201~", NewKey(KeyPaste, ModNone)},
+		{"repl alt", PresetREPL(), "\x1bx", NewRuneKey('x', ModAlt)},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			input := NewInput(c.preset)
+			k, err := input.ReadKey(strings.NewReader(c.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if k != c.want {
+				t.Errorf("want %s, got %s", c.want, k)
+			}
+			if input.escDelay != presetESCTimeout || input.seqDelay != presetSeqTimeout {
+				t.Errorf("want timeouts %s and %s, got %s and %s", presetESCTimeout, presetSeqTimeout, input.escDelay, input.seqDelay)
+			}
+		})
+	}
+
+	// options after the preset override it
+	input := NewInput(PresetTUI(), WithESCTimeout(time.Second))
+	if input.escDelay != time.Second {
+		t.Errorf("want ESC timeout overridden, got %s", input.escDelay)
+	}
+}
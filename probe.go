@@ -0,0 +1,105 @@
+package zzterm
+
+import (
+	"fmt"
+	"io"
+)
+
+// Probe is a query sent to the terminal to detect its capabilities. The
+// terminal replies to it with an escape sequence that is read as input.
+type Probe int
+
+// List of supported probes.
+const (
+	// ProbeSecondaryDA requests the secondary device attributes (DA2), the
+	// terminal replies with "ESC [ > Pp ; Pv ; Pc c".
+	ProbeSecondaryDA Probe = iota + 1
+
+	// ProbeCursorPosition requests the cursor position (DSR 6), the
+	// terminal replies with "ESC [ Pr ; Pc R". The reply is ambiguous with
+	// F3 pressed with modifiers on some terminals (e.g. "ESC [ 1 ; 5 R").
+	ProbeCursorPosition
+
+	// ProbeTermParams requests the DEC terminal parameters, see
+	// SeqRequestTermParams and WithTermParams.
+	ProbeTermParams
+
+	// ProbeDeviceAttributes requests the primary device attributes (DA1),
+	// see SeqRequestDeviceAttributes and WithDeviceAttributes. As all the
+	// terminals compatible with the VT100 reply to it, it is usually sent
+	// last so that its reply marks the end of the replies to the probes.
+	ProbeDeviceAttributes
+)
+
+var probeSeqs = [...]string{
+	ProbeSecondaryDA:      "\x1b[>c",
+	ProbeCursorPosition:   "\x1b[6n",
+	ProbeTermParams:       SeqRequestTermParams,
+	ProbeDeviceAttributes: SeqRequestDeviceAttributes,
+}
+
+var probeNames = [...]string{
+	ProbeSecondaryDA:      "SecondaryDA",
+	ProbeCursorPosition:   "CursorPosition",
+	ProbeTermParams:       "TermParams",
+	ProbeDeviceAttributes: "DeviceAttributes",
+}
+
+// String returns the name of the probe.
+func (p Probe) String() string {
+	if p > 0 && int(p) < len(probeNames) {
+		return probeNames[p]
+	}
+	return fmt.Sprintf("Probe(%d)", int(p))
+}
+
+// Sequence returns the escape sequence to write to the terminal to send the
+// probe, or an empty string if p is not a supported probe.
+func (p Probe) Sequence() string {
+	if p > 0 && int(p) < len(probeSeqs) {
+		return probeSeqs[p]
+	}
+	return ""
+}
+
+// WriteProbes writes the sequences of the probes to the terminal
+// represented by w in a single write, in the order of the arguments. It
+// returns an error without writing anything if a probe is not supported.
+//
+// Not all probes are safe to send to all terminals, as some terminals
+// print the queries that they do not support or mishandle them. To probe a
+// terminal unconditionally at startup, use the probes of its profile, e.g.:
+//
+//	zzterm.WriteProbes(w, zzterm.ProfileXterm.Probes()...)
+func WriteProbes(w io.Writer, probes ...Probe) error {
+	var b []byte
+	for _, p := range probes {
+		seq := p.Sequence()
+		if seq == "" {
+			return fmt.Errorf("zzterm: unsupported probe: %s", p)
+		}
+		b = append(b, seq...)
+	}
+	if len(b) == 0 {
+		return nil
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+// Probes returns the probes that are safe to send to the terminal of the
+// profile, that is, the probes that are known not to disturb it, with
+// ProbeDeviceAttributes last. Only ProbeDeviceAttributes is returned for the
+// profiles that have no specific list, and for a nil profile.
+func (p *Profile) Probes() []Probe {
+	if p == nil || len(p.probes) == 0 {
+		return []Probe{ProbeDeviceAttributes}
+	}
+	return append([]Probe(nil), p.probes...)
+}
+
+// sets the probes that are safe to send to the terminal of p and returns p.
+func (p *Profile) withProbes(probes ...Probe) *Profile {
+	p.probes = probes
+	return p
+}
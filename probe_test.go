@@ -0,0 +1,71 @@
+package zzterm
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteProbes(t *testing.T) {
+	var w countWriter
+	if err := WriteProbes(&w, ProbeCursorPosition, ProbeDeviceAttributes); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\x1b[6n" + SeqRequestDeviceAttributes; w.String() != want || w.writes != 1 {
+		t.Errorf("want %q in 1 write, got %q in %d", want, w.String(), w.writes)
+	}
+
+	w.Reset()
+	w.writes = 0
+	if err := WriteProbes(&w, ProbeDeviceAttributes, Probe(99)); err == nil {
+		t.Error("want error for unsupported probe")
+	}
+	if err := WriteProbes(&w); err != nil {
+		t.Fatal(err)
+	}
+	if w.writes != 0 {
+		t.Errorf("want no write, got %d", w.writes)
+	}
+}
+
+func TestProfile_Probes(t *testing.T) {
+	for _, name := range append(Profiles(), "") {
+		p := LookupProfile(name)
+		probes := p.Probes()
+		if len(probes) == 0 || probes[len(probes)-1] != ProbeDeviceAttributes {
+			t.Errorf("%s: want DA1 last, got %v", p, probes)
+		}
+		for _, pr := range probes {
+			if pr.Sequence() == "" {
+				t.Errorf("%s: invalid probe %s", p, pr)
+			}
+		}
+	}
+
+	if got := ProfileScreen.Probes(); len(got) != 1 {
+		t.Errorf("screen: want only DA1, got %v", got)
+	}
+
+	// modifying the returned slice does not change the profile
+	probes := ProfileXterm.Probes()
+	probes[0] = 0
+	if ProfileXterm.Probes()[0] == 0 {
+		t.Error("want a copy")
+	}
+
+	var buf bytes.Buffer
+	if err := WriteProbes(&buf, ProfileXterm.Probes()...); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\x1b[>c\x1b[6n\x1b[1x\x1b[c"; buf.String() != want {
+		t.Errorf("want %q, got %q", want, buf.String())
+	}
+}
+
+func TestProbe_String(t *testing.T) {
+	if s := ProbeDeviceAttributes.String(); s != "DeviceAttributes" {
+		t.Errorf("want DeviceAttributes, got %s", s)
+	}
+	if s := Probe(0).String(); s != "Probe(0)" {
+		t.Errorf("want Probe(0), got %s", s)
+	}
+}
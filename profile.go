@@ -0,0 +1,133 @@
+package zzterm
+
+import "sort"
+
+// Profile is a predefined mapping of escape sequences to special keys for
+// a specific terminal (or family of terminals). It can be used instead of
+// a terminfo-like map to configure an Input via the WithProfile option.
+type Profile struct {
+	name   string
+	esc    map[string]Key
+	opts   []Option // decoding options specific to the terminal
+	probes []Probe  // probes safe to send, see Probes
+}
+
+// Name returns the name of the profile.
+func (p *Profile) Name() string {
+	return p.name
+}
+
+// String returns the string representation of the profile.
+func (p *Profile) String() string {
+	return "Profile(" + p.name + ")"
+}
+
+// WithProfile sets the mapping of escape sequences to special keys to the
+// one of the provided profile. It has the same effect as the WithESCSeq
+// option, and if both are provided, the last one wins. A nil profile uses
+// the default mapping. It also applies the decoding options specific to
+// the terminal of the profile, if any (e.g. WithKitty for the terminals
+// that support the kitty keyboard protocol).
+func WithProfile(p *Profile) Option {
+	return func(i *Input) {
+		if p == nil {
+			i.esc = cloneEscMap(defaultEsc)
+			return
+		}
+		i.esc = cloneEscMap(p.esc)
+		for _, opt := range p.opts {
+			opt(i)
+		}
+	}
+}
+
+// List of built-in profiles.
+var (
+	// ProfileXterm is the profile for xterm and compatible terminals. This is
+	// the default mapping used when no WithESCSeq nor WithProfile option is
+	// provided.
+	ProfileXterm = (&Profile{name: "xterm", esc: defaultEsc}).withProbes(
+		ProbeSecondaryDA, ProbeCursorPosition, ProbeTermParams, ProbeDeviceAttributes)
+
+	// ProfileScreen is the profile for applications running inside GNU
+	// screen, which rewrites the key sequences of the outer terminal to its
+	// own (e.g. ESC [ 1 ~ for Home and ESC [ 4 ~ for End) regardless of the
+	// terminal it runs in.
+	ProfileScreen = newProfile("screen", defaultEsc, map[string]Key{
+		"\x1b[1~":  keyFromTypeMod(KeyHome, ModNone),
+		"\x1b[4~":  keyFromTypeMod(KeyEnd, ModNone),
+		"\x1bOA":   keyFromTypeMod(KeyUp, ModNone),
+		"\x1bOB":   keyFromTypeMod(KeyDown, ModNone),
+		"\x1bOC":   keyFromTypeMod(KeyRight, ModNone),
+		"\x1bOD":   keyFromTypeMod(KeyLeft, ModNone),
+		"\x1b[11~": keyFromTypeMod(KeyF1, ModNone),
+		"\x1b[12~": keyFromTypeMod(KeyF2, ModNone),
+		"\x1b[13~": keyFromTypeMod(KeyF3, ModNone),
+		"\x1b[14~": keyFromTypeMod(KeyF4, ModNone),
+	})
+
+	// ProfileWezTerm is the profile for the WezTerm terminal, which
+	// supports the kitty keyboard protocol (CSI u, when enabled with
+	// EnableKittyKeyboard or the enable_kitty_keyboard setting) and xterm's
+	// modifyOtherKeys mode, so both are decoded (see WithKitty and
+	// WithModifyOtherKeys).
+	ProfileWezTerm = newProfile("wezterm", defaultEsc, nil).
+			withOptions(WithKitty(), WithModifyOtherKeys()).
+			withProbes(ProbeSecondaryDA, ProbeCursorPosition, ProbeDeviceAttributes)
+
+	// ProfileFoot is the profile for the foot terminal, which supports the
+	// kitty keyboard protocol (CSI u, when enabled with EnableKittyKeyboard)
+	// and xterm's modifyOtherKeys mode, so both are decoded (see WithKitty
+	// and WithModifyOtherKeys). It reports the cursor keys, Home and End in
+	// SS3 form when the application cursor keys mode (DECCKM) is enabled.
+	ProfileFoot = newProfile("foot", defaultEsc, map[string]Key{
+		"\x1bOA": keyFromTypeMod(KeyUp, ModNone),
+		"\x1bOB": keyFromTypeMod(KeyDown, ModNone),
+		"\x1bOC": keyFromTypeMod(KeyRight, ModNone),
+		"\x1bOD": keyFromTypeMod(KeyLeft, ModNone),
+		"\x1bOH": keyFromTypeMod(KeyHome, ModNone),
+		"\x1bOF": keyFromTypeMod(KeyEnd, ModNone),
+	}).
+		withOptions(WithKitty(), WithModifyOtherKeys()).
+		withProbes(ProbeSecondaryDA, ProbeCursorPosition, ProbeDeviceAttributes)
+)
+
+var profiles = map[string]*Profile{
+	ProfileXterm.name:   ProfileXterm,
+	ProfileScreen.name:  ProfileScreen,
+	ProfileWezTerm.name: ProfileWezTerm,
+	ProfileFoot.name:    ProfileFoot,
+}
+
+// LookupProfile returns the built-in profile with that name, or nil if
+// there is no such profile.
+func LookupProfile(name string) *Profile {
+	return profiles[name]
+}
+
+// Profiles returns the sorted names of the built-in profiles.
+func Profiles() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// returns a profile with the escape sequences of base, overridden by those
+// of esc.
+func newProfile(name string, base, esc map[string]Key) *Profile {
+	m := cloneEscMap(base)
+	for k, v := range esc {
+		m[k] = v
+	}
+	return &Profile{name: name, esc: m}
+}
+
+// sets the decoding options specific to the terminal of the profile, applied
+// by WithProfile, and returns p.
+func (p *Profile) withOptions(opts ...Option) *Profile {
+	p.opts = opts
+	return p
+}
@@ -0,0 +1,89 @@
+package zzterm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	for _, name := range Profiles() {
+		p := LookupProfile(name)
+		if p == nil || p.Name() != name {
+			t.Errorf("%s: invalid profile %v", name, p)
+		}
+	}
+	if p := LookupProfile("nope"); p != nil {
+		t.Errorf("want nil profile, got %v", p)
+	}
+}
+
+func TestInput_ReadKey_ProfileScreen(t *testing.T) {
+	cases := []testcase{
+		{"a", 'a', KeyRune, ModNone},
+		{"\x1b[1~", -1, KeyHome, ModNone},
+		{"\x1b[4~", -1, KeyEnd, ModNone},
+		{"\x1b[H", -1, KeyHome, ModNone},
+		{"\x1bOP", -1, KeyF1, ModNone},
+		{"\x1b[11~", -1, KeyF1, ModNone},
+		{"\x1b[24~", -1, KeyF12, ModNone},
+		{"\x1bOA", -1, KeyUp, ModNone},
+		{"\x1b[A", -1, KeyUp, ModNone},
+		{"\x1b[5~", -1, KeyPgUp, ModNone},
+	}
+
+	input := NewInput(WithProfile(ProfileScreen))
+	for _, c := range cases {
+		runTestcase(t, c, input)
+	}
+}
+
+func TestInput_ReadKey_ProfileExtended(t *testing.T) {
+	cases := []testcase{
+		{"\x1b[A", -1, KeyUp, ModNone},
+		{"\x1b[1;5D", -1, KeyLeft, ModCtrl},
+		{"\x1b[1;3A", -1, KeyUp, ModAlt},
+		{"\x1b[1;6H", -1, KeyHome, ModCtrl | ModShift},
+		{"\x1b[1;2F", -1, KeyEnd, ModShift},
+		{"\x1b[5;3~", -1, KeyPgUp, ModAlt},
+		{"\x1b[3;5~", -1, KeyDelete, ModCtrl},
+		{"\x1b[1;2P", -1, KeyF1, ModShift},
+	}
+
+	for _, p := range []*Profile{ProfileWezTerm, ProfileFoot} {
+		t.Run(p.Name(), func(t *testing.T) {
+			input := NewInput(WithProfile(p))
+			for _, c := range cases {
+				runTestcase(t, c, input)
+			}
+		})
+	}
+
+	input := NewInput(WithProfile(ProfileFoot))
+	runTestcase(t, testcase{"\x1bOH", -1, KeyHome, ModNone}, input)
+
+	// CSI u and modifyOtherKeys are decoded
+	keys := []struct {
+		in   string
+		want Key
+	}{
+		{"\x1b[97;5u", NewRuneKey('a', ModCtrl)},
+		{"\x1b[57414u", NewKey(KeyCR, ModNone)},
+		{"\x1b[27;5;105~", NewRuneKey('i', ModCtrl)},
+	}
+	for _, p := range []*Profile{ProfileWezTerm, ProfileFoot, ProfileXterm} {
+		input := NewInput(WithProfile(p))
+		for _, c := range keys {
+			k, err := input.ReadKey(strings.NewReader(c.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := c.want
+			if p == ProfileXterm {
+				want = NewKey(KeyESCSeq, ModNone)
+			}
+			if k != want {
+				t.Errorf("%s: %q: want %s, got %s", p, c.in, want, k)
+			}
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package zzterm
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+)
+
+// magic bytes at the start of a stream written by an EventWriter.
+const eventStreamMagic = "zzev\x01"
+
+// flags of an event in the compressed stream.
+const (
+	evSameKey = 1 << iota // the key is the same as the previous event
+	evData                // the event has a data payload
+)
+
+// EventWriter writes a stream of events in a compressed binary format,
+// intended to record long sessions, e.g. with a lot of mouse movement. It
+// differs from the encoding of AppendEvent in that a key repeated from the
+// previous event is not written again, and the coordinates of the mouse
+// events are written as the difference with those of the previous mouse
+// event, which takes a single byte for most mouse moves. The stream can
+// optionally be compressed with gzip.
+//
+// The stream is read with an EventReader, and ConvertEvents converts it to
+// the encoding of AppendEvent.
+type EventWriter struct {
+	w       io.Writer
+	gz      *gzip.Writer // nil if not compressed with gzip
+	buf     []byte
+	started bool
+	prevKey Key
+	prevX   int32
+	prevY   int32
+}
+
+// NewEventWriter returns an EventWriter that writes to w. If gz is true,
+// the stream is compressed with gzip, and Close must be called to write the
+// end of the stream.
+func NewEventWriter(w io.Writer, gz bool) *EventWriter {
+	ew := &EventWriter{w: w}
+	if gz {
+		ew.gz = gzip.NewWriter(w)
+		ew.w = ew.gz
+	}
+	return ew
+}
+
+// WriteEvent writes the event made of the key k, the mouse event m (only
+// written if k is of type KeyMouse) and the data payload (may be nil), as
+// for AppendEvent. The event is written to the underlying writer in a
+// single call.
+func (ew *EventWriter) WriteEvent(k Key, m MouseEvent, data []byte) error {
+	b := ew.buf[:0]
+	if !ew.started {
+		b = append(b, eventStreamMagic...)
+	}
+
+	var flags byte
+	if ew.started && k == ew.prevKey {
+		flags |= evSameKey
+	}
+	if len(data) > 0 {
+		flags |= evData
+	}
+	b = append(b, flags)
+	if flags&evSameKey == 0 {
+		b = appendUvarint(b, uint64(k))
+	}
+	if k.Type() == KeyMouse {
+		b = append(b, mouseButtonByte(m))
+		b = appendVarint(b, int64(m.x)-int64(ew.prevX))
+		b = appendVarint(b, int64(m.y)-int64(ew.prevY))
+		b = appendUvarint(b, uint64(m.buttons))
+		ew.prevX, ew.prevY = m.x, m.y
+	}
+	if flags&evData != 0 {
+		b = appendUvarint(b, uint64(len(data)))
+		b = append(b, data...)
+	}
+	ew.buf = b
+	ew.started = true
+	ew.prevKey = k
+
+	_, err := ew.w.Write(b)
+	return err
+}
+
+// Close writes the end of the gzip stream if the EventWriter compresses
+// with gzip, it does not close the underlying writer. Otherwise it does
+// nothing.
+func (ew *EventWriter) Close() error {
+	if ew.gz != nil {
+		return ew.gz.Close()
+	}
+	return nil
+}
+
+// EventReader reads a stream of events written by an EventWriter.
+type EventReader struct {
+	r       *bufio.Reader
+	buf     []byte
+	started bool
+	prevKey Key
+	prevX   int32
+	prevY   int32
+}
+
+// NewEventReader returns an EventReader that reads from r. The stream may
+// be compressed with gzip or not, this is detected automatically. It
+// returns an error if the gzip header is invalid.
+func NewEventReader(r io.Reader) (*EventReader, error) {
+	br := bufio.NewReader(r)
+	if b, _ := br.Peek(2); len(b) == 2 && b[0] == 0x1f && b[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		br = bufio.NewReader(gz)
+	}
+	return &EventReader{r: br}, nil
+}
+
+// ReadEvent reads the next event of the stream. It returns the key, the
+// mouse event (the zero value if the key is not of type KeyMouse) and the
+// data payload, which is only valid until the next call to ReadEvent. It
+// returns io.EOF at the end of the stream, io.ErrUnexpectedEOF if the
+// stream ends in the middle of an event and ErrInvalidEncoding if it is not
+// a valid stream.
+func (er *EventReader) ReadEvent() (k Key, m MouseEvent, data []byte, err error) {
+	defer func() {
+		if err != nil && err != io.EOF {
+			k, m, data = 0, MouseEvent{}, nil
+		}
+	}()
+
+	if !er.started {
+		var magic [len(eventStreamMagic)]byte
+		if _, err := io.ReadFull(er.r, magic[:]); err != nil {
+			return 0, m, nil, err
+		}
+		if string(magic[:]) != eventStreamMagic {
+			return 0, m, nil, ErrInvalidEncoding
+		}
+		er.started = true
+	}
+
+	flags, err := er.r.ReadByte()
+	if err != nil {
+		return 0, m, nil, err // io.EOF at the end of the stream
+	}
+	if flags&^(evSameKey|evData) != 0 {
+		return 0, m, nil, ErrInvalidEncoding
+	}
+
+	k = er.prevKey
+	if flags&evSameKey == 0 {
+		v, err := er.readUvarint(1<<32 - 1)
+		if err != nil {
+			return 0, m, nil, err
+		}
+		k = Key(v)
+	}
+	if k.Type() == KeyMouse {
+		b, err := er.r.ReadByte()
+		if err != nil {
+			return 0, m, nil, unexpectedEOF(err)
+		}
+		m.setButtonByte(b)
+		dx, err := er.readVarint()
+		if err != nil {
+			return 0, m, nil, err
+		}
+		dy, err := er.readVarint()
+		if err != nil {
+			return 0, m, nil, err
+		}
+		x, y := int64(er.prevX)+dx, int64(er.prevY)+dy
+		if x < 0 || x > 1<<31-1 || y < 0 || y > 1<<31-1 {
+			return 0, m, nil, ErrInvalidEncoding
+		}
+		btns, err := er.readUvarint(1<<16 - 1)
+		if err != nil {
+			return 0, m, nil, err
+		}
+		m.x, m.y, m.buttons = int32(x), int32(y), uint16(btns)
+		er.prevX, er.prevY = m.x, m.y
+	}
+	if flags&evData != 0 {
+		l, err := er.readUvarint(1<<31 - 1)
+		if err != nil {
+			return 0, m, nil, err
+		}
+		if data, err = er.readData(int(l)); err != nil {
+			return 0, m, nil, unexpectedEOF(err)
+		}
+	}
+	er.prevKey = k
+	return k, m, data, nil
+}
+
+// dataChunk is the size of the chunks in which the data payload of an event
+// is read when it does not fit in the buffer of the EventReader.
+const dataChunk = 32 << 10
+
+// reads the data payload of l bytes of an event. The buffer grows with the
+// bytes actually read, so that a crafted length cannot force a large
+// allocation before the stream ends.
+func (er *EventReader) readData(l int) ([]byte, error) {
+	if cap(er.buf) >= l {
+		data := er.buf[:l:l]
+		_, err := io.ReadFull(er.r, data)
+		return data, err
+	}
+
+	b := er.buf[:0]
+	for len(b) < l {
+		n := l - len(b)
+		if n > dataChunk {
+			n = dataChunk
+		}
+		if cap(b)-len(b) < n {
+			// grow by doubling, up to l
+			c := 2*cap(b) + n
+			if c > l {
+				c = l
+			}
+			nb := make([]byte, len(b), c)
+			copy(nb, b)
+			b = nb
+		}
+		m, err := io.ReadFull(er.r, b[len(b):len(b)+n])
+		b = b[:len(b)+m]
+		er.buf = b
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b[:l:l], nil
+}
+
+// reads a uvarint in the middle of an event, values greater than max are
+// invalid.
+func (er *EventReader) readUvarint(max uint64) (uint64, error) {
+	b, err := er.readVarintBytes()
+	if err != nil {
+		return 0, err
+	}
+	v, n := binary.Uvarint(b)
+	if n <= 0 || v > max {
+		return 0, ErrInvalidEncoding
+	}
+	return v, nil
+}
+
+// reads a zig-zag encoded varint in the middle of an event.
+func (er *EventReader) readVarint() (int64, error) {
+	b, err := er.readVarintBytes()
+	if err != nil {
+		return 0, err
+	}
+	v, n := binary.Varint(b)
+	if n <= 0 {
+		return 0, ErrInvalidEncoding
+	}
+	return v, nil
+}
+
+// reads the bytes of a varint, up to the first byte without the
+// continuation bit.
+func (er *EventReader) readVarintBytes() ([]byte, error) {
+	var b [binary.MaxVarintLen64]byte
+	for i := range b {
+		c, err := er.r.ReadByte()
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		b[i] = c
+		if c < 0x80 {
+			return b[:i+1], nil
+		}
+	}
+	return nil, ErrInvalidEncoding
+}
+
+// ConvertEvents reads the stream of events written by an EventWriter from
+// src (compressed with gzip or not) and writes them to dst in the encoding
+// of AppendEvent. It returns the number of events converted.
+func ConvertEvents(dst io.Writer, src io.Reader) (int, error) {
+	er, err := NewEventReader(src)
+	if err != nil {
+		return 0, err
+	}
+	var buf []byte
+	var n int
+	for {
+		k, m, data, err := er.ReadEvent()
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		buf = AppendEvent(buf[:0], k, m, data)
+		if _, err := dst.Write(buf); err != nil {
+			return n, err
+		}
+		n++
+	}
+}
+
+func appendVarint(dst []byte, v int64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(buf[:], v)
+	return append(dst, buf[:n]...)
+}
+
+// returns io.ErrUnexpectedEOF if err is io.EOF, as the stream ends in the
+// middle of an event, otherwise err.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
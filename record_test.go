@@ -0,0 +1,171 @@
+package zzterm
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+type recordedEvent struct {
+	k    Key
+	m    MouseEvent
+	data string
+}
+
+var recordedEvents = []recordedEvent{
+	{Key('a'), MouseEvent{}, ""},
+	{Key('a'), MouseEvent{}, ""},
+	{keyFromTypeMod(KeyUp, ModShift|ModCtrl), MouseEvent{}, ""},
+	{keyFromTypeMod(KeyMouse, ModNone), MouseEvent{x: 10, y: 20}, ""},
+	{keyFromTypeMod(KeyMouse, ModNone), MouseEvent{x: 11, y: 19}, ""},
+	{keyFromTypeMod(KeyMouse, ModShift), MouseEvent{buttonID: 3, pressed: true, x: 1, y: 542, buttons: 0b100}, ""},
+	{keyFromTypeMod(KeyMouse, ModShift), MouseEvent{buttonID: 1, clamped: true, x: 1<<31 - 1, y: 0}, ""},
+	{keyFromTypeMod(KeyESCSeq, ModNone), MouseEvent{}, "\x1b[abc"},
+	{keyFromTypeMod(KeyESCSeq, ModNone), MouseEvent{}, "\x1b[abcd"},
+}
+
+func writeRecordedEvents(t *testing.T, w io.Writer, gz bool) {
+	t.Helper()
+	ew := NewEventWriter(w, gz)
+	for _, e := range recordedEvents {
+		if err := ew.WriteEvent(e.k, e.m, []byte(e.data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ew.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEventWriter(t *testing.T) {
+	for _, gz := range []bool{false, true} {
+		var buf bytes.Buffer
+		writeRecordedEvents(t, &buf, gz)
+
+		er, err := NewEventReader(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, want := range recordedEvents {
+			k, m, data, err := er.ReadEvent()
+			if err != nil {
+				t.Fatalf("%t [%d]: %v", gz, i, err)
+			}
+			if k != want.k || m != want.m || string(data) != want.data {
+				t.Fatalf("%t [%d]: want %s %s %q, got %s %s %q", gz, i, want.k, want.m, want.data, k, m, data)
+			}
+		}
+		if _, _, _, err := er.ReadEvent(); err != io.EOF {
+			t.Fatalf("%t: want io.EOF, got %v", gz, err)
+		}
+	}
+}
+
+func TestEventWriter_Size(t *testing.T) {
+	var plain []byte
+	var buf bytes.Buffer
+	ew := NewEventWriter(&buf, false)
+	k := keyFromTypeMod(KeyMouse, ModNone)
+	for i := 0; i < 1000; i++ {
+		m := MouseEvent{x: int32(1000 + i%7), y: int32(2000 - i%5)}
+		plain = AppendEvent(plain, k, m, nil)
+		if err := ew.WriteEvent(k, m, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if buf.Len() >= len(plain)*2/3 {
+		t.Errorf("want compressed stream smaller than 2/3 of %d bytes, got %d", len(plain), buf.Len())
+	}
+}
+
+func TestConvertEvents(t *testing.T) {
+	var want []byte
+	for _, e := range recordedEvents {
+		want = AppendEvent(want, e.k, e.m, []byte(e.data))
+	}
+
+	for _, gz := range []bool{false, true} {
+		var src, dst bytes.Buffer
+		writeRecordedEvents(t, &src, gz)
+		n, err := ConvertEvents(&dst, &src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != len(recordedEvents) || !bytes.Equal(dst.Bytes(), want) {
+			t.Errorf("%t: want %d events %x, got %d %x", gz, len(recordedEvents), want, n, dst.Bytes())
+		}
+	}
+}
+
+func TestEventReader_Invalid(t *testing.T) {
+	var buf bytes.Buffer
+	writeRecordedEvents(t, &buf, false)
+	full := buf.Bytes()
+
+	// a truncated stream fails in the middle of an event, unless it is
+	// truncated between events.
+	bounds := make(map[int]bool)
+	var b bytes.Buffer
+	ew := NewEventWriter(&b, false)
+	for _, e := range recordedEvents {
+		ew.WriteEvent(e.k, e.m, []byte(e.data))
+		bounds[b.Len()] = true
+	}
+	for i := len(eventStreamMagic); i < len(full); i++ {
+		er, _ := NewEventReader(bytes.NewReader(full[:i]))
+		var err error
+		for err == nil {
+			_, _, _, err = er.ReadEvent()
+		}
+		if bounds[i] || i == len(eventStreamMagic) {
+			if err != io.EOF {
+				t.Errorf("[%d]: want io.EOF, got %v", i, err)
+			}
+		} else if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("[%d]: want io.ErrUnexpectedEOF, got %v", i, err)
+		}
+	}
+
+	cases := map[string]string{
+		"magic": "zzev\x02\x00a",
+		"flags": eventStreamMagic + "\x80a",
+		"key":   eventStreamMagic + "\x00\x80\x80\x80\x80\x10",
+		"coord": eventStreamMagic + "\x00" + string(appendUvarint(nil, uint64(keyFromTypeMod(KeyMouse, ModNone)))) + "\x01\x01\x00\x00",
+	}
+	for name, in := range cases {
+		er, err := NewEventReader(bytes.NewReader([]byte(in)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, _, err := er.ReadEvent(); !errors.Is(err, ErrInvalidEncoding) {
+			t.Errorf("%s: want ErrInvalidEncoding, got %v", name, err)
+		}
+	}
+
+	// a crafted data length does not allocate more than what is read
+	in := append([]byte(eventStreamMagic), evData)
+	in = appendUvarint(in, uint64('a'))
+	in = append(appendUvarint(in, 1<<31-1), "abc"...)
+	er, err := NewEventReader(bytes.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := er.ReadEvent(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("length: want io.ErrUnexpectedEOF, got %v", err)
+	}
+	if c := cap(er.buf); c > 2*dataChunk {
+		t.Errorf("length: want at most %d bytes allocated, got %d", 2*dataChunk, c)
+	}
+
+	// while a large payload is still read in full
+	var large bytes.Buffer
+	data := bytes.Repeat([]byte("abcd"), 3*dataChunk)
+	if err := NewEventWriter(&large, false).WriteEvent(keyFromTypeMod(KeyPaste, ModNone), MouseEvent{}, data); err != nil {
+		t.Fatal(err)
+	}
+	er, _ = NewEventReader(&large)
+	if _, _, got, err := er.ReadEvent(); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("large: want %d bytes, got %d, %v", len(data), len(got), err)
+	}
+}
@@ -0,0 +1,133 @@
+package zzterm
+
+// Rect is a rectangular area of the terminal, in terminal coordinates (the
+// upper left character position on the terminal is 1,1).
+type Rect struct {
+	X, Y int // upper left position
+	W, H int // width and height
+}
+
+// Contains returns true if the position x,y is inside the area.
+func (r Rect) Contains(x, y int) bool {
+	return x >= r.X && x < r.X+r.W && y >= r.Y && y < r.Y+r.H
+}
+
+// PaneHandler is the function called by a Router to handle a key in a
+// pane. If k is of type KeyMouse, m is the mouse event with coordinates
+// relative to the pane (the upper left character position of the pane is
+// 1,1), otherwise m is the zero value.
+type PaneHandler func(k Key, m MouseEvent)
+
+type pane struct {
+	area Rect
+	fn   PaneHandler
+}
+
+// Router dispatches the keys read from a single Input to the handlers of
+// multiple panes, e.g. in an application that displays split views. Mouse
+// events are dispatched to the pane under the mouse (with coordinates
+// translated to the pane), and that pane gets the focus. Other keys are
+// dispatched to the focused pane.
+//
+// While a mouse button is held, all mouse events are dispatched to the
+// pane where it was pressed, even outside its area, so that drag gestures
+// are not split across panes. In that case the local coordinates are
+// clamped to 0 if the mouse is above or to the left of the pane.
+//
+// A Router is not safe for concurrent use.
+type Router struct {
+	panes   []pane
+	focus   int // index of the focused pane, -1 if none
+	capture int // index of the pane capturing the mouse, -1 if none
+}
+
+// NewRouter returns a Router without any pane.
+func NewRouter() *Router {
+	return &Router{focus: -1, capture: -1}
+}
+
+// Add adds a pane covering area and handled by fn, and returns its index.
+// If panes overlap, the last one added is on top. The first pane added
+// gets the focus.
+func (r *Router) Add(area Rect, fn PaneHandler) int {
+	r.panes = append(r.panes, pane{area: area, fn: fn})
+	ix := len(r.panes) - 1
+	if r.focus < 0 {
+		r.focus = ix
+	}
+	return ix
+}
+
+// SetArea changes the area of the pane at index ix, e.g. when the layout
+// changes after a resize of the terminal.
+func (r *Router) SetArea(ix int, area Rect) {
+	r.panes[ix].area = area
+}
+
+// Area returns the area of the pane at index ix.
+func (r *Router) Area(ix int) Rect {
+	return r.panes[ix].area
+}
+
+// Focus returns the index of the focused pane, or -1 if there is no pane.
+func (r *Router) Focus() int {
+	return r.focus
+}
+
+// SetFocus sets the focus to the pane at index ix. It panics if there is
+// no such pane.
+func (r *Router) SetFocus(ix int) {
+	_ = r.panes[ix]
+	r.focus = ix
+}
+
+// Dispatch dispatches the key k read from in (i.e. the key returned by
+// in.ReadKey) to the corresponding pane, see Route.
+func (r *Router) Dispatch(in *Input, k Key) int {
+	var m MouseEvent
+	if k.Type() == KeyMouse {
+		m = in.Mouse()
+	}
+	return r.Route(k, m)
+}
+
+// Route dispatches the key k and, if k is of type KeyMouse, the mouse event
+// m to the corresponding pane. It returns the index of the pane that
+// handled the key, or -1 if no pane handled it (e.g. a mouse event outside
+// any pane).
+func (r *Router) Route(k Key, m MouseEvent) int {
+	if k.Type() != KeyMouse {
+		if r.focus < 0 {
+			return -1
+		}
+		r.panes[r.focus].fn(k, MouseEvent{})
+		return r.focus
+	}
+
+	x, y := m.Coords()
+	ix := r.capture
+	if ix < 0 {
+		for j := len(r.panes) - 1; j >= 0; j-- {
+			if r.panes[j].area.Contains(x, y) {
+				ix = j
+				break
+			}
+		}
+		if ix < 0 {
+			return -1
+		}
+	}
+	if m.Buttons() != 0 {
+		r.capture = ix
+	} else {
+		r.capture = -1
+	}
+	r.focus = ix
+
+	p := r.panes[ix]
+	local := m
+	local.setCoords(x-p.area.X+1, y-p.area.Y+1, defaultCoordLimit)
+	local.clamped = local.clamped || m.clamped
+	p.fn(k, local)
+	return ix
+}
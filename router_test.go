@@ -0,0 +1,89 @@
+package zzterm
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRouter_Route(t *testing.T) {
+	var got []string
+	handler := func(name string) PaneHandler {
+		return func(k Key, m MouseEvent) {
+			if k.Type() == KeyMouse {
+				x, y := m.Coords()
+				var flags string
+				if m.Dragging() {
+					flags += " drag"
+				}
+				if m.Clamped() {
+					flags += " clamped"
+				}
+				got = append(got, fmt.Sprintf("%s:%d@%d,%d%s", name, m.ButtonID(), x, y, flags))
+				return
+			}
+			got = append(got, fmt.Sprintf("%s:%s", name, k))
+		}
+	}
+
+	r := NewRouter()
+	if ix := r.Route(Key('a'), MouseEvent{}); ix != -1 {
+		t.Fatalf("want -1 without pane, got %d", ix)
+	}
+	left := r.Add(Rect{X: 1, Y: 1, W: 40, H: 24}, handler("left"))
+	right := r.Add(Rect{X: 41, Y: 1, W: 40, H: 24}, handler("right"))
+	popup := r.Add(Rect{X: 30, Y: 10, W: 20, H: 5}, handler("popup"))
+
+	mouse := NewKey(KeyMouse, ModNone)
+	press := func(btn, x, y int) MouseEvent {
+		_, m := NewMouseEvent(btn, true, x, y, ModNone)
+		return m
+	}
+	release := func(btn, x, y int) MouseEvent {
+		_, m := NewMouseEvent(btn, false, x, y, ModNone)
+		return m
+	}
+	drag := func(btn, x, y int) MouseEvent {
+		_, m := NewMouseEvent(btn, true, x, y, ModNone)
+		return m.WithMotion(true).WithButtons(1 << (btn - 1))
+	}
+
+	steps := []struct {
+		k    Key
+		m    MouseEvent
+		want int
+	}{
+		{Key('a'), MouseEvent{}, left},
+		{mouse, press(1, 45, 2), right},
+		{Key('b'), MouseEvent{}, right},
+		{mouse, release(1, 45, 2), right},
+		{mouse, press(1, 31, 11), popup},
+		{mouse, drag(1, 35, 12), popup},
+		{mouse, drag(1, 5, 30), popup},
+		{mouse, release(1, 5, 30), popup},
+		{mouse, press(4, 5, 5), left},
+		{mouse, press(1, 100, 100), -1},
+		{Key('c'), MouseEvent{}, left},
+	}
+	for i, s := range steps {
+		if ix := r.Route(s.k, s.m); ix != s.want {
+			t.Errorf("%d: want pane %d, got %d", i, s.want, ix)
+		}
+	}
+
+	want := []string{
+		"left:Key(U+0061 'a')",
+		"right:1@5,2",
+		"right:Key(U+0062 'b')",
+		"right:1@5,2",
+		"popup:1@2,2",
+		"popup:1@6,3 drag",
+		"popup:1@0,21 drag clamped",
+		"popup:1@0,21 clamped",
+		"left:4@5,5",
+		"left:Key(U+0063 'c')",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("want\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
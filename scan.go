@@ -0,0 +1,129 @@
+package zzterm
+
+import "unicode/utf8"
+
+// maximum length of a token returned by ScanKeys, longer sequences are
+// split.
+const maxTokenLen = 128
+
+// ScanKeys is a split function for a bufio.Scanner that returns each key
+// of the raw terminal input as a token, so that it can be decoded by
+// Input.Decode. A token is either a single UTF-8 encoded rune (or a single
+// invalid byte), a complete escape sequence (CSI, SS3, OSC, DCS, APC or
+// PM), an ESC followed by a rune (e.g. an Alt-modified key) or a lone ESC.
+//
+// As for Input.ReadKey, an ESC at the end of the buffered data is a lone
+// ESC, but an incomplete CSI, OSC, DCS, APC or PM sequence at the end of
+// the buffered data requests more data, unless the data is at EOF or the
+// sequence is longer than 128 bytes, in which case the token is the
+// (possibly incomplete) sequence.
+func ScanKeys(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if len(data) == 0 {
+		return 0, nil, nil
+	}
+
+	if data[0] != '\x1b' {
+		if !atEOF && !utf8.FullRune(data) {
+			return 0, nil, nil
+		}
+		_, sz := utf8.DecodeRune(data)
+		return sz, data[:sz], nil
+	}
+
+	if len(data) == 1 {
+		return 1, data[:1], nil
+	}
+
+	var n int
+	switch data[1] {
+	case '\x1b':
+		// ESC followed by ESC, the first one is a lone ESC
+		return 1, data[:1], nil
+	case '[':
+		n = scanCSI(data)
+	case 'O':
+		n = 3
+		if len(data) < n {
+			n = -1
+		}
+	case ']', 'P', '_', '^':
+		n = scanST(data)
+	default:
+		if !atEOF && !utf8.FullRune(data[1:]) {
+			return 0, nil, nil
+		}
+		_, sz := utf8.DecodeRune(data[1:])
+		n = 1 + sz
+	}
+
+	if n < 0 {
+		// incomplete sequence
+		if !atEOF && len(data) < maxTokenLen {
+			return 0, nil, nil
+		}
+		n = len(data)
+	}
+	if n > maxTokenLen {
+		n = maxTokenLen
+	}
+	return n, data[:n], nil
+}
+
+// returns the length of the CSI sequence at the start of b, or -1 if it is
+// incomplete.
+func scanCSI(b []byte) int {
+	for i := 2; i < len(b); i++ {
+		if b[i] >= 0x40 && b[i] <= 0x7e {
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// returns the length of the invalid CSI sequence at the start of b, up to
+// its final byte or up to the next ESC (excluded), or -1 if it is
+// incomplete.
+func skipCSI(b []byte) int {
+	for i := 2; i < len(b); i++ {
+		if b[i] == '\x1b' {
+			return i
+		}
+		if b[i] >= 0x40 && b[i] <= 0x7e {
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// returns the length of the string sequence at the start of b, terminated
+// by BEL or ST (ESC \), or -1 if it is incomplete.
+func scanST(b []byte) int {
+	for i := 2; i < len(b); i++ {
+		switch b[i] {
+		case '\a':
+			return i + 1
+		case '\x1b':
+			if i+1 < len(b) && b[i+1] == '\\' {
+				return i + 2
+			}
+		}
+	}
+	return -1
+}
+
+// Decode decodes the key in tok, which should be a single token as
+// returned by ScanKeys, and returns it as ReadKey would. Any bytes buffered
+// by previous calls to ReadKey are discarded, as well as the events queued
+// by Expect. After the call, the methods that return information on the
+// last key (e.g. Bytes and Mouse) can be called as for ReadKey.
+//
+// It returns ErrTimeout if tok is empty.
+func (i *Input) Decode(tok []byte) (Key, error) {
+	i.sz, i.len, i.stream = 0, 0, nil
+	i.queue = i.queue[:0]
+	if len(tok) > len(i.buf) {
+		tok = tok[:len(i.buf)]
+	}
+	i.tokr.Reset(tok)
+	return i.ReadKey(&i.tokr)
+}
@@ -0,0 +1,101 @@
+package zzterm
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestScanKeys(t *testing.T) {
+	in := "a👪\x1b[A\x1b\x1bOP\x1b]9;hi\a\x1b[<0;1;2M\x1bx\x1b]2;t\x1b\\\xffz\x1b"
+	want := []string{
+		"a",
+		"👪",
+		"\x1b[A",
+		"\x1b",
+		"\x1bOP",
+		"\x1b]9;hi\a",
+		"\x1b[<0;1;2M",
+		"\x1bx",
+		"\x1b]2;t\x1b\\",
+		"\xff",
+		"z",
+		"\x1b",
+	}
+
+	sc := bufio.NewScanner(strings.NewReader(in))
+	sc.Split(ScanKeys)
+	var got []string
+	for sc.Scan() {
+		got = append(got, sc.Text())
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestScanKeys_Incomplete(t *testing.T) {
+	cases := []struct {
+		in    string
+		atEOF bool
+		adv   int
+	}{
+		{"\x1b[1;5", false, 0},
+		{"\x1b[1;5", true, 5},
+		{"\x1b]9;hi", false, 0},
+		{"\x1bO", false, 0},
+		{"\xf0\x9f", false, 0},
+		{"\xf0\x9f", true, 1},
+		{"\x1b[" + strings.Repeat("1", 200), false, maxTokenLen},
+	}
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			adv, tok, err := ScanKeys([]byte(c.in), c.atEOF)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if adv != c.adv || len(tok) != c.adv {
+				t.Errorf("want advance %d, got %d (token %q)", c.adv, adv, tok)
+			}
+		})
+	}
+}
+
+func TestInput_Decode(t *testing.T) {
+	input := NewInput(WithMouse())
+	cases := []struct {
+		tok string
+		typ KeyType
+	}{
+		{"a", KeyRune},
+		{"\x1b", KeyESC},
+		{"\x1b[A", KeyUp},
+		{"\x1b[<0;1;2M", KeyMouse},
+		{"\x1bx", KeyESCSeq},
+		{"\r", KeyCR},
+	}
+	for _, c := range cases {
+		t.Run(c.tok, func(t *testing.T) {
+			// leave some bytes buffered, they must be discarded by Decode
+			input.ReadKey(strings.NewReader("xyz"))
+
+			k, err := input.Decode([]byte(c.tok))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if k.Type() != c.typ {
+				t.Errorf("want %s, got %s", c.typ, k.Type())
+			}
+			if got := string(input.Bytes()); got != c.tok {
+				t.Errorf("want bytes %q, got %q", c.tok, got)
+			}
+		})
+	}
+
+	if _, err := input.Decode(nil); err != ErrTimeout {
+		t.Errorf("want ErrTimeout, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package zzterm
+
+// DefaultScrollLines is the default number of lines scrolled by a Scroller
+// for each mouse wheel event.
+const DefaultScrollLines = 3
+
+// Scroller maintains the scroll offset of a viewport within some content,
+// e.g. a scrollable list or text view, in response to mouse wheel events
+// and to the PgUp, PgDn, Home and End keys. The offset is the index of the
+// first line of content visible in the viewport, and it is always clamped
+// so that the viewport does not scroll past the end of the content.
+//
+// The zero value is a Scroller with no content. A Scroller is not safe for
+// concurrent use.
+type Scroller struct {
+	content  int
+	viewport int
+	offset   int
+
+	// Lines is the number of lines scrolled for each mouse wheel event. If
+	// it is <= 0, DefaultScrollLines is used.
+	Lines int
+}
+
+// NewScroller returns a Scroller for content lines of content displayed in
+// a viewport of viewport lines, with the offset set to 0.
+func NewScroller(content, viewport int) *Scroller {
+	var s Scroller
+	s.Resize(content, viewport)
+	return &s
+}
+
+// Resize sets the content and viewport heights, e.g. when the content
+// changes or when the terminal is resized. Negative heights are treated as
+// 0. It returns the offset, clamped to the new heights.
+func (s *Scroller) Resize(content, viewport int) int {
+	if content < 0 {
+		content = 0
+	}
+	if viewport < 0 {
+		viewport = 0
+	}
+	s.content, s.viewport = content, viewport
+	return s.SetOffset(s.offset)
+}
+
+// Offset returns the current offset.
+func (s *Scroller) Offset() int {
+	return s.offset
+}
+
+// MaxOffset returns the maximum offset, which is the offset where the last
+// line of content is at the bottom of the viewport (or 0 if the content
+// fits in the viewport).
+func (s *Scroller) MaxOffset() int {
+	if max := s.content - s.viewport; max > 0 {
+		return max
+	}
+	return 0
+}
+
+// SetOffset sets the offset to n, clamped between 0 and MaxOffset, and
+// returns the resulting offset.
+func (s *Scroller) SetOffset(n int) int {
+	if max := s.MaxOffset(); n > max {
+		n = max
+	}
+	if n < 0 {
+		n = 0
+	}
+	s.offset = n
+	return n
+}
+
+// Scroll scrolls by n lines (up if n is negative, down otherwise) and
+// returns the resulting offset.
+func (s *Scroller) Scroll(n int) int {
+	return s.SetOffset(s.offset + n)
+}
+
+// Handle updates the offset in response to the key k and, if k is of type
+// KeyMouse, the mouse event m (typically the value returned by
+// Input.Mouse). It returns the resulting offset and true if the key is a
+// scroll event, otherwise it returns the current offset and false and the
+// offset is unchanged.
+//
+// The scroll events are the mouse wheel up (button 4) and down (button 5),
+// which scroll by Lines, the PgUp and PgDn keys, which scroll by the
+// viewport height, and the Home and End keys, which scroll to the start and
+// end of the content. Keys with modifier flags are not scroll events.
+func (s *Scroller) Handle(k Key, m MouseEvent) (int, bool) {
+	if k.Type() == KeyMouse {
+		lines := s.Lines
+		if lines <= 0 {
+			lines = DefaultScrollLines
+		}
+		if _, dy := m.WheelDelta(); dy != 0 {
+			return s.Scroll(dy * lines), true
+		}
+		return s.offset, false
+	}
+
+	if k.Mod() != ModNone {
+		return s.offset, false
+	}
+	switch k.Type() {
+	case KeyPgUp:
+		return s.Scroll(-s.viewport), true
+	case KeyPgDn:
+		return s.Scroll(s.viewport), true
+	case KeyHome:
+		return s.SetOffset(0), true
+	case KeyEnd:
+		return s.SetOffset(s.MaxOffset()), true
+	}
+	return s.offset, false
+}
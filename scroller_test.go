@@ -0,0 +1,62 @@
+package zzterm
+
+import "testing"
+
+func TestScroller_Handle(t *testing.T) {
+	wheelUp, up := NewMouseEvent(4, true, 1, 1, ModNone)
+	wheelDown, down := NewMouseEvent(5, true, 1, 1, ModNone)
+	click, left := NewMouseEvent(1, true, 1, 1, ModNone)
+
+	s := NewScroller(100, 20)
+	cases := []struct {
+		k    Key
+		m    MouseEvent
+		want int
+		ok   bool
+	}{
+		{wheelUp, up, 0, true},
+		{wheelDown, down, 3, true},
+		{wheelDown, down, 6, true},
+		{click, left, 6, false},
+		{NewKey(KeyPgDn, ModNone), MouseEvent{}, 26, true},
+		{NewKey(KeyPgDn, ModNone), MouseEvent{}, 46, true},
+		{NewKey(KeyPgDn, ModNone), MouseEvent{}, 66, true},
+		{NewKey(KeyPgDn, ModNone), MouseEvent{}, 80, true},
+		{wheelDown, down, 80, true},
+		{NewKey(KeyPgUp, ModNone), MouseEvent{}, 60, true},
+		{NewKey(KeyHome, ModCtrl), MouseEvent{}, 60, false},
+		{NewKey(KeyHome, ModNone), MouseEvent{}, 0, true},
+		{NewKey(KeyEnd, ModNone), MouseEvent{}, 80, true},
+		{Key('j'), MouseEvent{}, 80, false},
+	}
+	for i, c := range cases {
+		got, ok := s.Handle(c.k, c.m)
+		if got != c.want || ok != c.ok {
+			t.Errorf("%d: %s: want %d, %t, got %d, %t", i, c.k, c.want, c.ok, got, ok)
+		}
+	}
+}
+
+func TestScroller_Resize(t *testing.T) {
+	s := NewScroller(100, 20)
+	s.SetOffset(70)
+	if got := s.Resize(50, 20); got != 30 {
+		t.Errorf("want offset 30, got %d", got)
+	}
+	if got := s.Resize(10, 20); got != 0 {
+		t.Errorf("want offset 0, got %d", got)
+	}
+	s.Lines = 1
+	s.Resize(100, -1)
+	if got := s.Scroll(5); got != 5 {
+		t.Errorf("want offset 5, got %d", got)
+	}
+	if got := s.MaxOffset(); got != 100 {
+		t.Errorf("want max offset 100, got %d", got)
+	}
+
+	var zero Scroller
+	if got, ok := zero.Handle(NewKey(KeyEnd, ModNone), MouseEvent{}); got != 0 || !ok {
+		t.Errorf("want 0, true, got %d, %t", got, ok)
+	}
+}
@@ -0,0 +1,151 @@
+package zzterm
+
+import (
+	"io"
+	"time"
+)
+
+// default delay to wait after an empty read while waiting for the rest of
+// an escape sequence, if not set by WithLinkSpeed.
+const defaultByteDelay = time.Millisecond
+
+// WithSeqTimeout sets the maximum delay to wait for the rest of an escape
+// sequence split over multiple reads, as can happen over SSH or serial
+// links (e.g. "ESC [ 1 ;" followed by "2 C"). By default, the sequence is
+// decoded with the bytes returned by a single read, so the first part would
+// be reported as a KeyESCSeq and the rest as runes. With this option, when
+// the bytes read start with an incomplete CSI, SS3, OSC, DCS, APC or PM
+// sequence, the Input reads the following bytes one at a time until the
+// sequence is complete or the timeout expires, and then decodes it. Reading
+// one byte at a time ensures that the keys that follow the sequence are not
+// merged with it. An ESC read alone is still returned as a KeyESC key,
+// see WithESCTimeout to wait for the bytes that may follow it.
+//
+// For the timeout to be enforced, the reader must have a read timeout
+// shorter than d, otherwise ReadKey blocks until more bytes are received.
+// If d is <= 0, the option is ignored.
+func WithSeqTimeout(d time.Duration) Option {
+	return func(i *Input) {
+		if d > 0 {
+			i.seqDelay = d
+		}
+	}
+}
+
+// WithESCTimeout sets the maximum delay to wait for the bytes that may
+// follow an ESC read alone. By default, a lone ESC is returned as a KeyESC
+// key, so if a terminal sends the ESC of a sequence in a different write than
+// the rest (e.g. the ESC of an arrow key), the sequence is reported as a
+// KeyESC key followed by runes. With this option, the Input keeps reading
+// for up to d after a lone ESC and returns KeyESC only if no byte is received
+// in that delay. If bytes are received, the sequence is decoded as usual, up
+// to the delay set by WithSeqTimeout if it is incomplete, or up to d if that
+// option is not set.
+//
+// As for WithSeqTimeout, the reader must have a read timeout shorter than d
+// for the delay to be enforced. If d is <= 0, the option is ignored.
+func WithESCTimeout(d time.Duration) Option {
+	return func(i *Input) {
+		if d > 0 {
+			i.escDelay = d
+		}
+	}
+}
+
+// WithMaxLatency bounds to d the total time that ReadKey waits for the rest
+// of an escape sequence, whatever the delays set by WithSeqTimeout,
+// WithESCTimeout and WithLinkSpeed (e.g. the delay of a lone ESC followed by
+// the delay of the rest of the sequence), for applications such as games
+// where the input latency matters more than the decoding of the sequences
+// split over reads. When d expires, the bytes received so far are decoded
+// as usual, so an incomplete sequence is reported as a KeyESCSeq key and a
+// lone ESC as a KeyESC key.
+//
+// As for WithSeqTimeout, the reader must have a read timeout shorter than d
+// for the bound to be enforced. If d is <= 0, the option is ignored.
+func WithMaxLatency(d time.Duration) Option {
+	return func(i *Input) {
+		if d > 0 {
+			i.maxLatency = d
+		}
+	}
+}
+
+// reads more bytes from r one at a time while the buffer holds an
+// incomplete escape sequence, for up to the delay of the sequence, and up
+// to the maximum latency overall.
+func (i *Input) awaitSeq(r io.Reader) {
+	delay := i.seqDelay
+	if i.len == 1 {
+		delay = i.escDelay
+	}
+	if delay <= 0 {
+		return
+	}
+	byteDelay := i.byteDelay
+	if byteDelay <= 0 {
+		byteDelay = defaultByteDelay
+	}
+
+	start := i.clock.Now()
+	bound := func(t time.Time) time.Time {
+		if limit := start.Add(i.maxLatency); i.maxLatency > 0 && t.After(limit) {
+			return limit
+		}
+		return t
+	}
+
+	deadline := bound(start.Add(delay))
+	for i.len < len(i.buf) && !isSeqComplete(i.buf[:i.len]) && i.clock.Now().Before(deadline) {
+		i.enter(PhaseRead)
+		n, err := r.Read(i.buf[i.len : i.len+1])
+		i.exit(PhaseRead)
+		i.stats.Bytes += uint64(n)
+		if n > 0 && err == nil && i.dropNUL {
+			n = i.dropPadding(i.buf[i.len : i.len+n])
+		}
+		if n > 0 {
+			if i.len == 1 && i.seqDelay > 0 {
+				// the ESC starts a sequence, wait for its rest
+				deadline = bound(i.clock.Now().Add(i.seqDelay))
+			}
+			i.len += n
+			if i.stamps {
+				i.lastAt = i.clock.Now()
+			}
+		}
+		if err != nil {
+			if to, ok := err.(interface{ Timeout() bool }); ok && to.Timeout() {
+				continue
+			}
+			// the error is returned by the next read
+			return
+		}
+		if n == 0 {
+			d := byteDelay
+			if left := deadline.Sub(i.clock.Now()); left < d {
+				d = left
+			}
+			if d > 0 {
+				i.clock.Sleep(d)
+			}
+		}
+	}
+}
+
+// returns true if b, which starts with ESC, holds a complete escape
+// sequence.
+func isSeqComplete(b []byte) bool {
+	if len(b) < 2 {
+		return false
+	}
+	switch b[1] {
+	case '[':
+		return scanCSI(b) >= 0
+	case 'O':
+		return len(b) > 2
+	case ']', 'P', '_', '^':
+		return scanST(b) >= 0
+	}
+	return true
+}
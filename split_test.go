@@ -0,0 +1,157 @@
+package zzterm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithSeqTimeout(t *testing.T) {
+	esc, seq := NewKey(KeyESC, ModNone), NewKey(KeyESCSeq, ModNone)
+	empty := make([]string, 20)
+
+	cases := []struct {
+		desc    string
+		timeout time.Duration
+		chunks  []string
+		want    []Key
+	}{
+		{"no timeout", 0, []string{"\x1b[1;", "2C"}, []Key{seq, '2', 'C'}},
+		{"split CSI", 10 * time.Millisecond, []string{"\x1b[1;", "", "2C"}, []Key{NewKey(KeyRight, ModShift)}},
+		{"following keys", 10 * time.Millisecond, []string{"\x1b[", "Ax\x1bOP"}, []Key{NewKey(KeyUp, ModNone), 'x', NewKey(KeyF1, ModNone)}},
+		{"split OSC", 10 * time.Millisecond, []string{"\x1b]11;rgb:0/0/0", "\x1b\\a"}, []Key{NewKey(KeyOSC, ModNone), 'a'}},
+		{"lone ESC", 10 * time.Millisecond, []string{"\x1b", "[A"}, []Key{esc, '[', 'A'}},
+		{"expired", 10 * time.Millisecond, append(append([]string{"\x1b[1;"}, empty...), "2C"), []Key{seq, '2', 'C'}},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			clock := &fakeClock{}
+			input := NewInput(WithClock(clock), WithSeqTimeout(c.timeout), WithOSC())
+			r := &clockedReader{clock: clock, chunks: c.chunks, delay: time.Millisecond}
+			var got []Key
+			for {
+				k, err := input.ReadKey(r)
+				if err == ErrTimeout && len(r.chunks) == 0 {
+					break
+				}
+				if err == ErrTimeout {
+					continue
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, k)
+			}
+			if len(got) != len(c.want) {
+				t.Fatalf("want %v, got %v", c.want, got)
+			}
+			for j, w := range c.want {
+				if got[j] != w {
+					t.Errorf("[%d]: want %s, got %s", j, w, got[j])
+				}
+			}
+		})
+	}
+}
+
+func TestWithESCTimeout(t *testing.T) {
+	esc, up := NewKey(KeyESC, ModNone), NewKey(KeyUp, ModNone)
+	empty := make([]string, 20)
+
+	cases := []struct {
+		desc   string
+		esc    time.Duration
+		seq    time.Duration
+		chunks []string
+		want   []Key
+	}{
+		{"no timeout", 0, 0, []string{"\x1b", "[A"}, []Key{esc, '[', 'A'}},
+		{"arrow key", 10 * time.Millisecond, 0, []string{"\x1b", "", "[A"}, []Key{up}},
+		{"split arrow key", 10 * time.Millisecond, 0, []string{"\x1b", "[", "", "A"}, []Key{up}},
+		{"escape", 10 * time.Millisecond, 0, append([]string{"\x1b"}, empty...), []Key{esc}},
+		{"late bytes", 10 * time.Millisecond, 0, append(append([]string{"\x1b"}, empty...), "[A"), []Key{esc, '[', 'A'}},
+		{"escape then rune", 10 * time.Millisecond, 0, append(append([]string{"\x1b"}, empty...), "x"), []Key{esc, 'x'}},
+		{"sequence timeout", 5 * time.Millisecond, 20 * time.Millisecond, append(append([]string{"\x1b", "", "[1;"}, empty[:5]...), "2C"), []Key{NewKey(KeyRight, ModShift)}},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			clock := &fakeClock{}
+			input := NewInput(WithClock(clock), WithESCTimeout(c.esc), WithSeqTimeout(c.seq))
+			r := &clockedReader{clock: clock, chunks: c.chunks, delay: time.Millisecond}
+			var got []Key
+			for {
+				k, err := input.ReadKey(r)
+				if err == ErrTimeout && len(r.chunks) == 0 {
+					break
+				}
+				if err == ErrTimeout {
+					continue
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, k)
+			}
+			if len(got) != len(c.want) {
+				t.Fatalf("want %v, got %v", c.want, got)
+			}
+			for j, w := range c.want {
+				if got[j] != w {
+					t.Errorf("[%d]: want %s, got %s", j, w, got[j])
+				}
+			}
+		})
+	}
+}
+
+func TestWithMaxLatency(t *testing.T) {
+	esc, seq := NewKey(KeyESC, ModNone), NewKey(KeyESCSeq, ModNone)
+	right := NewKey(KeyRight, ModShift)
+	empty := make([]string, 6)
+
+	cases := []struct {
+		desc   string
+		max    time.Duration
+		chunks []string
+		want   []Key
+	}{
+		{"unbounded", 0, append(append([]string{"\x1b[1;"}, empty...), "2C"), []Key{right}},
+		{"within bound", 50 * time.Millisecond, append(append([]string{"\x1b[1;"}, empty...), "2C"), []Key{right}},
+		{"sequence bound", 5 * time.Millisecond, append(append([]string{"\x1b[1;"}, empty...), "2C"), []Key{seq, '2', 'C'}},
+		{"unbounded ESC", 0, append(append([]string{"\x1b"}, empty[:3]...), "[A"), []Key{NewKey(KeyUp, ModNone)}},
+		{"ESC bound", 3 * time.Millisecond, append(append([]string{"\x1b"}, empty[:3]...), "[A"), []Key{esc, '[', 'A'}},
+		{"ESC and sequence bound", 8 * time.Millisecond, append(append([]string{"\x1b", "", "[1;"}, empty...), "2C"), []Key{seq, '2', 'C'}},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			clock := &fakeClock{}
+			input := NewInput(WithClock(clock), WithESCTimeout(10*time.Millisecond), WithSeqTimeout(20*time.Millisecond), WithMaxLatency(c.max))
+			r := &clockedReader{clock: clock, chunks: c.chunks, delay: time.Millisecond}
+			var got []Key
+			for {
+				start := clock.Now()
+				k, err := input.ReadKey(r)
+				if c.max > 0 && clock.Now().Sub(start) > c.max+time.Millisecond {
+					t.Errorf("want at most %s per key, got %s", c.max, clock.Now().Sub(start))
+				}
+				if err == ErrTimeout && len(r.chunks) == 0 {
+					break
+				}
+				if err == ErrTimeout {
+					continue
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, k)
+			}
+			if len(got) != len(c.want) {
+				t.Fatalf("want %v, got %v", c.want, got)
+			}
+			for j, w := range c.want {
+				if got[j] != w {
+					t.Errorf("[%d]: want %s, got %s", j, w, got[j])
+				}
+			}
+		})
+	}
+}
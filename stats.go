@@ -0,0 +1,50 @@
+package zzterm
+
+// Stats holds the counters of an Input, e.g. to report the input rates on
+// a performance dashboard. The counters only increase, until ResetStats is
+// called.
+type Stats struct {
+	Keys    uint64 // keys decoded, including the mouse events
+	Mouse   uint64 // mouse events decoded (keys of type KeyMouse)
+	Bytes   uint64 // bytes read from the reader
+	Dropped uint64 // bytes skipped to resynchronize the decoding
+}
+
+// Diff returns the difference between s and the previous snapshot prev of
+// the same Input, e.g. to compute the rates per frame or per minute:
+//
+//	cur := input.Stats()
+//	d := cur.Diff(prev)
+//	keysPerSec := float64(d.Keys) / elapsed.Seconds()
+//	prev = cur
+//
+// If the stats were reset after prev was taken, it returns s.
+func (s Stats) Diff(prev Stats) Stats {
+	if s.Keys < prev.Keys || s.Mouse < prev.Mouse || s.Bytes < prev.Bytes || s.Dropped < prev.Dropped {
+		return s
+	}
+	return Stats{
+		Keys:    s.Keys - prev.Keys,
+		Mouse:   s.Mouse - prev.Mouse,
+		Bytes:   s.Bytes - prev.Bytes,
+		Dropped: s.Dropped - prev.Dropped,
+	}
+}
+
+// counts the key k.
+func (s *Stats) count(k Key) {
+	s.Keys++
+	if k.Type() == KeyMouse {
+		s.Mouse++
+	}
+}
+
+// Stats returns a snapshot of the counters of the Input.
+func (i *Input) Stats() Stats {
+	return i.stats
+}
+
+// ResetStats resets the counters of the Input to 0.
+func (i *Input) ResetStats() {
+	i.stats = Stats{}
+}
@@ -0,0 +1,42 @@
+package zzterm
+
+import "testing"
+
+func TestInput_Stats(t *testing.T) {
+	input := NewInput(WithMouse(), WithDeviceReplyFilter())
+	r := &scriptReader{chunks: []string{"a", "\x1b[<0;1;2M", "\xff", "\x1b[0n", "é"}}
+	for {
+		_, err := input.ReadKey(r)
+		if err == ErrTimeout {
+			break
+		}
+	}
+
+	s := input.Stats()
+	want := Stats{Keys: 3, Mouse: 1, Bytes: 1 + 9 + 1 + 4 + 2, Dropped: 1}
+	if s != want {
+		t.Errorf("want %+v, got %+v", want, s)
+	}
+
+	r.chunks = []string{"b", "\x1b[<0;1;2m"}
+	for j := 0; j < 2; j++ {
+		if _, err := input.ReadKey(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d, want := input.Stats().Diff(s), (Stats{Keys: 2, Mouse: 1, Bytes: 10}); d != want {
+		t.Errorf("want diff %+v, got %+v", want, d)
+	}
+
+	input.ResetStats()
+	if got := input.Stats(); got != (Stats{}) {
+		t.Errorf("want zero stats, got %+v", got)
+	}
+	r.chunks = []string{"c"}
+	if _, err := input.ReadKey(r); err != nil {
+		t.Fatal(err)
+	}
+	if d, want := input.Stats().Diff(s), (Stats{Keys: 1, Bytes: 1}); d != want {
+		t.Errorf("want diff after reset %+v, got %+v", want, d)
+	}
+}
@@ -0,0 +1,205 @@
+package zzterm
+
+import (
+	"io"
+	"time"
+)
+
+// Bounds of the draining of an unterminated sequence stream by ReadKey, see
+// drainSeqStream.
+const (
+	maxStreamDrain       = 1 << 20
+	defaultStreamTimeout = time.Second
+)
+
+// seqStream is the reader of an escape sequence that does not fit in the
+// buffer of the Input. It reads the sequence through the buffer of the
+// Input, so that the memory used is bounded.
+type seqStream struct {
+	i    *Input
+	r    io.Reader
+	st   bool // string sequence terminated by BEL or ST, otherwise CSI
+	esc  bool // last byte scanned was ESC (for the ST terminator)
+	off  int  // index of the next byte to return in the buffer
+	end  int  // index after the terminator in the buffer, -1 if not found
+	done bool
+	head bool  // the buffer holds the first bytes of the sequence
+	err  error // reason reported for the discarded bytes, see drainSeqStream
+
+	seq  string    // start of the sequence, for the error if it is abandoned
+	last time.Time // time of the last read that returned bytes
+	gone int       // number of bytes discarded by drainSeqStream
+}
+
+// returns a stream for the incomplete escape sequence that fills the
+// buffer, or nil if the buffer does not hold such a sequence.
+func (i *Input) newSeqStream(r io.Reader) *seqStream {
+	buf := i.buf[:i.len]
+	if i.len < len(i.buf) || len(buf) < 2 {
+		return nil
+	}
+
+	head := buf
+	if len(head) > maxTokenLen {
+		head = head[:maxTokenLen]
+	}
+	s := &seqStream{i: i, r: r, head: true, seq: string(head), last: i.clock.Now()}
+	switch buf[1] {
+	case '[':
+		if scanCSI(buf) >= 0 {
+			return nil
+		}
+	case ']', 'P', '_', '^':
+		if scanST(buf) >= 0 {
+			return nil
+		}
+		s.st = true
+	default:
+		return nil
+	}
+	s.end = s.scan(2)
+	return s
+}
+
+// scans the buffer from index start for the terminator of the sequence,
+// and returns the index after it, or -1 if it is not found.
+func (s *seqStream) scan(start int) int {
+	for j, c := range s.i.buf[start:s.i.len] {
+		if !s.st {
+			if c >= 0x40 && c <= 0x7e {
+				return start + j + 1
+			}
+			continue
+		}
+		switch {
+		case c == '\a', s.esc && c == '\\':
+			return start + j + 1
+		}
+		s.esc = c == '\x1b'
+	}
+	return -1
+}
+
+// Read reads the next bytes of the sequence, it returns io.EOF after the
+// last byte of the sequence. If the underlying read returns no byte, it
+// returns ErrTimeout (or the error returned by the underlying read, if
+// it is not a timeout or io.EOF).
+func (s *seqStream) Read(p []byte) (int, error) {
+	if s.done {
+		return 0, io.EOF
+	}
+	i := s.i
+
+	if s.off == i.len {
+		// all buffered bytes have been returned, read more
+		i.sz, i.len, s.off = 0, 0, 0
+		n, err := s.r.Read(i.buf)
+		i.stats.Bytes += uint64(n)
+		if n == 0 {
+			to, ok := err.(interface{ Timeout() bool })
+			if err == nil || err == io.EOF || (ok && to.Timeout()) {
+				err = ErrTimeout
+			}
+			return 0, err
+		}
+		i.len = n
+		s.last = i.clock.Now()
+		if i.stamps {
+			i.lastAt = s.last
+		}
+		s.end = s.scan(0)
+		s.head = false
+	}
+
+	end := i.len
+	if s.end >= 0 {
+		end = s.end
+	}
+	n := copy(p, i.buf[s.off:end])
+	if i.mirror != nil && !s.head {
+		// the first bytes are mirrored when the key is returned
+		i.mirror.Write(i.buf[s.off : s.off+n])
+	}
+	s.off += n
+	if s.off == s.end {
+		// the remaining bytes are returned by the next ReadKey
+		s.done = true
+		i.sz = s.end
+	}
+	return n, nil
+}
+
+// reads and discards the rest of the current sequence stream, if any. The
+// discarded bytes that were not returned by the last ReadKey are reported
+// to the dropped bytes logger and counted in the stats. So that a sequence
+// that is never terminated does not swallow all the following input, the
+// stream is abandoned with a CSITooLong error if more than maxStreamDrain
+// bytes are discarded, or if a read times out after no byte was received
+// for the delay set by WithSeqTimeout (or defaultStreamTimeout). The bytes
+// that follow are then decoded as usual.
+func (i *Input) drainSeqStream() error {
+	s := i.stream
+	if s == nil {
+		return nil
+	}
+	err := s.err
+	if err == nil {
+		err = errSeqDropped
+	}
+
+	timeout := i.seqDelay
+	if timeout <= 0 {
+		timeout = defaultStreamTimeout
+	}
+
+	var p [256]byte
+	for {
+		n, rerr := s.Read(p[:])
+		if n > 0 && !s.head {
+			i.stats.Dropped += uint64(n)
+			s.gone += n
+			if i.dropped != nil {
+				b := i.buf[s.off-n : i.len : i.len]
+				i.dropped(b[:n:n], b, err)
+			}
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr == ErrTimeout && i.clock.Now().Sub(s.last) >= timeout || rerr == nil && s.gone > maxStreamDrain {
+			// the remaining bytes of the buffer are decoded by the next ReadKey
+			i.sz = s.off
+			i.stream = nil
+			return CSIError{Kind: CSITooLong, Seq: s.seq}
+		}
+		if rerr != nil {
+			return rerr
+		}
+	}
+	i.stream = nil
+	return nil
+}
+
+// SeqReader returns the reader of the escape sequence of the last key of
+// type KeyESCSeqStream, or nil if the last key is not of that type. It
+// returns all bytes of the sequence, starting with the bytes returned by
+// Input.Bytes, and then reads the rest of the sequence from the reader
+// passed to ReadKey, through the buffer of the Input (so Input.Bytes is
+// invalid once reading from it starts). It returns io.EOF after the
+// terminator of the sequence, and ErrTimeout if the underlying read times
+// out, in which case reading can be resumed later.
+//
+// The reader is valid until the next call to ReadKey, which discards the
+// unread bytes of the sequence (reading them from the underlying reader if
+// necessary) so that decoding resumes after the sequence. The discarded
+// bytes are reported to the dropped bytes logger, if any (see
+// WithLoggerForDroppedBytes). If the sequence is never terminated, ReadKey
+// abandons it and returns a CSIError of kind CSITooLong once it discarded
+// 1 MiB of it or no byte was received for the delay set by WithSeqTimeout
+// (1 second by default), and decodes the following bytes as usual.
+func (i *Input) SeqReader() io.Reader {
+	if i.stream == nil {
+		return nil
+	}
+	return i.stream
+}
@@ -0,0 +1,212 @@
+package zzterm
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInput_ReadKey_ESCSeqStream(t *testing.T) {
+	osc := "\x1b]52;c;" + strings.Repeat("QUJD", 100) + "\a"
+	csi := "\x1b[" + strings.Repeat("1;", 100) + "m"
+	cases := []struct {
+		name string
+		seq  string
+		next string
+		read bool
+	}{
+		{"osc read", osc, "a", true},
+		{"osc skip", osc, "a", false},
+		{"csi read", csi, "\x1b[A", true},
+		{"csi skip", csi, "\x1b[A", false},
+		{"st read", "\x1bP" + strings.Repeat("x", 200) + "\x1b\\", "b", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			input := NewInput()
+			r := strings.NewReader(c.seq + c.next)
+
+			k, err := input.ReadKey(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if k.Type() != KeyESCSeqStream {
+				t.Fatalf("want KeyESCSeqStream, got %s", k)
+			}
+			if head := string(input.Bytes()); !strings.HasPrefix(c.seq, head) || len(head) != 128 {
+				t.Errorf("invalid head of %d bytes: %q", len(head), head)
+			}
+			if c.read {
+				b, err := ioutil.ReadAll(input.SeqReader())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(b) != c.seq {
+					t.Errorf("want sequence %q, got %q", c.seq, b)
+				}
+			}
+
+			k, err = input.ReadKey(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(input.Bytes()); got != c.next {
+				t.Errorf("want next key %q, got %q (%s)", c.next, got, k)
+			}
+			if input.SeqReader() != nil {
+				t.Error("want nil SeqReader")
+			}
+		})
+	}
+}
+
+func TestInput_ReadKey_ESCSeqStreamDropped(t *testing.T) {
+	osc := "\x1b]52;c;" + strings.Repeat("QUJD", 100) + "\a"
+	cases := []struct {
+		name string
+		read int // bytes read from the SeqReader before the next ReadKey
+	}{
+		{"skip", 0},
+		{"head read", 128},
+		{"partly read", 200},
+		{"all read", len(osc)},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var dropped []byte
+			var errs []error
+			input := NewInput(WithLoggerForDroppedBytes(func(b, context []byte, err error) {
+				if !bytes.HasPrefix(context, b) {
+					t.Errorf("dropped bytes %q are not a prefix of the context %q", b, context)
+				}
+				dropped = append(dropped, b...)
+				errs = append(errs, err)
+			}))
+			r := strings.NewReader(osc + "a")
+
+			k, err := input.ReadKey(r)
+			if err != nil || k.Type() != KeyESCSeqStream {
+				t.Fatalf("want KeyESCSeqStream, got %s, %v", k, err)
+			}
+			if c.read > 0 {
+				if _, err := io.ReadFull(input.SeqReader(), make([]byte, c.read)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if k, err := input.ReadKey(r); err != nil || k != 'a' {
+				t.Fatalf("want 'a', got %s, %v", k, err)
+			}
+
+			// the head is returned by the first ReadKey, the unread bytes
+			// after it are dropped
+			from := c.read
+			if from < 128 {
+				from = 128
+			}
+			if want := osc[from:]; string(dropped) != want {
+				t.Errorf("want dropped %q, got %q", want, dropped)
+			}
+			if got := input.Stats().Dropped; got != uint64(len(osc)-from) {
+				t.Errorf("want %d dropped bytes in stats, got %d", len(osc)-from, got)
+			}
+			for _, err := range errs {
+				if err != errSeqDropped {
+					t.Errorf("want %v, got %v", errSeqDropped, err)
+				}
+			}
+		})
+	}
+}
+
+func TestInput_ReadKey_ESCSeqStreamTimeout(t *testing.T) {
+	head := "\x1b]2;" + strings.Repeat("x", 124)
+	r := &scriptReader{chunks: []string{head, "yyy\x1b", "", "\\z"}}
+	input := NewInput()
+
+	k, err := input.ReadKey(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.Type() != KeyESCSeqStream {
+		t.Fatalf("want KeyESCSeqStream, got %s", k)
+	}
+
+	var buf bytes.Buffer
+	sr := input.SeqReader()
+	if _, err := io.Copy(&buf, sr); err != ErrTimeout {
+		t.Fatalf("want ErrTimeout, got %v", err)
+	}
+	if _, err := io.Copy(&buf, sr); err != nil {
+		t.Fatal(err)
+	}
+	if want := head + "yyy\x1b\\"; buf.String() != want {
+		t.Errorf("want %q, got %q", want, buf.String())
+	}
+
+	k, err = input.ReadKey(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k != 'z' {
+		t.Errorf("want 'z', got %s", k)
+	}
+}
+
+// infiniteReader returns an endless stream of b.
+type infiniteReader byte
+
+func (r infiniteReader) Read(b []byte) (int, error) {
+	for j := range b {
+		b[j] = byte(r)
+	}
+	return len(b), nil
+}
+
+func TestInput_ReadKey_ESCSeqStreamAbandoned(t *testing.T) {
+	head := "\x1b]2;" + strings.Repeat("x", 124)
+
+	t.Run("timeout", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+		r := &clockedReader{clock: clock, chunks: []string{head, "abc", "", "", "z"}, delay: 600 * time.Millisecond}
+		input := NewInput(WithClock(clock))
+
+		if k, err := input.ReadKey(r); err != nil || k.Type() != KeyESCSeqStream {
+			t.Fatalf("want KeyESCSeqStream, got %s, %v", k, err)
+		}
+		// "abc" is part of the sequence, no byte for 600ms
+		if _, err := input.ReadKey(r); err != ErrTimeout {
+			t.Fatalf("want ErrTimeout, got %v", err)
+		}
+		// no byte for 1.2s
+		var ce CSIError
+		if _, err := input.ReadKey(r); !errors.As(err, &ce) || ce.Kind != CSITooLong || ce.Seq != head {
+			t.Fatalf("want %s error, got %v", CSITooLong, err)
+		}
+		if k, err := input.ReadKey(r); err != nil || k != 'z' {
+			t.Fatalf("want 'z', got %s, %v", k, err)
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		input := NewInput()
+		r := io.MultiReader(strings.NewReader(head), io.LimitReader(infiniteReader('y'), 2*maxStreamDrain))
+
+		if k, err := input.ReadKey(r); err != nil || k.Type() != KeyESCSeqStream {
+			t.Fatalf("want KeyESCSeqStream, got %s, %v", k, err)
+		}
+		var ce CSIError
+		if _, err := input.ReadKey(r); !errors.As(err, &ce) || ce.Kind != CSITooLong {
+			t.Fatalf("want %s error, got %v", CSITooLong, err)
+		}
+		if got := input.Stats().Dropped; got <= maxStreamDrain || got > maxStreamDrain+uint64(len(head)) {
+			t.Errorf("want about %d dropped bytes, got %d", maxStreamDrain, got)
+		}
+		if k, err := input.ReadKey(r); err != nil || k != 'y' {
+			t.Fatalf("want 'y', got %s, %v", k, err)
+		}
+	})
+}
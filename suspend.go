@@ -0,0 +1,56 @@
+package zzterm
+
+import "io"
+
+// Suspend prepares the Input for a child process (e.g. $EDITOR) to take
+// over the terminal. It discards the decoding state of the Input: the
+// buffered bytes, the unread rest of a KeyESCSeqStream sequence, the keys
+// queued by Expect, the pending key of a two-key escape and the mouse
+// buttons held. If w is not nil, it also disables the features on the
+// terminal represented by w in a single write (see DisableFeatures), and
+// Resume enables them again.
+//
+// The Input does not manage the mode of the terminal, so the caller is
+// responsible to restore the cooked mode before starting the child process
+// and to set the raw mode again before calling Resume. The state of a
+// ChordReader that reads from the Input is not affected.
+func (i *Input) Suspend(w io.Writer, features ...Feature) error {
+	i.reset()
+	i.susp = nil
+	if w == nil || len(features) == 0 {
+		return nil
+	}
+	if err := DisableFeatures(w, features...); err != nil {
+		return err
+	}
+	i.susp = append([]Feature(nil), features...)
+	return nil
+}
+
+// Resume resumes decoding after a call to Suspend, once the child process
+// has exited. If w is not nil, it enables the features that were disabled
+// by Suspend on the terminal represented by w in a single write (see
+// EnableFeatures). The decoding state is discarded again, so that nothing
+// left over by the child process is mixed with the next keys.
+func (i *Input) Resume(w io.Writer) error {
+	i.reset()
+	fs := i.susp
+	i.susp = nil
+	if w == nil || len(fs) == 0 {
+		return nil
+	}
+	return EnableFeatures(w, fs...)
+}
+
+// discards the decoding state of the Input.
+func (i *Input) reset() {
+	i.sz, i.len = 0, 0
+	i.stream = nil
+	i.queue = nil
+	i.rbuf = nil
+	i.held = 0
+	i.lastxy = [2]int32{}
+	if tk := i.twoKey; tk != nil {
+		tk.hasPending, tk.hasReplay = false, false
+	}
+}
@@ -0,0 +1,61 @@
+package zzterm
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestInput_SuspendResume(t *testing.T) {
+	var buf bytes.Buffer
+	input := NewInput(WithMouse(), WithTwoKeyEscape(time.Hour, "jk"))
+
+	// left-over state: a held button, a queued key, a pending two-key
+	// escape and buffered bytes
+	r := &scriptReader{chunks: []string{"\x1b[<0;1;2M", "a", "b", "j", "xyz"}}
+	if _, err := input.Expect(context.Background(), r, func(ev Event) bool { return ev.Key == 'b' }); err != nil {
+		t.Fatal(err)
+	}
+	if len(input.Pending()) != 2 || input.held == 0 {
+		t.Fatalf("want pending keys and held button, got %v, %b", input.Pending(), input.held)
+	}
+	if _, err := input.ReadKey(r); err != nil { // replay of mouse event
+		t.Fatal(err)
+	}
+	if _, err := input.ReadKey(r); err != nil { // replay of 'a'
+		t.Fatal(err)
+	}
+	if k, err := input.ReadKey(r); err != nil || k != 'j' { // 'j' pending, 'x' replayed next
+		t.Fatalf("want 'j', got %s, %v", k, err)
+	}
+
+	if err := input.Suspend(&buf, FeatureMouseAny, FeatureFocus); err != nil {
+		t.Fatal(err)
+	}
+	if want := SeqDisableFocus + SeqDisableMouseAny; buf.String() != want {
+		t.Errorf("want %q, got %q", want, buf.String())
+	}
+	if input.len != 0 || input.held != 0 || input.Pending() != nil || input.twoKey.hasReplay {
+		t.Errorf("want state discarded, got len %d, held %b, pending %v", input.len, input.held, input.Pending())
+	}
+
+	buf.Reset()
+	if err := input.Resume(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if want := SeqEnableMouseAny + SeqEnableFocus; buf.String() != want {
+		t.Errorf("want %q, got %q", want, buf.String())
+	}
+
+	r = &scriptReader{chunks: []string{"q"}}
+	if k, err := input.ReadKey(r); err != nil || k != 'q' {
+		t.Errorf("want 'q', got %s, %v", k, err)
+	}
+
+	// second Resume does not write anything
+	buf.Reset()
+	if err := input.Resume(&buf); err != nil || buf.Len() != 0 {
+		t.Errorf("want no write, got %q, %v", buf.String(), err)
+	}
+}
@@ -0,0 +1,139 @@
+package zzterm
+
+import (
+	"io"
+	"sync"
+)
+
+// Tee is an io.Writer that forwards the bytes written to it to another
+// writer from a separate goroutine, without ever blocking the caller. It is
+// intended to be used with WithRawMirror (or Input.SetRawMirror) to feed
+// the exact bytes consumed by an Input to a diagnostic process, e.g. the
+// eventviewer example over a pipe, without perturbing the timing of the
+// main loop:
+//
+//	tee := zzterm.NewTee(pipe, 4096)
+//	defer tee.Close()
+//	input := zzterm.NewInput(zzterm.WithRawMirror(tee))
+//
+// The bytes are buffered in a fixed-size buffer, and the writes that do not
+// fit in it are dropped entirely (see Tee.Dropped), so that the bytes that
+// are forwarded are never split in the middle of a key.
+type Tee struct {
+	w     io.Writer
+	wake  chan struct{}
+	done  chan struct{}
+	drain sync.WaitGroup
+
+	mu      sync.Mutex
+	buf     []byte // ring buffer
+	start   int    // index of the first byte to forward
+	n       int    // number of bytes to forward
+	dropped uint64
+	closed  bool
+}
+
+// NewTee returns a Tee that forwards the bytes written to it to w, with a
+// buffer of size bytes. It panics if size is <= 0. Close must be called to
+// release the goroutine.
+func NewTee(w io.Writer, size int) *Tee {
+	if size <= 0 {
+		panic("zzterm: invalid tee buffer size")
+	}
+	t := &Tee{
+		w:    w,
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+		buf:  make([]byte, size),
+	}
+	t.drain.Add(1)
+	go t.forward()
+	return t
+}
+
+// Write buffers p to be forwarded and returns len(p) and a nil error, even
+// if p is dropped because the buffer is full or the Tee is closed. It does
+// not block on the forwarding writer. It is safe to call concurrently.
+func (t *Tee) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	if t.closed || len(p) > len(t.buf)-t.n {
+		t.dropped += uint64(len(p))
+		t.mu.Unlock()
+		return len(p), nil
+	}
+	end := (t.start + t.n) % len(t.buf)
+	c := copy(t.buf[end:], p)
+	copy(t.buf, p[c:])
+	t.n += len(p)
+	t.mu.Unlock()
+
+	select {
+	case t.wake <- struct{}{}:
+	default:
+	}
+	return len(p), nil
+}
+
+// Dropped returns the number of bytes that were dropped because the buffer
+// was full or the Tee was closed.
+func (t *Tee) Dropped() uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.dropped
+}
+
+// Close forwards the bytes still buffered and stops the forwarding
+// goroutine. The bytes written after Close are dropped. It always returns
+// nil, and it can be called more than once.
+func (t *Tee) Close() error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil
+	}
+	t.closed = true
+	t.mu.Unlock()
+
+	close(t.done)
+	t.drain.Wait()
+	return nil
+}
+
+// forwards the buffered bytes until the Tee is closed.
+func (t *Tee) forward() {
+	defer t.drain.Done()
+
+	local := make([]byte, len(t.buf))
+	for {
+		select {
+		case <-t.wake:
+		case <-t.done:
+			// forward what is left, no more bytes can be added
+			for t.flush(local) {
+			}
+			return
+		}
+		for t.flush(local) {
+		}
+	}
+}
+
+// forwards the contiguous bytes at the start of the ring buffer, and
+// returns true if there were any.
+func (t *Tee) flush(local []byte) bool {
+	t.mu.Lock()
+	n := t.n
+	if t.start+n > len(t.buf) {
+		n = len(t.buf) - t.start
+	}
+	copy(local, t.buf[t.start:t.start+n])
+	t.start = (t.start + n) % len(t.buf)
+	t.n -= n
+	t.mu.Unlock()
+
+	if n == 0 {
+		return false
+	}
+	t.w.Write(local[:n])
+	return true
+}
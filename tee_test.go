@@ -0,0 +1,120 @@
+package zzterm
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type lockedBuffer struct {
+	mu sync.Mutex
+	bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.Buffer.Write(p)
+}
+
+func TestTee(t *testing.T) {
+	var out lockedBuffer
+	tee := NewTee(&out, 8)
+	input := NewInput(WithMouse())
+	input.SetRawMirror(tee)
+
+	r := &scriptReader{chunks: []string{"ab", "\x1b[<0;1;2M", "cdef", "平"}}
+	for j := 0; j < 5; j++ {
+		if _, err := input.ReadKey(r); err != nil {
+			t.Fatal(err)
+		}
+		// wait for the bytes to be forwarded, so that none is dropped
+		for {
+			tee.mu.Lock()
+			n := tee.n
+			tee.mu.Unlock()
+			if n == 0 {
+				break
+			}
+		}
+	}
+	// the mouse event is larger than the buffer, and e is not mirrored
+	input.SetRawMirror(nil)
+	if _, err := input.ReadKey(r); err != nil {
+		t.Fatal(err)
+	}
+	tee.Close()
+
+	if want := "abcd"; out.String() != want {
+		t.Errorf("want %q, got %q", want, out.String())
+	}
+	if got := tee.Dropped(); got != 9 {
+		t.Errorf("want 9 dropped bytes, got %d", got)
+	}
+}
+
+func TestTee_Blocked(t *testing.T) {
+	pr, pw := newBlockingWriter()
+	tee := NewTee(pw, 4)
+
+	tee.Write([]byte("ab"))
+	<-pr.started // the forwarding goroutine is blocked writing "ab"
+	tee.Write([]byte("cd"))
+	tee.Write([]byte("ef"))
+	tee.Write([]byte("gh"))
+	if got := tee.Dropped(); got != 2 {
+		t.Errorf("want 2 dropped bytes, got %d", got)
+	}
+
+	close(pr.release)
+	tee.Close()
+	tee.Close()
+	tee.Write([]byte("ij"))
+	if want := "abcdef"; pw.String() != want {
+		t.Errorf("want %q, got %q", want, pw.String())
+	}
+	if got := tee.Dropped(); got != 4 {
+		t.Errorf("want 4 dropped bytes, got %d", got)
+	}
+}
+
+type blockingWriter struct {
+	lockedBuffer
+	once    sync.Once
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlockingWriter() (*blockingWriter, *blockingWriter) {
+	w := &blockingWriter{started: make(chan struct{}), release: make(chan struct{})}
+	return w, w
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.started) })
+	<-w.release
+	return w.lockedBuffer.Write(p)
+}
+
+func TestTee_Wrap(t *testing.T) {
+	var out lockedBuffer
+	tee := NewTee(&out, 5)
+	var want strings.Builder
+	for _, s := range []string{"abc", "de", "fgh", "ij", "klmno"} {
+		want.WriteString(s)
+		tee.Write([]byte(s))
+		for {
+			tee.mu.Lock()
+			n := tee.n
+			tee.mu.Unlock()
+			if n == 0 {
+				break
+			}
+		}
+	}
+	tee.Close()
+	if out.String() != want.String() {
+		t.Errorf("want %q, got %q", want.String(), out.String())
+	}
+}
@@ -0,0 +1,77 @@
+package zzterm
+
+// TermParams is a DEC terminal parameters report (DECREPTPARM), sent by the
+// terminal in reply to a DECREQTPARM request ("ESC [ x" or "ESC [ 1 x"),
+// or unsolicited by some VT100-compatible terminals and serial consoles.
+// The values are those of the report, see
+// https://vt100.net/docs/vt100-ug/chapter3.html#DECREPTPARM
+type TermParams struct {
+	// Solicited is true if the report is a reply to a request, false if it
+	// is unsolicited (the first parameter of the report is 3 or 2,
+	// respectively).
+	Solicited bool
+	Parity    int // 1: no parity, 4: odd parity, 5: even parity
+	Bits      int // 1: 8 bits per character, 2: 7 bits per character
+	XSpeed    int // transmit speed code, e.g. 112 for 9600 bauds
+	RSpeed    int // receive speed code, same values as XSpeed
+	ClockMul  int // bit rate multiplier, always 1 on a VT100
+	Flags     int // setting of the switches of the terminal, 0 to 15
+}
+
+// DECREQTPARM requests for a terminal parameters report. After the first
+// one, the terminal only sends reports when requested, after the second one
+// it may also send unsolicited reports (e.g. when the settings change).
+const (
+	SeqRequestTermParams      = "\x1b[1x"
+	SeqRequestTermParamsUnsol = "\x1b[0x"
+)
+
+// WithTermParams enables decoding of DEC terminal parameters reports
+// (DECREPTPARM, "ESC [ Ps ; Ps ; Ps ; Ps ; Ps ; Ps ; Ps x"). Such reports
+// are returned as a key of type KeyTermParams, and the parameters can be
+// retrieved by calling Input.TermParams before the next call to
+// Input.ReadKey. Without this option, they are reported as KeyESCSeq.
+func WithTermParams() Option {
+	return func(i *Input) {
+		i.tparmOn = true
+	}
+}
+
+// TermParams returns the terminal parameters of the last key of type
+// KeyTermParams. It should be called only after a key of type
+// KeyTermParams has been received from ReadKey, and before any other call
+// to ReadKey.
+func (i *Input) TermParams() TermParams {
+	return i.tparm
+}
+
+// returns either a KeyTermParams key, or a KeyESCSeq if the buffer does not
+// start with a terminal parameters report. If it returns a KeyTermParams
+// key, i.sz is set to the length of the report.
+func (i *Input) decodeTermParams() Key {
+	seq, n := ParseCSI(i.buf[:i.len])
+	if n == 0 || seq.Final != 'x' || seq.Prefix != 0 || seq.Intermediate != 0 ||
+		seq.NumParams() != 7 || seq.HasSubParams() {
+		return keyFromTypeMod(KeyESCSeq, ModNone)
+	}
+
+	var sol bool
+	switch seq.Param(0) {
+	case 2:
+	case 3:
+		sol = true
+	default:
+		return keyFromTypeMod(KeyESCSeq, ModNone)
+	}
+	i.tparm = TermParams{
+		Solicited: sol,
+		Parity:    seq.ParamOr(1, 0),
+		Bits:      seq.ParamOr(2, 0),
+		XSpeed:    seq.ParamOr(3, 0),
+		RSpeed:    seq.ParamOr(4, 0),
+		ClockMul:  seq.ParamOr(5, 0),
+		Flags:     seq.ParamOr(6, 0),
+	}
+	i.sz = n
+	return keyFromTypeMod(KeyTermParams, ModNone)
+}
@@ -0,0 +1,50 @@
+package zzterm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInput_ReadKey_TermParams(t *testing.T) {
+	cases := []struct {
+		in   string
+		want TermParams
+		typ  KeyType
+	}{
+		{"\x1b[3;1;1;112;112;1;0x", TermParams{Solicited: true, Parity: 1, Bits: 1, XSpeed: 112, RSpeed: 112, ClockMul: 1}, KeyTermParams},
+		{"\x1b[2;5;2;120;104;1;15x", TermParams{Parity: 5, Bits: 2, XSpeed: 120, RSpeed: 104, ClockMul: 1, Flags: 15}, KeyTermParams},
+		{"\x1b[3;;;;;;x", TermParams{Solicited: true}, KeyTermParams},
+		{"\x1b[1;1;1;112;112;1;0x", TermParams{}, KeyESCSeq},
+		{"\x1b[3;1;1;112;112;1x", TermParams{}, KeyESCSeq},
+		{"\x1b[?3;1;1;112;112;1;0x", TermParams{}, KeyESCSeq},
+		{"\x1b[3;1;1;112;112;1;0y", TermParams{}, KeyESCSeq},
+	}
+
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			input := NewInput(WithTermParams())
+			k, err := input.ReadKey(strings.NewReader(c.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if k.Type() != c.typ {
+				t.Fatalf("want %s, got %s", c.typ, k.Type())
+			}
+			if got := input.TermParams(); got != c.want {
+				t.Errorf("want %+v, got %+v", c.want, got)
+			}
+			if got := string(input.Bytes()); got != c.in {
+				t.Errorf("want bytes %q, got %q", c.in, got)
+			}
+		})
+	}
+
+	// without the option, reports are KeyESCSeq
+	k, err := NewInput().ReadKey(strings.NewReader(cases[0].in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.Type() != KeyESCSeq {
+		t.Errorf("want %s, got %s", KeyESCSeq, k.Type())
+	}
+}
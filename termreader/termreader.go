@@ -0,0 +1,124 @@
+// Package termreader provides io.Reader wrappers useful to debug and test
+// programs reading terminal input with zzterm: TraceReader logs every read,
+// ThrottleReader simulates a slow serial link and ChunkReader splits the
+// input in arbitrary chunks, as can happen over SSH or slow connections.
+package termreader
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// TraceReader returns a reader that reads from r and writes a line
+// describing each call to Read to w, with the bytes read and the error, if
+// any.
+func TraceReader(r io.Reader, w io.Writer) io.Reader {
+	return &traceReader{r: r, w: w}
+}
+
+type traceReader struct {
+	r io.Reader
+	w io.Writer
+}
+
+func (t *traceReader) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	if err != nil {
+		fmt.Fprintf(t.w, "read(%d) = %d %q: %v\n", len(p), n, p[:n], err)
+	} else {
+		fmt.Fprintf(t.w, "read(%d) = %d %q\n", len(p), n, p[:n])
+	}
+	return n, err
+}
+
+// ThrottleReader returns a reader that reads from r as if the bytes were
+// transmitted over a serial link at the specified speed in bits per second,
+// with 10 bits per byte (8 data bits plus start and stop bits). Each call to
+// Read waits until at least one byte has been transmitted and returns the
+// bytes transmitted since the previous call, as a terminal would.
+func ThrottleReader(r io.Reader, bitsPerSecond int) io.Reader {
+	if bitsPerSecond <= 0 {
+		panic("termreader: invalid bits per second")
+	}
+	return &throttleReader{
+		r:       r,
+		perByte: time.Duration(10 * int64(time.Second) / int64(bitsPerSecond)),
+	}
+}
+
+type throttleReader struct {
+	r       io.Reader
+	perByte time.Duration
+
+	buf  []byte    // bytes read from r but not yet returned
+	err  error     // error returned by r, once buf is consumed
+	last time.Time // time at which the first byte of buf started transmitting
+}
+
+func (t *throttleReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if len(t.buf) == 0 {
+		if t.err != nil {
+			return 0, t.err
+		}
+		if cap(t.buf) < len(p) {
+			t.buf = make([]byte, len(p))
+		}
+		n, err := t.r.Read(t.buf[:len(p)])
+		t.buf, t.err = t.buf[:n], err
+		t.last = time.Now()
+		if n == 0 {
+			return 0, err
+		}
+	}
+
+	// wait for at least one byte, then return all the bytes transmitted so
+	// far.
+	elapsed := time.Since(t.last)
+	if elapsed < t.perByte {
+		time.Sleep(t.perByte - elapsed)
+		elapsed = t.perByte
+	}
+	avail := int(elapsed / t.perByte)
+	if avail > len(t.buf) {
+		avail = len(t.buf)
+	}
+	n := copy(p, t.buf[:avail])
+	t.buf = t.buf[n:]
+	t.last = t.last.Add(time.Duration(n) * t.perByte)
+	return n, nil
+}
+
+// ChunkReader returns a reader that reads from r at most sizes[i] bytes
+// on the ith call to Read, cycling through the sizes. If no size is
+// provided, each Read returns at most a single byte. It is useful to test
+// that decoding does not depend on the way the input is split by reads.
+func ChunkReader(r io.Reader, sizes ...int) io.Reader {
+	if len(sizes) == 0 {
+		sizes = []int{1}
+	}
+	for _, sz := range sizes {
+		if sz <= 0 {
+			panic("termreader: invalid chunk size")
+		}
+	}
+	return &chunkReader{r: r, sizes: sizes}
+}
+
+type chunkReader struct {
+	r     io.Reader
+	sizes []int
+	ix    int
+}
+
+func (c *chunkReader) Read(p []byte) (int, error) {
+	sz := c.sizes[c.ix]
+	c.ix = (c.ix + 1) % len(c.sizes)
+	if len(p) > sz {
+		p = p[:sz]
+	}
+	return c.r.Read(p)
+}
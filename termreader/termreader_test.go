@@ -0,0 +1,72 @@
+package termreader
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTraceReader(t *testing.T) {
+	var buf bytes.Buffer
+	r := TraceReader(strings.NewReader("ab"), &buf)
+
+	p := make([]byte, 4)
+	for {
+		if _, err := r.Read(p); err != nil {
+			break
+		}
+	}
+	want := "read(4) = 2 \"ab\"\nread(4) = 0 \"\": EOF\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+}
+
+func TestThrottleReader(t *testing.T) {
+	// 10000 bps is 1ms per byte
+	const in = "abcdefghij"
+	r := ThrottleReader(strings.NewReader(in), 10000)
+
+	start := time.Now()
+	p := make([]byte, 32)
+	n, err := r.Read(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("want a single byte on first read, got %d", n)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(p[:n]) + string(b); got != in {
+		t.Fatalf("want %q, got %q", in, got)
+	}
+	if d := time.Since(start); d < 10*time.Millisecond {
+		t.Fatalf("want at least 10ms to read, got %s", d)
+	}
+}
+
+func TestChunkReader(t *testing.T) {
+	r := ChunkReader(strings.NewReader("abcdefgh"), 1, 3)
+
+	p := make([]byte, 10)
+	var got []string
+	for {
+		n, err := r.Read(p)
+		if err == io.EOF {
+			break
+		}
+		got = append(got, string(p[:n]))
+	}
+	want := []string{"a", "bcd", "e", "fgh"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,43 @@
+package termtest
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock is a zzterm.Clock whose time only changes when Advance or
+// Sleep is called, so that the timing-based features of zzterm (e.g.
+// two-key escapes and chords) can be tested deterministically:
+//
+//	clock := termtest.NewFakeClock(time.Time{})
+//	input := zzterm.NewInput(zzterm.WithClock(clock))
+//
+// It is safe for concurrent use.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock returns a FakeClock set to the time t.
+func NewFakeClock(t time.Time) *FakeClock {
+	return &FakeClock{now: t}
+}
+
+// Now returns the current time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Sleep advances the clock by d and returns immediately.
+func (c *FakeClock) Sleep(d time.Duration) {
+	c.Advance(d)
+}
+
+// Advance advances the clock by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
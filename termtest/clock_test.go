@@ -0,0 +1,84 @@
+package termtest
+
+import (
+	"testing"
+	"time"
+
+	"git.sr.ht/~mna/zzterm"
+)
+
+// clockReader returns a chunk of bytes per call to Read and advances the
+// clock by the corresponding delay before returning it.
+type clockReader struct {
+	clock  *FakeClock
+	chunks []string
+	delays []time.Duration
+}
+
+func (r *clockReader) Read(b []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, nil
+	}
+	r.clock.Advance(r.delays[0])
+	c := r.chunks[0]
+	r.chunks, r.delays = r.chunks[1:], r.delays[1:]
+	return copy(b, c), nil
+}
+
+func TestFakeClock_Chord(t *testing.T) {
+	ctrlX := zzterm.NewKey(zzterm.KeyCtrlX, zzterm.ModNone)
+	ctrlS := zzterm.NewKey(zzterm.KeyCtrlS, zzterm.ModNone)
+	save := zzterm.Chord{Keys: []zzterm.Key{ctrlX, ctrlS}, Timeout: time.Second}
+
+	cases := []struct {
+		delay time.Duration
+		want  []zzterm.Key // 0 for the chord
+	}{
+		{0, []zzterm.Key{0}},
+		{time.Second, []zzterm.Key{0}},
+		{time.Second + 1, []zzterm.Key{ctrlX, ctrlS}},
+		{time.Hour, []zzterm.Key{ctrlX, ctrlS}},
+	}
+	for _, c := range cases {
+		t.Run(c.delay.String(), func(t *testing.T) {
+			clock := NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+			r := &clockReader{
+				clock:  clock,
+				chunks: []string{"\x18", "\x13"},
+				delays: []time.Duration{0, c.delay},
+			}
+			input := zzterm.NewInput(zzterm.WithClock(clock))
+			cr := zzterm.NewChordReader(input, r, save)
+
+			for i, want := range c.want {
+				k, ch, err := cr.ReadChord()
+				if err != nil {
+					t.Fatalf("[%d]: %v", i, err)
+				}
+				if want == 0 && ch == nil {
+					t.Errorf("[%d]: want chord, got %s", i, k)
+				} else if want != 0 && k != want {
+					t.Errorf("[%d]: want %s, got %s (chord: %v)", i, want, k, ch != nil)
+				}
+			}
+		})
+	}
+}
+
+func TestFakeClock_ZeroReadRetry(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	input := zzterm.NewInput(
+		zzterm.WithClock(clock),
+		zzterm.WithZeroReadRetry(zzterm.RetryPolicy{MaxRetries: 3, Backoff: time.Minute}),
+	)
+
+	r := &clockReader{clock: clock}
+	if _, err := input.ReadKey(r); err != zzterm.ErrTimeout {
+		t.Fatalf("want ErrTimeout, got %v", err)
+	}
+	// 1 + 2 + 4 minutes, without sleeping
+	if got, want := clock.Now().Sub(start), 7*time.Minute; got != want {
+		t.Errorf("want clock advanced by %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,90 @@
+package termtest
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"git.sr.ht/~mna/zzterm"
+)
+
+// EventsEqual returns true if a and b hold the same events, that is, the
+// same keys with the same bytes and, for the mouse keys, the same mouse
+// events.
+func EventsEqual(a, b []zzterm.Event) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Key != b[i].Key || !bytes.Equal(a[i].Bytes, b[i].Bytes) {
+			return false
+		}
+		if a[i].Key.Type() == zzterm.KeyMouse && a[i].Mouse != b[i].Mouse {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatEvents returns the textual golden format of the events, suitable
+// for snapshot tests of the input handling. Each event is on its own line
+// with the key, the mouse event for the mouse keys, and the bytes of the
+// event as rendered by zzterm.DumpBytes after a "|" separator, e.g.:
+//
+//	Key(U+0061 'a') | a
+//	Key(Mouse) Mouse(⇓01 x:10 y:20) | CSI <0;10;20M
+//
+// The format is stable so that golden files can be kept under version
+// control.
+func FormatEvents(events []zzterm.Event) string {
+	var sb strings.Builder
+	for _, ev := range events {
+		sb.WriteString(ev.Key.String())
+		if ev.Key.Type() == zzterm.KeyMouse {
+			sb.WriteString(" " + ev.Mouse.String())
+		}
+		sb.WriteString(" | " + zzterm.DumpBytes(ev.Bytes) + "\n")
+	}
+	return sb.String()
+}
+
+// DecodeEvents decodes the events of the raw bytes, e.g. as recorded from a
+// terminal, with an Input configured with opts. The bytes are split in
+// keys with zzterm.ScanKeys, so that the result does not depend on how the
+// bytes were read.
+func DecodeEvents(raw []byte, opts ...zzterm.Option) ([]zzterm.Event, error) {
+	input := zzterm.NewInput(opts...)
+	sc := bufio.NewScanner(bytes.NewReader(raw))
+	sc.Split(zzterm.ScanKeys)
+
+	var events []zzterm.Event
+	for sc.Scan() {
+		k, err := input.Decode(sc.Bytes())
+		if err != nil {
+			if errors.Is(err, zzterm.ErrTimeout) {
+				// filtered event
+				continue
+			}
+			return events, fmt.Errorf("termtest: decode %s: %w", zzterm.DumpBytes(sc.Bytes()), err)
+		}
+		ev := zzterm.Event{Key: k, Bytes: append([]byte{}, input.Bytes()...)}
+		if k.Type() == zzterm.KeyMouse {
+			ev.Mouse = input.Mouse()
+		}
+		events = append(events, ev)
+	}
+	return events, sc.Err()
+}
+
+// Golden returns the textual golden format (see FormatEvents) of the events
+// decoded from the raw bytes with an Input configured with opts (see
+// DecodeEvents).
+func Golden(raw []byte, opts ...zzterm.Option) (string, error) {
+	events, err := DecodeEvents(raw, opts...)
+	if err != nil {
+		return "", err
+	}
+	return FormatEvents(events), nil
+}
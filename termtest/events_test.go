@@ -0,0 +1,61 @@
+package termtest
+
+import (
+	"testing"
+
+	"git.sr.ht/~mna/zzterm"
+)
+
+func TestGolden(t *testing.T) {
+	raw := MustCompile("type 'a'; press Up; click 10,20; press Alt+x", nil)
+	got, err := Golden(raw, zzterm.WithMouse())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `Key(U+0061 'a') | a
+Key(Up) | CSI A
+Key(Mouse) Mouse(⇓01 x:10 y:20) | CSI <0;10;20M
+Key(Mouse) Mouse(⇑01 x:10 y:20) | CSI <0;10;20m
+Key(ESCSeq) | ESC x
+`
+	if got != want {
+		t.Errorf("want\n%s\ngot\n%s", want, got)
+	}
+
+	if _, err := Golden([]byte("a\xffb")); err == nil {
+		t.Error("want error for invalid byte")
+	}
+}
+
+func TestEventsEqual(t *testing.T) {
+	raw := []byte("a\x1b[<0;10;20M")
+	a, err := DecodeEvents(raw, zzterm.WithMouse())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := DecodeEvents(raw, zzterm.WithMouse())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !EventsEqual(a, b) {
+		t.Errorf("want equal events: %v, %v", a, b)
+	}
+
+	c, err := DecodeEvents([]byte("a\x1b[<0;10;21M"), zzterm.WithMouse())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if EventsEqual(a, c) {
+		t.Error("want different mouse events")
+	}
+	if EventsEqual(a, a[:1]) {
+		t.Error("want different lengths")
+	}
+	d, err := DecodeEvents(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if EventsEqual(a, d) {
+		t.Error("want different keys")
+	}
+}
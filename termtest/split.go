@@ -0,0 +1,49 @@
+package termtest
+
+import "fmt"
+
+// maxSplitLen is the maximum length of the input of EachSplit, so that the
+// number of splits fits in a uint64.
+const maxSplitLen = 64
+
+// EachSplit calls fn for every possible split of b in non-empty chunks, in
+// order, starting with b as a single chunk, until fn returns false. This can
+// be used to replay a byte stream through all the possible chunkings of the
+// reads of a terminal, e.g. with a reader that returns a chunk per call to
+// Read. It calls fn once with no chunk if b is empty.
+//
+// There are 2^(len(b)-1) splits, so this is only practical for short
+// inputs, and it panics if b is longer than 64 bytes. The chunks are
+// sub-slices of b and must not be modified.
+//
+// Note that zzterm relies on the read boundaries to tell the ESC key from
+// the start of an escape sequence, so the keys decoded from a stream that
+// contains ESC bytes depend on the chunking, unless the Input waits for the
+// rest of the sequences (see zzterm.WithSeqTimeout and
+// zzterm.WithESCTimeout).
+func EachSplit(b []byte, fn func(chunks [][]byte) bool) {
+	if len(b) > maxSplitLen {
+		panic(fmt.Sprintf("termtest: input too long to split: %d bytes (max %d)", len(b), maxSplitLen))
+	}
+	if len(b) == 0 {
+		fn(nil)
+		return
+	}
+
+	n := len(b) - 1 // number of possible split points
+	chunks := make([][]byte, 0, len(b))
+	for mask := uint64(0); mask < 1<<uint(n); mask++ {
+		chunks = chunks[:0]
+		start := 0
+		for i := 0; i < n; i++ {
+			if mask&(1<<uint(i)) != 0 {
+				chunks = append(chunks, b[start:i+1])
+				start = i + 1
+			}
+		}
+		chunks = append(chunks, b[start:])
+		if !fn(chunks) {
+			return
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package termtest
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"git.sr.ht/~mna/zzterm"
+)
+
+func TestEachSplit(t *testing.T) {
+	var got []string
+	EachSplit([]byte("abc"), func(chunks [][]byte) bool {
+		got = append(got, string(bytes.Join(chunks, []byte("|"))))
+		return true
+	})
+	want := "abc a|bc ab|c a|b|c"
+	if s := strings.Join(got, " "); s != want {
+		t.Errorf("want %s, got %s", want, s)
+	}
+
+	var n int
+	EachSplit(nil, func(chunks [][]byte) bool {
+		if chunks != nil {
+			t.Errorf("want no chunk, got %q", chunks)
+		}
+		n++
+		return true
+	})
+	EachSplit([]byte("abcd"), func(chunks [][]byte) bool {
+		n++
+		return n < 3
+	})
+	if n != 3 {
+		t.Errorf("want 3 calls, got %d", n)
+	}
+
+	// the longest input is split, a longer one panics
+	long := bytes.Repeat([]byte("a"), 65)
+	EachSplit(long[:64], func(chunks [][]byte) bool {
+		if len(chunks) != 1 {
+			t.Errorf("want a single chunk, got %d", len(chunks))
+		}
+		return false
+	})
+	defer func() {
+		if e := recover(); e == nil {
+			t.Error("want panic for input longer than 64 bytes")
+		}
+	}()
+	EachSplit(long, func(chunks [][]byte) bool { return false })
+}
+
+// chunkReader returns a chunk per call to Read.
+type chunkReader struct {
+	chunks [][]byte
+}
+
+func (r *chunkReader) Read(b []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, nil
+	}
+	n := copy(b, r.chunks[0])
+	r.chunks[0] = r.chunks[0][n:]
+	if len(r.chunks[0]) == 0 {
+		r.chunks = r.chunks[1:]
+	}
+	return n, nil
+}
+
+func decodeAll(t *testing.T, chunks [][]byte, opts ...zzterm.Option) []zzterm.Key {
+	t.Helper()
+
+	r := &chunkReader{chunks: append([][]byte(nil), chunks...)}
+	input := zzterm.NewInput(opts...)
+	var keys []zzterm.Key
+	for {
+		k, err := input.ReadKey(r)
+		if errors.Is(err, zzterm.ErrTimeout) && len(r.chunks) == 0 {
+			return keys
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, k)
+	}
+}
+
+func TestEachSplit_Decode(t *testing.T) {
+	cases := []struct {
+		corpus []string
+		opts   []zzterm.Option
+	}{
+		// streams without ESC bytes decode the same regardless of the reads
+		{[]string{
+			"hello",
+			"é😿a",
+			"a\tb\r\x7f\x01",
+			"日本語",
+		}, nil},
+
+		// and so do streams with escape sequences when the Input waits for the
+		// rest of the sequences split over multiple reads
+		{[]string{
+			"\x1b[A",
+			"a\x1b[1;5Cb",
+			"\x1bOP",
+			"é\x1b[15~",
+			"\x1b[<0;1;2M\x1b[3~",
+		}, []zzterm.Option{
+			zzterm.WithMouse(),
+			zzterm.WithSeqTimeout(time.Second),
+			zzterm.WithESCTimeout(time.Second),
+		}},
+	}
+	for _, c := range cases {
+		for _, s := range c.corpus {
+			want := decodeAll(t, [][]byte{[]byte(s)}, c.opts...)
+			EachSplit([]byte(s), func(chunks [][]byte) bool {
+				got := decodeAll(t, chunks, c.opts...)
+				if len(got) != len(want) {
+					t.Errorf("%q: want %v, got %v", chunks, want, got)
+					return false
+				}
+				for i := range got {
+					if got[i] != want[i] {
+						t.Errorf("%q: want %v, got %v", chunks, want, got)
+						return false
+					}
+				}
+				return true
+			})
+		}
+	}
+}
@@ -0,0 +1,329 @@
+// Package termtest compiles a small, human-readable script of terminal
+// interactions to the raw bytes a terminal would send for them, so that
+// tests of programs built on zzterm can describe their input naturally
+// instead of embedding raw escape sequences.
+//
+// A script is a list of statements separated by semicolons or newlines.
+// The supported statements are:
+//
+//	press <key> [<key>...]   # e.g. press Ctrl+X Ctrl+S, press Shift+Left
+//	type '<text>'            # the text is sent as-is
+//	click <x>,<y> [<button>] # button is left (default), middle or right
+//	paste '<text>'           # text wrapped in bracketed paste markers
+//
+// Text may be quoted with single quotes (taken literally) or double quotes
+// (interpreted as a Go string literal, so that escapes such as \n are
+// supported). Keys are named after the zzterm.KeyType names (e.g. Up, PgDn,
+// F1, ESC, TAB) or are a single rune, optionally prefixed with modifiers
+// (Ctrl, Alt, Meta and Shift) separated by "+". The aliases Enter, Esc,
+// Escape, Tab, Backspace and Space are also supported. Key names and
+// modifiers are case-insensitive.
+//
+// The package also exports test vectors for the parsing of control
+// sequences (see CSIVectors), so that parsers can be validated against the
+// grammar supported by zzterm, and helpers for snapshot tests of the
+// decoded events (see Golden).
+package termtest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"git.sr.ht/~mna/zzterm"
+)
+
+// Compile compiles the script to the stream of bytes that a terminal would
+// send to the application. The escape sequences of special keys are those
+// of the tinfo map, which has the same semantics as for zzterm.WithESCSeq
+// (the default mapping is used if it is nil).
+func Compile(script string, tinfo map[string]string) ([]byte, error) {
+	stmts, err := splitStatements(script)
+	if err != nil {
+		return nil, err
+	}
+
+	c := compiler{seqs: make(map[keyMod]string)}
+	for k, seq := range zzterm.KeySequences(tinfo) {
+		c.seqs[keyMod{k.Type(), k.Mod()}] = seq
+	}
+	for i, stmt := range stmts {
+		if err := c.statement(stmt); err != nil {
+			return nil, fmt.Errorf("termtest: statement %d (%q): %w", i+1, stmt, err)
+		}
+	}
+	return c.buf, nil
+}
+
+// MustCompile is like Compile but panics if the script is invalid.
+func MustCompile(script string, tinfo map[string]string) []byte {
+	b, err := Compile(script, tinfo)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+type keyMod struct {
+	t zzterm.KeyType
+	m zzterm.Mod
+}
+
+type compiler struct {
+	buf  []byte
+	seqs map[keyMod]string
+}
+
+func (c *compiler) statement(stmt string) error {
+	cmd, arg := stmt, ""
+	if ix := strings.IndexFunc(stmt, unicode.IsSpace); ix >= 0 {
+		cmd, arg = stmt[:ix], strings.TrimSpace(stmt[ix:])
+	}
+
+	switch strings.ToLower(cmd) {
+	case "press":
+		keys := strings.Fields(arg)
+		if len(keys) == 0 {
+			return fmt.Errorf("missing key")
+		}
+		for _, k := range keys {
+			if err := c.press(k); err != nil {
+				return err
+			}
+		}
+
+	case "type":
+		s, err := unquote(arg)
+		if err != nil {
+			return err
+		}
+		c.buf = append(c.buf, s...)
+
+	case "paste":
+		s, err := unquote(arg)
+		if err != nil {
+			return err
+		}
+		c.buf = append(c.buf, "\x1b[200~"...)
+		c.buf = append(c.buf, s...)
+		c.buf = append(c.buf, "\x1b[201~"...)
+
+	case "click":
+		return c.click(arg)
+
+	default:
+		return fmt.Errorf("unknown statement %q", cmd)
+	}
+	return nil
+}
+
+func (c *compiler) click(arg string) error {
+	coords, btnName := arg, "left"
+	if ix := strings.IndexFunc(arg, unicode.IsSpace); ix >= 0 {
+		coords, btnName = arg[:ix], strings.TrimSpace(arg[ix:])
+	}
+
+	var btn int
+	switch strings.ToLower(btnName) {
+	case "left":
+		btn = 0
+	case "middle":
+		btn = 1
+	case "right":
+		btn = 2
+	default:
+		return fmt.Errorf("invalid mouse button %q", btnName)
+	}
+
+	parts := strings.Split(coords, ",")
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid coordinates %q", coords)
+	}
+	x, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || x < 1 {
+		return fmt.Errorf("invalid x coordinate %q", parts[0])
+	}
+	y, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil || y < 1 {
+		return fmt.Errorf("invalid y coordinate %q", parts[1])
+	}
+
+	c.buf = append(c.buf, fmt.Sprintf("\x1b[<%d;%d;%dM\x1b[<%[1]d;%d;%dm", btn, x, y)...)
+	return nil
+}
+
+func (c *compiler) press(spec string) error {
+	parts := strings.Split(spec, "+")
+	name := parts[len(parts)-1]
+	if name == "" && len(parts) > 1 {
+		// the key is "+" itself, e.g. "Ctrl++"
+		name = "+"
+		parts = parts[:len(parts)-1]
+	}
+
+	var mod zzterm.Mod
+	for _, p := range parts[:len(parts)-1] {
+		switch strings.ToLower(p) {
+		case "ctrl":
+			mod |= zzterm.ModCtrl
+		case "alt":
+			mod |= zzterm.ModAlt
+		case "meta":
+			mod |= zzterm.ModMeta
+		case "shift":
+			mod |= zzterm.ModShift
+		default:
+			return fmt.Errorf("invalid modifier %q", p)
+		}
+	}
+
+	if r, sz := utf8.DecodeRuneInString(name); sz == len(name) && r != utf8.RuneError {
+		return c.pressRune(r, mod)
+	}
+
+	typ, ok := keyTypes[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("unknown key %q", name)
+	}
+	if typ == zzterm.KeyRune {
+		// only Space is a named rune
+		return c.pressRune(' ', mod)
+	}
+	if typ == zzterm.KeyTAB && mod == zzterm.ModShift {
+		typ, mod = zzterm.KeyBacktab, zzterm.ModNone
+	}
+
+	// a control character can only be combined with Alt, which prefixes it
+	// with ESC.
+	if typ <= zzterm.KeyUS || typ == zzterm.KeyDEL {
+		if mod&^(zzterm.ModAlt|zzterm.ModMeta) != 0 {
+			return fmt.Errorf("unsupported modifiers for key %s", typ)
+		}
+		if mod != zzterm.ModNone {
+			c.buf = append(c.buf, '\x1b')
+		}
+		c.buf = append(c.buf, byte(typ))
+		return nil
+	}
+
+	seq, ok := c.seqs[keyMod{typ, mod}]
+	if !ok {
+		return fmt.Errorf("no escape sequence for key %s%s", mod, typ)
+	}
+	c.buf = append(c.buf, seq...)
+	return nil
+}
+
+func (c *compiler) pressRune(r rune, mod zzterm.Mod) error {
+	if mod&(zzterm.ModAlt|zzterm.ModMeta) != 0 {
+		c.buf = append(c.buf, '\x1b')
+	}
+	if mod&zzterm.ModShift != 0 {
+		r = unicode.ToUpper(r)
+	}
+	if mod&zzterm.ModCtrl != 0 {
+		b, ok := ctrlByte(r)
+		if !ok {
+			return fmt.Errorf("no control character for %q", r)
+		}
+		c.buf = append(c.buf, b)
+		return nil
+	}
+	c.buf = append(c.buf, string(r)...)
+	return nil
+}
+
+func ctrlByte(r rune) (byte, bool) {
+	switch {
+	case r >= 'a' && r <= 'z':
+		return byte(r - 'a' + 1), true
+	case r >= '@' && r <= '_':
+		return byte(r - '@'), true
+	case r == ' ':
+		return 0, true
+	case r == '?':
+		return 0x7f, true
+	}
+	return 0, false
+}
+
+// keyTypes maps the lowercase names of key types to the KeyType.
+var keyTypes = func() map[string]zzterm.KeyType {
+	m := map[string]zzterm.KeyType{
+		"enter":     zzterm.KeyEnter,
+		"esc":       zzterm.KeyEscape,
+		"escape":    zzterm.KeyEscape,
+		"tab":       zzterm.KeyTAB,
+		"backspace": zzterm.KeyBackspace,
+		"space":     zzterm.KeyRune,
+	}
+	for t := zzterm.KeyNUL; t <= zzterm.KeyKPMultiply; t++ {
+		name := t.String()
+		if _, err := strconv.Atoi(name); err == nil {
+			continue
+		}
+		if _, ok := m[strings.ToLower(name)]; !ok {
+			m[strings.ToLower(name)] = t
+		}
+	}
+	return m
+}()
+
+func unquote(s string) (string, error) {
+	if len(s) < 2 {
+		return "", fmt.Errorf("invalid quoted text %q", s)
+	}
+	switch s[0] {
+	case '\'':
+		if s[len(s)-1] != '\'' {
+			return "", fmt.Errorf("invalid quoted text %q", s)
+		}
+		return s[1 : len(s)-1], nil
+	case '"':
+		return strconv.Unquote(s)
+	}
+	return "", fmt.Errorf("invalid quoted text %q", s)
+}
+
+// splitStatements splits the script in statements separated by semicolons
+// or newlines, ignoring separators inside quoted text. Empty statements are
+// skipped.
+func splitStatements(script string) ([]string, error) {
+	var (
+		stmts []string
+		quote rune
+		esc   bool
+		start int
+	)
+
+	add := func(end int) {
+		if s := strings.TrimSpace(script[start:end]); s != "" {
+			stmts = append(stmts, s)
+		}
+	}
+
+	for i, r := range script {
+		switch {
+		case quote == '"' && esc:
+			esc = false
+		case quote == '"' && r == '\\':
+			esc = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == ';' || r == '\n':
+			add(i)
+			start = i + 1
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("termtest: unterminated quoted text")
+	}
+	add(len(script))
+	return stmts, nil
+}
@@ -0,0 +1,86 @@
+package termtest
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"git.sr.ht/~mna/zzterm"
+)
+
+func TestCompile(t *testing.T) {
+	cases := []struct {
+		script string
+		out    string
+		err    string
+	}{
+		{"", "", ""},
+		{"type 'hello'", "hello", ""},
+		{`type "a\tb"`, "a\tb", ""},
+		{"type 'a;b'; type \"c\\\"d\"", "a;bc\"d", ""},
+		{"press a B 1", "aB1", ""},
+		{"press Ctrl+X Ctrl+s ctrl+space Ctrl+[", "\x18\x13\x00\x1b", ""},
+		{"press Alt+x Shift+a Alt+Enter", "\x1bxA\x1b\r", ""},
+		{"press Up Shift+Left shift+tab F1 Esc", "\x1b[A\x1b[1;2D\x1b[Z\x1bOP\x1b", ""},
+		{"press Ctrl++", "", "no control character"},
+		{"press Ctrl+Up", "", "no escape sequence"},
+		{"press Hyper+a", "", "invalid modifier"},
+		{"press Nope", "", "unknown key"},
+		{"press", "", "missing key"},
+		{"click 3,4", "\x1b[<0;3;4M\x1b[<0;3;4m", ""},
+		{"click 3,4 right", "\x1b[<2;3;4M\x1b[<2;3;4m", ""},
+		{"click 3", "", "invalid coordinates"},
+		{"click 0,1", "", "invalid x coordinate"},
+		{"paste 'abc'", "\x1b[200~abc\x1b[201~", ""},
+		{"paste 'abc", "", "unterminated"},
+		{"jump", "", "unknown statement"},
+		{"type 'x'\n\npress Enter\n", "x\r", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.script, func(t *testing.T) {
+			b, err := Compile(c.script, nil)
+			if c.err != "" {
+				if err == nil || !bytes.Contains([]byte(err.Error()), []byte(c.err)) {
+					t.Fatalf("want error containing %q, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != c.out {
+				t.Fatalf("want %q, got %q", c.out, b)
+			}
+		})
+	}
+}
+
+func TestCompile_Decode(t *testing.T) {
+	cases := []struct {
+		script string
+		want   string
+	}{
+		{"type 'h'", "Key(U+0068 'h')"},
+		{"press Ctrl+C", "Key(ETX)"},
+		{"press Up", "Key(Up)"},
+		{"press Shift+Right", "Key(⇧ Right)"},
+		{"press F5", "Key(F5)"},
+	}
+
+	input := zzterm.NewInput()
+	for _, c := range cases {
+		t.Run(c.script, func(t *testing.T) {
+			r := bytes.NewReader(MustCompile(c.script, nil))
+			k, err := input.ReadKey(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if k.String() != c.want {
+				t.Fatalf("want %s, got %s", c.want, k)
+			}
+			if _, err := input.ReadKey(r); !errors.Is(err, zzterm.ErrTimeout) {
+				t.Fatalf("want ErrTimeout, got %v", err)
+			}
+		})
+	}
+}
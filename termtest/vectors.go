@@ -0,0 +1,96 @@
+package termtest
+
+// CSIVector is a test vector for the parsing of Control Sequence Introducer
+// (CSI) sequences, as done by zzterm.ParseCSI. It can be used to validate
+// other parsers against the same grammar.
+type CSIVector struct {
+	Name string // short description of the case
+	In   string // input bytes
+
+	// Len is the expected length of the parsed sequence, 0 if the input
+	// does not start with a valid and complete sequence (in which case the
+	// other fields are irrelevant).
+	Len          int
+	Prefix       byte
+	Intermediate byte
+	Final        byte
+	Params       []int // -1 for an omitted parameter
+
+	// SubParams are the sub-parameters of each parameter (-1 if omitted), in
+	// the same order as Params. If nil, the parameters have no
+	// sub-parameter.
+	SubParams [][]int
+}
+
+// CSIVectors is the list of CSI test vectors. It covers the edge cases of
+// the DEC/xterm control sequence grammar as supported by zzterm: private
+// parameter prefixes, omitted and out-of-range parameters, the limit on
+// the number of parameters, colon-separated sub-parameters, intermediate
+// bytes and the range of the final byte. Multiple intermediate bytes are
+// not supported, such sequences are invalid.
+var CSIVectors = []CSIVector{
+	// introducer
+	{Name: "empty", In: ""},
+	{Name: "esc only", In: "\x1b"},
+	{Name: "introducer only", In: "\x1b["},
+	{Name: "ss3", In: "\x1bOA"},
+	{Name: "8-bit csi", In: "\x9bA"},
+
+	// final byte
+	{Name: "no param", In: "\x1b[A", Len: 3, Final: 'A'},
+	{Name: "final lowest", In: "\x1b[@", Len: 3, Final: '@'},
+	{Name: "final highest", In: "\x1b[~", Len: 3, Final: '~'},
+	{Name: "final DEL", In: "\x1b[1\x7f"},
+	{Name: "incomplete", In: "\x1b[1;2"},
+	{Name: "control in sequence", In: "\x1b[1\x1b[A"},
+	{Name: "trailing bytes", In: "\x1b[Bxyz", Len: 3, Final: 'B'},
+
+	// parameters
+	{Name: "one param", In: "\x1b[5~", Len: 4, Final: '~', Params: []int{5}},
+	{Name: "two params", In: "\x1b[1;5D", Len: 6, Final: 'D', Params: []int{1, 5}},
+	{Name: "leading zeros", In: "\x1b[007A", Len: 6, Final: 'A', Params: []int{7}},
+	{Name: "zero param", In: "\x1b[0n", Len: 4, Final: 'n', Params: []int{0}},
+	{Name: "omitted first", In: "\x1b[;2H", Len: 5, Final: 'H', Params: []int{-1, 2}},
+	{Name: "omitted last", In: "\x1b[2;H", Len: 5, Final: 'H', Params: []int{2, -1}},
+	{Name: "all omitted", In: "\x1b[;;H", Len: 5, Final: 'H', Params: []int{-1, -1, -1}},
+	{Name: "max int32", In: "\x1b[2147483647A", Len: 13, Final: 'A', Params: []int{2147483647}},
+	{Name: "overflow clamped", In: "\x1b[2147483648A", Len: 13, Final: 'A', Params: []int{2147483647}},
+	{Name: "huge clamped", In: "\x1b[99999999999999999999999A", Len: 26, Final: 'A', Params: []int{2147483647}},
+	{
+		Name: "max params", In: "\x1b[1;2;3;4;5;6;7;8;9;10;11;12;13;14;15;16m", Len: 41, Final: 'm',
+		Params: []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+	},
+	{Name: "too many params", In: "\x1b[1;2;3;4;5;6;7;8;9;10;11;12;13;14;15;16;17m"},
+	{Name: "too many omitted params", In: "\x1b[;;;;;;;;;;;;;;;;m"},
+	{Name: "prefix after param", In: "\x1b[1?A"},
+
+	// sub-parameters
+	{
+		Name: "sgr sub-params", In: "\x1b[38:2::255:0:0m", Len: 16, Final: 'm',
+		Params: []int{38}, SubParams: [][]int{{2, -1, 255, 0, 0}},
+	},
+	{
+		Name: "kitty sub-params", In: "\x1b[97:65;2u", Len: 10, Final: 'u',
+		Params: []int{97, 2}, SubParams: [][]int{{65}, nil},
+	},
+	{
+		Name: "omitted param with sub-param", In: "\x1b[:1;2u", Len: 7, Final: 'u',
+		Params: []int{-1, 2}, SubParams: [][]int{{1}, nil},
+	},
+	{Name: "too many sub-params", In: "\x1b[1:2:3:4:5:6:7:8:9:10:11:12:13:14:15:16:17m"},
+
+	// private prefixes
+	{Name: "prefix ?", In: "\x1b[?1049h", Len: 8, Prefix: '?', Final: 'h', Params: []int{1049}},
+	{Name: "prefix >", In: "\x1b[>0;95;0c", Len: 10, Prefix: '>', Final: 'c', Params: []int{0, 95, 0}},
+	{Name: "prefix =", In: "\x1b[=1u", Len: 5, Prefix: '=', Final: 'u', Params: []int{1}},
+	{Name: "prefix <", In: "\x1b[<0;10;20M", Len: 11, Prefix: '<', Final: 'M', Params: []int{0, 10, 20}},
+	{Name: "prefix only", In: "\x1b[?c", Len: 4, Prefix: '?', Final: 'c'},
+	{Name: "two prefixes", In: "\x1b[??1h"},
+
+	// intermediate bytes
+	{Name: "intermediate space", In: "\x1b[2 q", Len: 5, Intermediate: ' ', Final: 'q', Params: []int{2}},
+	{Name: "intermediate $", In: "\x1b[?1049;1$y", Len: 11, Prefix: '?', Intermediate: '$', Final: 'y', Params: []int{1049, 1}},
+	{Name: "intermediate no param", In: "\x1b[!p", Len: 4, Intermediate: '!', Final: 'p'},
+	{Name: "two intermediates", In: "\x1b[1$$y"},
+	{Name: "param after intermediate", In: "\x1b[1$1y"},
+}
@@ -0,0 +1,45 @@
+package termtest
+
+import (
+	"testing"
+
+	"git.sr.ht/~mna/zzterm"
+)
+
+func TestCSIVectors(t *testing.T) {
+	for _, v := range CSIVectors {
+		t.Run(v.Name, func(t *testing.T) {
+			seq, n := zzterm.ParseCSI([]byte(v.In))
+			if n != v.Len {
+				t.Fatalf("%q: want length %d, got %d", v.In, v.Len, n)
+			}
+			if n == 0 {
+				return
+			}
+			if seq.Prefix != v.Prefix || seq.Intermediate != v.Intermediate || seq.Final != v.Final {
+				t.Errorf("%q: want prefix %q, intermediate %q and final %q, got %q, %q and %q",
+					v.In, v.Prefix, v.Intermediate, v.Final, seq.Prefix, seq.Intermediate, seq.Final)
+			}
+			if seq.NumParams() != len(v.Params) {
+				t.Fatalf("%q: want %d params, got %d", v.In, len(v.Params), seq.NumParams())
+			}
+			for i, want := range v.Params {
+				if got := seq.Param(i); got != want {
+					t.Errorf("%q: param %d: want %d, got %d", v.In, i, want, got)
+				}
+				var subs []int
+				if v.SubParams != nil {
+					subs = v.SubParams[i]
+				}
+				if got := seq.NumSubParams(i); got != len(subs) {
+					t.Fatalf("%q: param %d: want %d sub-params, got %d", v.In, i, len(subs), got)
+				}
+				for j, want := range subs {
+					if got := seq.SubParam(i, j); got != want {
+						t.Errorf("%q: param %d: sub-param %d: want %d, got %d", v.In, i, j, want, got)
+					}
+				}
+			}
+		})
+	}
+}
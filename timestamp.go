@@ -0,0 +1,31 @@
+package zzterm
+
+import "time"
+
+// WithTimestamps enables the recording of the arrival time of the keys, so
+// that the latency introduced by the decoding can be measured, e.g. to tune
+// the timeout of two-key escapes or chords. The arrival time of a key is
+// the time at which the read that returned its first byte completed, as
+// given by the Clock of the Input. See Input.Timestamp and Input.Latency.
+func WithTimestamps() Option {
+	return func(i *Input) {
+		i.stamps = true
+	}
+}
+
+// Timestamp returns the arrival time of the first byte of the last key
+// returned by ReadKey, if WithTimestamps is set. Otherwise it returns the
+// zero time. Bytes read in the same call to the reader share the same
+// arrival time.
+func (i *Input) Timestamp() time.Time {
+	return i.keyAt
+}
+
+// Latency returns the time between the arrival of the first byte of the
+// last key returned by ReadKey and the return of that key, if
+// WithTimestamps is set. Otherwise it returns 0. This includes the time
+// that the key was held back by the Input, e.g. while waiting for the
+// second key of a two-key escape, or while it was queued by Expect.
+func (i *Input) Latency() time.Duration {
+	return i.keyLat
+}
@@ -0,0 +1,128 @@
+package zzterm
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock whose time only changes when Sleep is called.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// clockedReader returns a chunk of bytes per call to Read and advances the
+// clock by delay before each read. The part of a chunk that does not fit in
+// the read buffer is returned by the next read.
+type clockedReader struct {
+	clock  *fakeClock
+	chunks []string
+	delay  time.Duration
+}
+
+func (r *clockedReader) Read(b []byte) (int, error) {
+	r.clock.Sleep(r.delay)
+	if len(r.chunks) == 0 {
+		return 0, nil
+	}
+	n := copy(b, r.chunks[0])
+	if n < len(r.chunks[0]) {
+		r.chunks[0] = r.chunks[0][n:]
+	} else {
+		r.chunks = r.chunks[1:]
+	}
+	return n, nil
+}
+
+func TestInput_Timestamps(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	sec := func(n int) time.Time { return start.Add(time.Duration(n) * time.Second) }
+
+	t.Run("disabled", func(t *testing.T) {
+		clock := &fakeClock{now: start}
+		input := NewInput(WithClock(clock))
+		if _, err := input.ReadKey(&clockedReader{clock: clock, chunks: []string{"a"}, delay: time.Second}); err != nil {
+			t.Fatal(err)
+		}
+		if !input.Timestamp().IsZero() || input.Latency() != 0 {
+			t.Errorf("want no timestamp, got %s, %s", input.Timestamp(), input.Latency())
+		}
+	})
+
+	t.Run("buffered", func(t *testing.T) {
+		clock := &fakeClock{now: start}
+		input := NewInput(WithClock(clock), WithTimestamps())
+		r := &clockedReader{clock: clock, chunks: []string{"ab", "c"}, delay: time.Second}
+
+		want := []time.Time{sec(1), sec(1), sec(2)}
+		for j, w := range want {
+			if _, err := input.ReadKey(r); err != nil {
+				t.Fatal(err)
+			}
+			if got := input.Timestamp(); !got.Equal(w) {
+				t.Errorf("[%d]: want timestamp %s, got %s", j, w, got)
+			}
+			if got := input.Latency(); got != 0 {
+				t.Errorf("[%d]: want no latency, got %s", j, got)
+			}
+		}
+	})
+
+	t.Run("two-key", func(t *testing.T) {
+		clock := &fakeClock{now: start}
+		input := NewInput(WithClock(clock), WithTimestamps(), WithTwoKeyEscape(time.Minute, "jk"))
+		r := &clockedReader{clock: clock, chunks: []string{"j", "x"}, delay: time.Second}
+
+		for j, want := range []struct {
+			k   Key
+			at  time.Time
+			lat time.Duration
+		}{
+			{'j', sec(1), time.Second},
+			{'x', sec(2), 0},
+		} {
+			k, err := input.ReadKey(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if k != want.k || !input.Timestamp().Equal(want.at) || input.Latency() != want.lat {
+				t.Errorf("[%d]: want %s at %s (%s), got %s at %s (%s)", j, want.k, want.at, want.lat,
+					k, input.Timestamp(), input.Latency())
+			}
+		}
+	})
+
+	t.Run("expect", func(t *testing.T) {
+		clock := &fakeClock{now: start}
+		input := NewInput(WithClock(clock), WithTimestamps())
+		r := &clockedReader{clock: clock, chunks: []string{"a", "b"}, delay: time.Second}
+
+		if _, err := input.Expect(context.Background(), r, func(ev Event) bool { return ev.Key == 'b' }); err != nil {
+			t.Fatal(err)
+		}
+		clock.Sleep(time.Second)
+		if k, err := input.ReadKey(r); err != nil || k != 'a' {
+			t.Fatalf("want 'a', got %s, %v", k, err)
+		}
+		if got := input.Timestamp(); !got.Equal(sec(1)) {
+			t.Errorf("want timestamp %s, got %s", sec(1), got)
+		}
+		if got := input.Latency(); got != 2*time.Second {
+			t.Errorf("want latency %s, got %s", 2*time.Second, got)
+		}
+	})
+}
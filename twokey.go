@@ -0,0 +1,132 @@
+package zzterm
+
+import (
+	"io"
+	"time"
+	"unicode/utf8"
+)
+
+type twoKeyEscape struct {
+	timeout time.Duration
+	pairs   [][2]Key
+
+	pending    Key // first key of a pair, valid if hasPending
+	hasPending bool
+	at         time.Time // time the pending key was read
+	replay     Key       // key to return before reading more, valid if hasReplay
+	hasReplay  bool
+	err        error // error to return before reading more, read after the pending key
+
+	// arrival time of the first byte of the pending and replay keys, if
+	// timestamps are enabled
+	pendingAt time.Time
+	replayAt  time.Time
+}
+
+// WithTwoKeyEscape enables the detection of two-key escapes, as popularized
+// by vi users that map e.g. "jk" to leave the insert mode. Each pair must
+// be a string of exactly two runes, and if the second rune is read within
+// timeout of the first one, ReadKey returns a single KeyESC key instead of
+// the two runes. Otherwise the runes are returned individually.
+//
+// The first rune of a pair is not returned until the second one is read or
+// the timeout expires, so for the timeout to be detected while no key is
+// pressed, the reader must have a read timeout so that ReadKey is called
+// regularly (it returns ErrTimeout while the first rune is pending). When
+// the first rune is returned individually because another key was read,
+// the other key is returned by the next call to ReadKey without reading
+// from the reader, so Input.Bytes and Input.Mouse only correspond to the
+// last key read. For a two-key escape, Input.Bytes returns the bytes of
+// the second rune. If the read fails with an error other than ErrTimeout
+// while the first rune is pending (e.g. the terminal is closed), the rune
+// is returned first and the error by the next call to ReadKey.
+//
+// It panics if a pair does not have exactly two runes.
+func WithTwoKeyEscape(timeout time.Duration, pairs ...string) Option {
+	keys := make([][2]Key, 0, len(pairs))
+	for _, p := range pairs {
+		if utf8.RuneCountInString(p) != 2 {
+			panic("zzterm: two-key escape must have two runes: " + p)
+		}
+		first, sz := utf8.DecodeRuneInString(p)
+		second, _ := utf8.DecodeRuneInString(p[sz:])
+		keys = append(keys, [2]Key{Key(first), Key(second)})
+	}
+	return func(i *Input) {
+		i.twoKey = &twoKeyEscape{timeout: timeout, pairs: keys}
+	}
+}
+
+func (i *Input) readTwoKey(r io.Reader) (Key, error) {
+	tk := i.twoKey
+	for {
+		var (
+			k   Key
+			err error
+		)
+		if tk.err != nil {
+			err, tk.err = tk.err, nil
+			return 0, err
+		}
+		if tk.hasReplay {
+			k, tk.hasReplay = tk.replay, false
+			i.keyAt = tk.replayAt
+		} else {
+			k, err = i.readNext(r)
+		}
+
+		if tk.hasPending {
+			expired := i.clock.Now().Sub(tk.at) > tk.timeout
+			if err != nil {
+				if err == ErrTimeout && !expired {
+					return 0, err
+				}
+				// return the pending key first, and the error (if not a
+				// timeout) by the next call
+				if err != ErrTimeout {
+					tk.err = err
+				}
+				tk.hasPending = false
+				i.keyAt = tk.pendingAt
+				return tk.pending, nil
+			}
+
+			tk.hasPending = false
+			if !expired && tk.isPair(tk.pending, k) {
+				i.keyAt = tk.pendingAt
+				return keyFromTypeMod(KeyESC, ModNone), nil
+			}
+			tk.replay, tk.hasReplay, tk.replayAt = k, true, i.keyAt
+			i.keyAt = tk.pendingAt
+			return tk.pending, nil
+		}
+
+		if err != nil {
+			return 0, err
+		}
+		if tk.isFirst(k) {
+			tk.pending, tk.hasPending, tk.pendingAt = k, true, i.keyAt
+			tk.at = i.clock.Now()
+			continue
+		}
+		return k, nil
+	}
+}
+
+func (tk *twoKeyEscape) isFirst(k Key) bool {
+	for _, p := range tk.pairs {
+		if p[0] == k {
+			return true
+		}
+	}
+	return false
+}
+
+func (tk *twoKeyEscape) isPair(first, second Key) bool {
+	for _, p := range tk.pairs {
+		if p[0] == first && p[1] == second {
+			return true
+		}
+	}
+	return false
+}
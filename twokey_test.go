@@ -0,0 +1,91 @@
+package zzterm
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInput_ReadKey_TwoKeyEscape(t *testing.T) {
+	esc := NewKey(KeyESC, ModNone)
+	cases := []struct {
+		chunks []string
+		want   []Key
+	}{
+		{[]string{"j", "k"}, []Key{esc}},
+		{[]string{"jk"}, []Key{esc}},
+		{[]string{"k", "j"}, []Key{esc}},
+		{[]string{"j", "", "k", ""}, []Key{'j', 'k'}},
+		{[]string{"a", "j", "x"}, []Key{'a', 'j', 'x'}},
+		{[]string{"j", "j", "k"}, []Key{'j', esc}},
+		{[]string{"j", "\x1b[A"}, []Key{'j', NewKey(KeyUp, ModNone)}},
+		{[]string{"jkj", ""}, []Key{esc, 'j'}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.chunks[0], func(t *testing.T) {
+			r := &scriptReader{chunks: c.chunks, delay: 20 * time.Millisecond}
+			input := NewInput(WithTwoKeyEscape(10*time.Millisecond, "jk", "kj"))
+
+			for i, want := range c.want {
+				var (
+					k   Key
+					err error
+				)
+				for {
+					k, err = input.ReadKey(r)
+					if !errors.Is(err, ErrTimeout) || len(r.chunks) == 0 {
+						break
+					}
+				}
+				if err != nil {
+					t.Fatalf("[%d]: %v", i, err)
+				}
+				if k != want {
+					t.Errorf("[%d]: want %s, got %s", i, want, k)
+				}
+			}
+			if k, err := input.ReadKey(r); err != ErrTimeout {
+				t.Errorf("want ErrTimeout, got %s, %v", k, err)
+			}
+		})
+	}
+}
+
+func TestInput_ReadKey_TwoKeyEscapeError(t *testing.T) {
+	for _, c := range []struct {
+		opts []Option
+		err  error
+	}{
+		{[]Option{WithEOFAsClosed()}, ErrTerminalClosed},
+		{nil, errors.New("other")},
+	} {
+		t.Run(c.err.Error(), func(t *testing.T) {
+			readErr := c.err
+			if readErr == ErrTerminalClosed {
+				readErr = io.EOF
+			}
+			input := NewInput(append(c.opts, WithTwoKeyEscape(time.Minute, "jk"))...)
+			r := io.MultiReader(strings.NewReader("j"), errReader{readErr})
+
+			// the pending rune is returned before the error
+			if k, err := input.ReadKey(r); err != nil || k != 'j' {
+				t.Fatalf("want 'j', got %s, %v", k, err)
+			}
+			if _, err := input.ReadKey(r); !errors.Is(err, c.err) {
+				t.Fatalf("want %v, got %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestWithTwoKeyEscape_Invalid(t *testing.T) {
+	defer func() {
+		if e := recover(); e == nil {
+			t.Fatal("want panic")
+		}
+	}()
+	WithTwoKeyEscape(time.Second, "jkl")
+}
@@ -0,0 +1,135 @@
+package zzterm
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// UDK is a user-defined key of a DECUDK definition: the string Seq is sent
+// by the terminal when Key is pressed. On VT320-compatible terminals, the
+// user-defined keys are the shifted function keys F6 to F20, so Key is one
+// of those keys with the ModShift modifier.
+type UDK struct {
+	Key Key
+	Seq string
+}
+
+// DECUDK is a DEC user-defined keys definition, as sent to the terminal in
+// a Device Control String (DCS) to program the strings sent by the
+// function keys. See https://vt100.net/docs/vt320-uu/chapter4.html
+type DECUDK struct {
+	// ClearAll is true if all the user-defined keys are cleared before the
+	// new ones are defined (Pc parameter is 0 or omitted), otherwise only
+	// the keys that are defined are cleared (Pc parameter is 1).
+	ClearAll bool
+
+	// Lock is true if the keys are locked against future redefinition (Pl
+	// parameter is 0 or omitted).
+	Lock bool
+
+	// Keys is the list of keys defined, in the order of the definition.
+	Keys []UDK
+}
+
+// maps the key selector of DECUDK to the function keys F6 to F20.
+var udkKeys = map[int]KeyType{
+	17: KeyF6, 18: KeyF7, 19: KeyF8, 20: KeyF9, 21: KeyF10,
+	23: KeyF11, 24: KeyF12, 25: KeyF13, 26: KeyF14,
+	28: KeyF15, 29: KeyF16,
+	31: KeyF17, 32: KeyF18, 33: KeyF19, 34: KeyF20,
+}
+
+// ParseDECUDK parses the DECUDK definition in b, which must be a complete
+// Device Control String: "ESC P Pc ; Pl | Ky1 / St1 ; Ky2 / St2 ... ESC \"
+// where each Ky is a key selector and St the string encoded as hexadecimal
+// pairs. The 8-bit DCS and ST introducers are also supported. The resulting
+// keys can be registered with Input.AddESCSeq so that the strings they
+// send are decoded as the user-defined keys.
+func ParseDECUDK(b []byte) (DECUDK, error) {
+	var d DECUDK
+
+	switch {
+	case bytes.HasPrefix(b, []byte("\x1bP")):
+		b = b[2:]
+	case bytes.HasPrefix(b, []byte("\x90")):
+		b = b[1:]
+	default:
+		return d, fmt.Errorf("zzterm: DECUDK: missing DCS introducer: %s", DumpBytes(b))
+	}
+	switch {
+	case bytes.HasSuffix(b, []byte("\x1b\\")):
+		b = b[:len(b)-2]
+	case bytes.HasSuffix(b, []byte("\x9c")):
+		b = b[:len(b)-1]
+	default:
+		return d, fmt.Errorf("zzterm: DECUDK: missing string terminator")
+	}
+
+	ix := bytes.IndexByte(b, '|')
+	if ix < 0 {
+		return d, fmt.Errorf("zzterm: DECUDK: missing final character")
+	}
+	params, defs := b[:ix], b[ix+1:]
+
+	// parse the parameters with the CSI grammar
+	csi := make([]byte, 0, len(params)+3)
+	csi = append(append(append(csi, "\x1b["...), params...), 'x')
+	seq, n := ParseCSI(csi)
+	if n == 0 || seq.Prefix != 0 || seq.Intermediate != 0 || seq.NumParams() > 2 || seq.HasSubParams() {
+		return d, fmt.Errorf("zzterm: DECUDK: invalid parameters: %s", DumpBytes(params))
+	}
+	switch seq.ParamOr(0, 0) {
+	case 0:
+		d.ClearAll = true
+	case 1:
+	default:
+		return d, fmt.Errorf("zzterm: DECUDK: invalid clear parameter: %d", seq.Param(0))
+	}
+	switch seq.ParamOr(1, 0) {
+	case 0:
+		d.Lock = true
+	case 1:
+	default:
+		return d, fmt.Errorf("zzterm: DECUDK: invalid lock parameter: %d", seq.Param(1))
+	}
+
+	if len(defs) == 0 {
+		return d, nil
+	}
+	for _, def := range strings.Split(string(defs), ";") {
+		ix := strings.IndexByte(def, '/')
+		if ix < 0 {
+			return d, fmt.Errorf("zzterm: DECUDK: invalid key definition: %q", def)
+		}
+		sel, err := parseUintBytes([]byte(def[:ix]))
+		if err != nil {
+			return d, fmt.Errorf("zzterm: DECUDK: invalid key selector: %q", def[:ix])
+		}
+		kt, ok := udkKeys[int(sel)]
+		if !ok {
+			return d, fmt.Errorf("zzterm: DECUDK: unknown key selector: %d", sel)
+		}
+		s, err := hex.DecodeString(def[ix+1:])
+		if err != nil {
+			return d, fmt.Errorf("zzterm: DECUDK: invalid string of key %d: %w", sel, err)
+		}
+		d.Keys = append(d.Keys, UDK{Key: keyFromTypeMod(kt, ModShift), Seq: string(s)})
+	}
+	return d, nil
+}
+
+// AddESCSeq adds or replaces the escape sequence seq in the mapping of
+// escape sequences to keys of the Input, so that it is decoded as the key
+// k, e.g. to register the user-defined keys of a DECUDK definition at
+// runtime. Only sequences that start with ESC can be mapped, it returns
+// false without changing the mapping otherwise. It must not be called
+// concurrently with ReadKey.
+func (i *Input) AddESCSeq(seq string, k Key) bool {
+	if len(seq) < 2 || seq[0] != '\x1b' {
+		return false
+	}
+	i.esc[seq] = k
+	return true
+}
@@ -0,0 +1,89 @@
+package zzterm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDECUDK(t *testing.T) {
+	f6 := NewKey(KeyF6, ModShift)
+	f11 := NewKey(KeyF11, ModShift)
+	f20 := NewKey(KeyF20, ModShift)
+
+	cases := []struct {
+		in   string
+		want DECUDK
+		err  string
+	}{
+		{"\x1bP|\x1b\\", DECUDK{ClearAll: true, Lock: true}, ""},
+		{"\x1bP1;1|\x1b\\", DECUDK{}, ""},
+		{"\x1bP0;1|17/1b5b32307e\x1b\\", DECUDK{ClearAll: true, Keys: []UDK{{f6, "\x1b[20~"}}}, ""},
+		{"\x90;1|23/6869;34/\x9c", DECUDK{ClearAll: true, Keys: []UDK{{f11, "hi"}, {f20, ""}}}, ""},
+		{"\x1bP1|17/1B4F50\x1b\\", DECUDK{Lock: true, Keys: []UDK{{f6, "\x1bOP"}}}, ""},
+		{"|17/61\x1b\\", DECUDK{}, "missing DCS"},
+		{"\x1bP|17/61", DECUDK{}, "missing string terminator"},
+		{"\x1bP17/61\x1b\\", DECUDK{}, "missing final"},
+		{"\x1bP2|\x1b\\", DECUDK{}, "invalid clear"},
+		{"\x1bP0;2|\x1b\\", DECUDK{}, "invalid lock"},
+		{"\x1bP0;0;0|\x1b\\", DECUDK{}, "invalid parameters"},
+		{"\x1bP|1761\x1b\\", DECUDK{}, "invalid key definition"},
+		{"\x1bP|x/61\x1b\\", DECUDK{}, "invalid key selector"},
+		{"\x1bP|22/61\x1b\\", DECUDK{}, "unknown key selector"},
+		{"\x1bP|17/6\x1b\\", DECUDK{}, "invalid string"},
+	}
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			in := []byte(c.in)
+			got, err := ParseDECUDK(in)
+			if string(in) != c.in {
+				t.Errorf("input modified: %q", in)
+			}
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("want error containing %q, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("want %+v, got %+v", c.want, got)
+			}
+		})
+	}
+}
+
+func TestInput_AddESCSeq(t *testing.T) {
+	d, err := ParseDECUDK([]byte("\x1bP|17/1b5b39397e;18/6869\x1b\\"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := NewInput()
+	other := NewInput()
+	want := []bool{true, false}
+	for j, k := range d.Keys {
+		if got := input.AddESCSeq(k.Seq, k.Key); got != want[j] {
+			t.Errorf("[%d]: want %t, got %t", j, want[j], got)
+		}
+	}
+
+	k, err := input.ReadKey(strings.NewReader("\x1b[99~"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := NewKey(KeyF6, ModShift); k != want {
+		t.Errorf("want %s, got %s", want, k)
+	}
+
+	// other inputs are not affected
+	k, err = other.ReadKey(strings.NewReader("\x1b[99~"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.Type() != KeyESCSeq {
+		t.Errorf("want %s, got %s", KeyESCSeq, k)
+	}
+}
@@ -0,0 +1,64 @@
+package zzterm
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+const modulePath = "git.sr.ht/~mna/zzterm"
+
+// Version returns the version of the zzterm module compiled in the
+// program, as recorded in the build information (e.g. "v0.3.0"). It
+// returns "(devel)" if the version is not known, e.g. when zzterm is the
+// main module or the program was built without module support.
+func Version() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(devel)"
+	}
+	return moduleVersion(bi)
+}
+
+func moduleVersion(bi *debug.BuildInfo) string {
+	mods := append([]*debug.Module{&bi.Main}, bi.Deps...)
+	for _, m := range mods {
+		if m.Path != modulePath {
+			continue
+		}
+		if m.Replace != nil && m.Replace.Version != "" {
+			return m.Replace.Version
+		}
+		if m.Version != "" {
+			return m.Version
+		}
+	}
+	return "(devel)"
+}
+
+// SupportedProtocols returns the names of the input protocols that the
+// package can decode, for diagnostics such as bug reports. The names are
+// stable across versions, new protocols are appended to the list. The
+// returned slice is a copy that can be modified by the caller.
+func SupportedProtocols() []string {
+	ps := []string{
+		"xterm-keys",        // xterm and terminfo special keys, with modifiers
+		"sgr-mouse",         // X11 mouse protocol in SGR mode (1000, 1002, 1003, 1006)
+		"focus",             // focus in and out events (1004)
+		"bracketed-paste",   // bracketed paste (2004), see WithPaste
+		"osc",               // OSC sequences, see WithOSC
+		"kitty-keyboard",    // kitty keyboard protocol, see WithKitty
+		"decreptparm",       // DEC terminal parameters reports, see WithTermParams
+		"da1",               // primary device attributes, see ParseDeviceAttributes
+		"readline-meta",     // ESC-prefixed Alt keys, see WithReadlineMeta
+		"csi-subparams",     // CSI sub-parameters, see ParseCSI
+		"escseq-streaming",  // escape sequences longer than the buffer
+		"modify-other-keys", // xterm modifyOtherKeys, see WithModifyOtherKeys
+		"keypad-app",        // application keypad (DECKPAM), see SeqEnableKeypadApp
+		"legacy-mouse",      // X10, UTF-8 (1005) and urxvt (1015) mouse, see WithMouseEncodings
+		"mouse-highlight",   // mouse highlight tracking (1001), see Input.Highlight
+	}
+	if runtime.GOOS == "linux" {
+		ps = append(ps, "gpm") // Linux console mouse, see DialGPM
+	}
+	return ps
+}
@@ -0,0 +1,59 @@
+package zzterm
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestModuleVersion(t *testing.T) {
+	cases := []struct {
+		desc string
+		bi   debug.BuildInfo
+		want string
+	}{
+		{"none", debug.BuildInfo{Main: debug.Module{Path: "example.com/app"}}, "(devel)"},
+		{"main", debug.BuildInfo{Main: debug.Module{Path: modulePath, Version: "(devel)"}}, "(devel)"},
+		{"dep", debug.BuildInfo{
+			Main: debug.Module{Path: "example.com/app"},
+			Deps: []*debug.Module{{Path: "example.com/other", Version: "v1.0.0"}, {Path: modulePath, Version: "v0.4.1"}},
+		}, "v0.4.1"},
+		{"replaced", debug.BuildInfo{
+			Main: debug.Module{Path: "example.com/app"},
+			Deps: []*debug.Module{{Path: modulePath, Version: "v0.4.1", Replace: &debug.Module{Path: "../zzterm"}}},
+		}, "v0.4.1"},
+		{"replaced version", debug.BuildInfo{
+			Main: debug.Module{Path: "example.com/app"},
+			Deps: []*debug.Module{{Path: modulePath, Version: "v0.4.1", Replace: &debug.Module{Path: "example.com/fork", Version: "v0.5.0"}}},
+		}, "v0.5.0"},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			if got := moduleVersion(&c.bi); got != c.want {
+				t.Errorf("want %s, got %s", c.want, got)
+			}
+		})
+	}
+	if Version() == "" {
+		t.Error("want a version, got an empty string")
+	}
+}
+
+func TestSupportedProtocols(t *testing.T) {
+	ps := SupportedProtocols()
+	seen := make(map[string]bool)
+	for _, p := range ps {
+		if seen[p] {
+			t.Errorf("duplicate protocol %s", p)
+		}
+		seen[p] = true
+	}
+	for _, want := range []string{"sgr-mouse", "bracketed-paste", "kitty-keyboard", "mouse-highlight"} {
+		if !seen[want] {
+			t.Errorf("want %s in %v", want, ps)
+		}
+	}
+	ps[0] = "x"
+	if SupportedProtocols()[0] == "x" {
+		t.Error("want a copy")
+	}
+}